@@ -0,0 +1,293 @@
+// Package arrowtab converts tablib Datasets to and from Apache Arrow record
+// batches. It lives apart from package tablib so that only programs using
+// Arrow depend on arrow-go.
+package arrowtab
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	tablib "tablib-go"
+)
+
+// FromRecord creates a Dataset from an Arrow record batch. Each field
+// becomes a column headed by its name. Booleans, integers, floats, strings
+// and binary values keep their Go types, dates and timestamps become
+// time.Time in UTC, and nulls become nil. Columns of other Arrow types
+// return an error wrapping tablib.ErrInvalidData. The record is not
+// released.
+func FromRecord(rec arrow.RecordBatch) (*tablib.Dataset, error) {
+	headers := make([]string, rec.NumCols())
+	for i := range headers {
+		headers[i] = rec.ColumnName(i)
+	}
+
+	rows := make([][]any, rec.NumRows())
+	for i := range rows {
+		rows[i] = make([]any, len(headers))
+	}
+	for j, col := range rec.Columns() {
+		for i := range rows {
+			v, err := value(col, i)
+			if err != nil {
+				return nil, fmt.Errorf("%w: column %q: %v", tablib.ErrInvalidData, headers[j], err)
+			}
+			rows[i][j] = v
+		}
+	}
+
+	ds := tablib.NewDataset(headers)
+	for _, row := range rows {
+		if err := ds.Append(row); err != nil {
+			return nil, err
+		}
+	}
+	return ds, nil
+}
+
+// value returns the Go value of element i of col.
+func value(col arrow.Array, i int) (any, error) {
+	if col.IsNull(i) {
+		return nil, nil
+	}
+	switch a := col.(type) {
+	case *array.Null:
+		return nil, nil
+	case *array.Boolean:
+		return a.Value(i), nil
+	case *array.Int8:
+		return a.Value(i), nil
+	case *array.Int16:
+		return a.Value(i), nil
+	case *array.Int32:
+		return a.Value(i), nil
+	case *array.Int64:
+		return a.Value(i), nil
+	case *array.Uint8:
+		return a.Value(i), nil
+	case *array.Uint16:
+		return a.Value(i), nil
+	case *array.Uint32:
+		return a.Value(i), nil
+	case *array.Uint64:
+		return a.Value(i), nil
+	case *array.Float32:
+		return a.Value(i), nil
+	case *array.Float64:
+		return a.Value(i), nil
+	case *array.String:
+		return a.Value(i), nil
+	case *array.LargeString:
+		return a.Value(i), nil
+	case *array.Binary:
+		return append([]byte{}, a.Value(i)...), nil
+	case *array.LargeBinary:
+		return append([]byte{}, a.Value(i)...), nil
+	case *array.Date32:
+		return a.Value(i).ToTime(), nil
+	case *array.Date64:
+		return a.Value(i).ToTime(), nil
+	case *array.Timestamp:
+		return a.Value(i).ToTime(col.DataType().(*arrow.TimestampType).Unit), nil
+	}
+	return nil, fmt.Errorf("unsupported Arrow type %s", col.DataType())
+}
+
+// ToRecord returns the columns of ds named by its Headers as an Arrow
+// record batch with one field per column. The caller must Release the
+// record.
+//
+// Each column takes the Arrow type of its non-nil values: booleans,
+// integers and floats map to the Arrow type of the same width, strings to
+// utf8, []byte to binary, and time.Time to date32 when every value is at
+// midnight or to nanosecond timestamps in UTC otherwise. Columns mixing
+// numeric types become float64, columns mixing other types become utf8
+// with the text of each cell, and columns of only nil become null. nil
+// cells are Arrow nulls.
+func ToRecord(ds *tablib.Dataset) (arrow.RecordBatch, error) {
+	headers := ds.Headers()
+	if len(headers) == 0 {
+		return nil, tablib.ErrHeadersRequired
+	}
+	rows := ds.Records()
+
+	mem := memory.DefaultAllocator
+	fields := make([]arrow.Field, len(headers))
+	cols := make([]arrow.Array, len(headers))
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+	for j, h := range headers {
+		dt := columnType(rows, j)
+		fields[j] = arrow.Field{Name: h, Type: dt, Nullable: true}
+		b := array.NewBuilder(mem, dt)
+		for _, row := range rows {
+			appendValue(b, row[j])
+		}
+		cols[j] = b.NewArray()
+		b.Release()
+	}
+	return array.NewRecordBatch(arrow.NewSchema(fields, nil), cols, int64(len(rows))), nil
+}
+
+// columnType returns the Arrow type for column col of rows.
+func columnType(rows [][]any, col int) arrow.DataType {
+	var dt arrow.DataType
+	numeric, midnight := true, true
+	for _, row := range rows {
+		v := row[col]
+		if v == nil {
+			continue
+		}
+		if t, ok := v.(time.Time); ok && t.Hour()+t.Minute()+t.Second()+t.Nanosecond() != 0 {
+			midnight = false
+		}
+		_, isNumber := number(v)
+		numeric = numeric && isNumber
+		next := typeOf(v)
+		switch {
+		case dt == nil:
+			dt = next
+		case !arrow.TypeEqual(dt, next) && numeric:
+			dt = arrow.PrimitiveTypes.Float64
+		case !arrow.TypeEqual(dt, next):
+			return arrow.BinaryTypes.String
+		}
+	}
+	switch {
+	case dt == nil:
+		return arrow.Null
+	case dt.ID() == arrow.TIMESTAMP && midnight:
+		return arrow.FixedWidthTypes.Date32
+	}
+	return dt
+}
+
+// typeOf returns the Arrow type of a single cell value; values without one
+// map to utf8.
+func typeOf(v any) arrow.DataType {
+	switch v.(type) {
+	case bool:
+		return arrow.FixedWidthTypes.Boolean
+	case int8:
+		return arrow.PrimitiveTypes.Int8
+	case int16:
+		return arrow.PrimitiveTypes.Int16
+	case int32:
+		return arrow.PrimitiveTypes.Int32
+	case int, int64:
+		return arrow.PrimitiveTypes.Int64
+	case uint8:
+		return arrow.PrimitiveTypes.Uint8
+	case uint16:
+		return arrow.PrimitiveTypes.Uint16
+	case uint32:
+		return arrow.PrimitiveTypes.Uint32
+	case uint, uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case float32:
+		return arrow.PrimitiveTypes.Float32
+	case float64:
+		return arrow.PrimitiveTypes.Float64
+	case []byte:
+		return arrow.BinaryTypes.Binary
+	case time.Time:
+		return arrow.FixedWidthTypes.Timestamp_ns
+	}
+	return arrow.BinaryTypes.String
+}
+
+// number converts a Go integer or float to float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// appendValue appends v to b, whose type was chosen by columnType.
+func appendValue(b array.Builder, v any) {
+	if v == nil {
+		b.AppendNull()
+		return
+	}
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		b.Append(v.(bool))
+	case *array.Int8Builder:
+		b.Append(v.(int8))
+	case *array.Int16Builder:
+		b.Append(v.(int16))
+	case *array.Int32Builder:
+		b.Append(v.(int32))
+	case *array.Int64Builder:
+		if n, ok := v.(int); ok {
+			b.Append(int64(n))
+		} else {
+			b.Append(v.(int64))
+		}
+	case *array.Uint8Builder:
+		b.Append(v.(uint8))
+	case *array.Uint16Builder:
+		b.Append(v.(uint16))
+	case *array.Uint32Builder:
+		b.Append(v.(uint32))
+	case *array.Uint64Builder:
+		if n, ok := v.(uint); ok {
+			b.Append(uint64(n))
+		} else {
+			b.Append(v.(uint64))
+		}
+	case *array.Float32Builder:
+		b.Append(v.(float32))
+	case *array.Float64Builder:
+		f, _ := number(v)
+		b.Append(f)
+	case *array.BinaryBuilder:
+		b.Append(v.([]byte))
+	case *array.Date32Builder:
+		t := v.(time.Time)
+		b.Append(arrow.Date32FromTime(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)))
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(v.(time.Time).UnixNano()))
+	case *array.StringBuilder:
+		if t, ok := v.(time.Time); ok {
+			b.Append(t.Format(time.RFC3339Nano))
+		} else {
+			b.Append(fmt.Sprint(v))
+		}
+	default:
+		b.AppendNull()
+	}
+}
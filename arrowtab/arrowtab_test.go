@@ -0,0 +1,73 @@
+package arrowtab
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	tablib "tablib-go"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	when := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	ds := tablib.NewDataset([]string{"Name", "Age", "Score", "Ok", "Day", "When", "Raw", "Mixed", "Empty"})
+	ds.Append([]any{"Alice", int64(30), 1.5, true, day, when, []byte("a"), 1, nil})
+	ds.Append([]any{nil, int64(25), 2, false, nil, when, nil, "x", nil})
+
+	rec, err := ToRecord(ds)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rec.Release()
+	var types []string
+	for _, f := range rec.Schema().Fields() {
+		types = append(types, f.Type.String())
+	}
+	expected := "[utf8 int64 float64 bool date32 timestamp[ns, tz=UTC] binary utf8 null]"
+	if fmt.Sprint(types) != expected {
+		t.Errorf("expected types %s, got %v", expected, types)
+	}
+
+	got, err := FromRecord(rec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(got.Headers()) != fmt.Sprint(ds.Headers()) {
+		t.Errorf("unexpected headers %v", got.Headers())
+	}
+	row, _ := got.Row(0)
+	if row[0] != "Alice" || row[1] != int64(30) || row[2] != 1.5 || row[3] != true ||
+		!row[4].(time.Time).Equal(day) || !row[5].(time.Time).Equal(when) ||
+		string(row[6].([]byte)) != "a" || row[7] != "1" || row[8] != nil {
+		t.Errorf("unexpected first row %v", row)
+	}
+	row, _ = got.Row(1)
+	if row[0] != nil || row[2] != 2.0 || row[4] != nil || row[6] != nil || row[7] != "x" {
+		t.Errorf("unexpected second row %v", row)
+	}
+
+	if _, err := ToRecord(tablib.NewDataset(nil)); err != tablib.ErrHeadersRequired {
+		t.Errorf("expected ErrHeadersRequired, got %v", err)
+	}
+}
+
+func TestFromRecordUnsupportedType(t *testing.T) {
+	b := array.NewDurationBuilder(memory.DefaultAllocator, &arrow.DurationType{Unit: arrow.Second})
+	defer b.Release()
+	b.Append(5)
+	col := b.NewArray()
+	defer col.Release()
+	schema := arrow.NewSchema([]arrow.Field{{Name: "Wait", Type: col.DataType()}}, nil)
+	rec := array.NewRecordBatch(schema, []arrow.Array{col}, 1)
+	defer rec.Release()
+
+	if _, err := FromRecord(rec); !errors.Is(err, tablib.ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+}
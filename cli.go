@@ -1,19 +1,65 @@
 package tablib
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
 )
 
 func init() {
-	RegisterExporter(FormatCLI, ExporterFunc(exportCLI))
+	RegisterExporter(FormatCLI, settingsExporterFunc(exportCLI))
+	RegisterImporter(FormatCLI, ImporterFunc(importCLI))
 }
 
 // CLIOptions holds options for CLI export.
 type CLIOptions struct {
-	// Border style: "single" (default), "double", "ascii", "none"
+	// Border style: "single" (default), "double", "heavy", "ascii", "none"
+	// or a name passed to RegisterBorderStyle
 	BorderStyle string
+	// Border overrides BorderStyle with a custom character set.
+	Border *BorderChars
+
+	// EastAsianWidth counts ambiguous-width characters as two cells when
+	// padding columns.
+	EastAsianWidth bool
+
+	// MaxColumnWidth caps the width of every column in display cells.
+	// Zero means unlimited.
+	MaxColumnWidth int
+	// MaxTableWidth caps the total width of the table, borders included,
+	// by narrowing the widest columns first. Zero means unlimited.
+	MaxTableWidth int
+	// WordWrap wraps cells that exceed their column width onto additional
+	// lines. Otherwise such cells are truncated with an ellipsis.
+	WordWrap bool
+
+	// ShowTitle renders the dataset title centered above the table.
+	ShowTitle bool
+	// Footer is rendered as a final row below the data, set off by a
+	// border line (e.g. a totals line). It must have one value per column.
+	Footer []any
+
+	// ShowRowNumbers prefixes each row with a right-aligned "#" column
+	// numbering rows from 1.
+	ShowRowNumbers bool
+	// RowNumbers overrides the generated numbers, e.g. with the original
+	// indices of rows that survived a filter. It must have one entry per row.
+	RowNumbers []int
+
+	// ColumnWidths fixes the width of every column in display cells instead
+	// of measuring the data. It must have one entry per column, including
+	// the row number column when shown.
+	ColumnWidths []int
+	// SampleRows is the number of rows measured for column widths. A
+	// CLIWriter buffers that many rows before it starts writing, and zero
+	// means 100; exports measure every row when it is zero. Later cells
+	// that do not fit are truncated or wrapped. It is ignored when
+	// ColumnWidths is set.
+	SampleRows int
 }
 
 // DefaultCLIOptions returns default CLI export options.
@@ -25,174 +71,624 @@ func DefaultCLIOptions() CLIOptions {
 
 // ExportCLI exports the Dataset to CLI ASCII table format with options.
 func (ds *Dataset) ExportCLI(w io.Writer, opts CLIOptions) error {
+	return exportCLIWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+// exportCLI exports the Dataset using default CLI options, with a totals
+// row as the footer.
+func exportCLI(ds *Dataset, w io.Writer, s exportSettings) error {
+	opts := DefaultCLIOptions()
+	opts.ShowTitle = s.showTitle
+	ds, opts.Footer = splitTotals(ds, s)
 	return exportCLIWithOptions(ds, w, opts)
 }
 
-// exportCLI exports the Dataset using default CLI options.
-func exportCLI(ds *Dataset, w io.Writer) error {
-	return exportCLIWithOptions(ds, w, DefaultCLIOptions())
+// BorderChars is the set of characters used to draw a CLI table. An empty
+// Horizontal suppresses the top, bottom and middle border lines.
+type BorderChars struct {
+	TopLeft, TopRight, BottomLeft, BottomRight string
+	Horizontal, Vertical, Cross                string
+	TopT, BottomT, LeftT, RightT               string
+}
+
+var borderStyles = map[string]BorderChars{
+	"single": {"┌", "┐", "└", "┘", "─", "│", "┼", "┬", "┴", "├", "┤"},
+	"double": {"╔", "╗", "╚", "╝", "═", "║", "╬", "╦", "╩", "╠", "╣"},
+	"heavy":  {"┏", "┓", "┗", "┛", "━", "┃", "╋", "┳", "┻", "┣", "┫"},
+	"ascii":  {"+", "+", "+", "+", "-", "|", "+", "+", "+", "+", "+"},
+	"none":   {Vertical: " "},
+}
+
+// RegisterBorderStyle registers a named border style for use with
+// CLIOptions.BorderStyle, replacing any existing style of that name.
+func RegisterBorderStyle(name string, chars BorderChars) {
+	borderStyles[name] = chars
 }
 
-// getBorderChars returns border characters based on style.
-func getBorderChars(style string) (topLeft, topRight, bottomLeft, bottomRight, horizontal, vertical, cross, topT, bottomT, leftT, rightT string) {
-	switch style {
-	case "double":
-		return "╔", "╗", "╚", "╝", "═", "║", "╬", "╦", "╩", "╠", "╣"
-	case "ascii":
-		return "+", "+", "+", "+", "-", "|", "+", "+", "+", "+", "+"
-	case "none":
-		return "", "", "", "", "", " ", "", "", "", "", ""
-	default: // "single"
-		return "┌", "┐", "└", "┘", "─", "│", "┼", "┬", "┴", "├", "┤"
+// getBorderChars returns border characters based on style, falling back to
+// "single" for unknown styles.
+func getBorderChars(style string) BorderChars {
+	if chars, ok := borderStyles[style]; ok {
+		return chars
 	}
+	return borderStyles["single"]
 }
 
 func exportCLIWithOptions(ds *Dataset, w io.Writer, opts CLIOptions) error {
 	if ds.Width() == 0 {
 		return nil
 	}
+	if opts.ShowRowNumbers {
+		numbered, err := withCLIRowNumbers(ds, opts.RowNumbers)
+		if err != nil {
+			return err
+		}
+		ds = numbered
+		if len(opts.Footer) > 0 {
+			opts.Footer = append([]any{""}, opts.Footer...)
+		}
+	}
+	if len(opts.Footer) > 0 && len(opts.Footer) != ds.Width() {
+		return ErrInvalidDimensions
+	}
 
-	var sb strings.Builder
-
-	// Get border characters
-	topLeft, topRight, bottomLeft, bottomRight, horizontal, vertical, cross, topT, bottomT, leftT, rightT := getBorderChars(opts.BorderStyle)
+	footer := ds.cellTexts(opts.Footer)
 
-	// Calculate column widths
-	widths := make([]int, ds.Width())
+	aligns := make([]Alignment, ds.Width())
 	for i, h := range ds.headers {
-		if len(h) > widths[i] {
-			widths[i] = len(h)
+		aligns[i] = ds.Alignment(h)
+	}
+
+	measure := func(cond *runewidth.Condition) []int {
+		widths := ds.rowWidths(widthKind{eastAsian: opts.EastAsianWidth}, opts.SampleRows)
+		measureCells(widths, ds.headers, cond)
+		measureCells(widths, footer, cond)
+		return widths
+	}
+	r, err := newCLIRenderer(ds.Width(), measure, aligns, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	// Write title
+	if opts.ShowTitle && ds.title != "" {
+		r.writeTitle(bw, ds.title)
+	}
+
+	// Write top border
+	r.writeTopBorder(bw)
+
+	// Write headers
+	if len(ds.headers) > 0 {
+		r.writeCells(bw, ds.headers)
+		r.writeMiddleBorder(bw)
+	}
+
+	// Write data rows
+	for rowIdx, row := range ds.data {
+		// Check for separator before this row
+		if sep, ok := ds.GetSeparator(rowIdx); ok {
+			r.writeSeparator(bw, sep.Text)
+			r.writeMiddleBorder(bw)
 		}
+		r.writeCells(bw, ds.cellTexts(row))
 	}
-	for _, row := range ds.data {
-		for i, v := range row {
-			s := fmt.Sprintf("%v", v)
-			if len(s) > widths[i] {
-				widths[i] = len(s)
-			}
+
+	// Check for separator after the last row
+	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
+		r.writeMiddleBorder(bw)
+		r.writeSeparator(bw, sep.Text)
+	}
+
+	// Write footer
+	if len(footer) > 0 {
+		r.writeMiddleBorder(bw)
+		r.writeCells(bw, footer)
+	}
+
+	// Write bottom border
+	r.writeBottomBorder(bw)
+
+	return bw.Flush()
+}
+
+// cliStrings formats values for display.
+func cliStrings(values []any) []string {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = valueString(v)
+	}
+	return cells
+}
+
+// cliRenderer draws the lines of a CLI table with fixed column widths.
+type cliRenderer struct {
+	border BorderChars
+	widths []int
+	aligns []Alignment
+	wrap   bool
+	cond   *runewidth.Condition
+}
+
+// newCLIRenderer sizes n columns to fit the measured rows, or to the fixed
+// CLIOptions.ColumnWidths, and applies the width limits.
+func newCLIRenderer(n int, measure func(cond *runewidth.Condition) []int, aligns []Alignment, opts CLIOptions) (*cliRenderer, error) {
+	r := &cliRenderer{
+		border: getBorderChars(opts.BorderStyle),
+		widths: make([]int, n),
+		aligns: aligns,
+		wrap:   opts.WordWrap,
+		cond:   newWidthCondition(opts.EastAsianWidth),
+	}
+	if opts.Border != nil {
+		r.border = *opts.Border
+	}
+
+	// Calculate column widths
+	if opts.ColumnWidths != nil {
+		if len(opts.ColumnWidths) != n {
+			return nil, ErrInvalidDimensions
 		}
+		copy(r.widths, opts.ColumnWidths)
+	} else {
+		copy(r.widths, measure(r.cond))
 	}
 
 	// Ensure minimum width of 1
-	for i := range widths {
-		if widths[i] < 1 {
-			widths[i] = 1
+	for i := range r.widths {
+		if r.widths[i] < 1 {
+			r.widths[i] = 1
 		}
 	}
+	fitCLIWidths(r.widths, opts)
+	return r, nil
+}
 
-	// Helper function to write a separator line
-	writeTopBorder := func() {
-		if opts.BorderStyle == "none" {
-			return
+// writeBorder writes a horizontal border line with the given corner and
+// junction characters.
+func (r *cliRenderer) writeBorder(bw *bufio.Writer, left, junction, right string) {
+	if r.border.Horizontal == "" {
+		return
+	}
+	bw.WriteString(left)
+	for i, w := range r.widths {
+		bw.WriteString(strings.Repeat(r.border.Horizontal, w+2))
+		if i < len(r.widths)-1 {
+			bw.WriteString(junction)
 		}
-		sb.WriteString(topLeft)
-		for i, w := range widths {
-			sb.WriteString(strings.Repeat(horizontal, w+2))
-			if i < len(widths)-1 {
-				sb.WriteString(topT)
+	}
+	bw.WriteString(right)
+	bw.WriteString("\n")
+}
+
+func (r *cliRenderer) writeTopBorder(bw *bufio.Writer) {
+	r.writeBorder(bw, r.border.TopLeft, r.border.TopT, r.border.TopRight)
+}
+
+func (r *cliRenderer) writeMiddleBorder(bw *bufio.Writer) {
+	r.writeBorder(bw, r.border.LeftT, r.border.Cross, r.border.RightT)
+}
+
+func (r *cliRenderer) writeBottomBorder(bw *bufio.Writer) {
+	r.writeBorder(bw, r.border.BottomLeft, r.border.BottomT, r.border.BottomRight)
+}
+
+// writeCells writes one table row, spilling wrapped cells onto additional
+// lines.
+func (r *cliRenderer) writeCells(bw *bufio.Writer, cells []string) {
+	lines := make([][]string, len(cells))
+	height := 1
+	for i, c := range cells {
+		lines[i] = cliCellLines(c, r.widths[i], r.wrap, r.cond)
+		height = max(height, len(lines[i]))
+	}
+	for l := 0; l < height; l++ {
+		bw.WriteString(r.border.Vertical)
+		for i := range cells {
+			text := ""
+			if l < len(lines[i]) {
+				text = lines[i][l]
 			}
+			bw.WriteString(fmt.Sprintf(" %s ", alignText(text, r.widths[i], r.aligns[i], r.cond)))
+			bw.WriteString(r.border.Vertical)
 		}
-		sb.WriteString(topRight)
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 	}
+}
 
-	writeBottomBorder := func() {
-		if opts.BorderStyle == "none" {
-			return
+// writeSeparator writes a separator row spanning all columns.
+func (r *cliRenderer) writeSeparator(bw *bufio.Writer, text string) {
+	framed := r.border.Horizontal != ""
+	if framed {
+		bw.WriteString(r.border.Vertical)
+	}
+	totalWidth := 0
+	for _, w := range r.widths {
+		totalWidth += w + 3 // +3 for " | "
+	}
+	totalWidth-- // Remove last extra space
+	text = truncateText(text, totalWidth-2, r.cond)
+	bw.WriteString(fmt.Sprintf(" %s ", alignText(text, totalWidth-2, AlignLeft, r.cond)))
+	if framed {
+		bw.WriteString(r.border.Vertical)
+	}
+	bw.WriteString("\n")
+}
+
+// writeTitle writes title centered over the table.
+func (r *cliRenderer) writeTitle(bw *bufio.Writer, title string) {
+	tableWidth := len(r.widths) + 1
+	for _, w := range r.widths {
+		tableWidth += w + 2
+	}
+	title = truncateText(title, tableWidth, r.cond)
+	bw.WriteString(strings.TrimRight(alignText(title, tableWidth, AlignCenter, r.cond), " "))
+	bw.WriteString("\n")
+}
+
+// fitCLIWidths applies the column and table width limits to widths in place.
+func fitCLIWidths(widths []int, opts CLIOptions) {
+	if opts.MaxColumnWidth > 0 {
+		for i := range widths {
+			widths[i] = min(widths[i], opts.MaxColumnWidth)
 		}
-		sb.WriteString(bottomLeft)
+	}
+	if opts.MaxTableWidth <= 0 {
+		return
+	}
+
+	// Each column is padded by a space on both sides and followed by a
+	// border, plus the leading border
+	total := len(widths) + 1
+	for _, w := range widths {
+		total += w + 2
+	}
+	for total > opts.MaxTableWidth {
+		widest := 0
 		for i, w := range widths {
-			sb.WriteString(strings.Repeat(horizontal, w+2))
-			if i < len(widths)-1 {
-				sb.WriteString(bottomT)
+			if w > widths[widest] {
+				widest = i
 			}
 		}
-		sb.WriteString(bottomRight)
-		sb.WriteString("\n")
-	}
-
-	writeMiddleBorder := func() {
-		if opts.BorderStyle == "none" {
+		if widths[widest] <= 1 {
 			return
 		}
-		sb.WriteString(leftT)
-		for i, w := range widths {
-			sb.WriteString(strings.Repeat(horizontal, w+2))
-			if i < len(widths)-1 {
-				sb.WriteString(cross)
+		widths[widest]--
+		total--
+	}
+}
+
+// cliCellLines fits a cell into width display cells, either by word
+// wrapping onto several lines or by truncating with an ellipsis.
+func cliCellLines(s string, width int, wrap bool, cond *runewidth.Condition) []string {
+	if cond.StringWidth(s) <= width {
+		return []string{s}
+	}
+	if !wrap {
+		return []string{cond.Truncate(s, width, "…")}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		// Split words longer than the column
+		for cond.StringWidth(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			head := cond.Truncate(word, width, "")
+			n := len(head)
+			if head == "" {
+				// A rune wider than the column shows as an ellipsis
+				_, n = utf8.DecodeRuneInString(word)
+				head = "…"
 			}
+			lines = append(lines, head)
+			word = word[n:]
 		}
-		sb.WriteString(rightT)
-		sb.WriteString("\n")
+		switch {
+		case line == "":
+			line = word
+		case cond.StringWidth(line)+1+cond.StringWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
 	}
+	return lines
+}
 
-	// Write top border
-	writeTopBorder()
+// withCLIRowNumbers returns a copy of ds with a leading "#" column holding
+// row numbers, 1-based unless explicit numbers are given.
+func withCLIRowNumbers(ds *Dataset, numbers []int) (*Dataset, error) {
+	if numbers != nil && len(numbers) != len(ds.data) {
+		return nil, ErrInvalidDimensions
+	}
 
-	// Write headers
+	var headers []string
 	if len(ds.headers) > 0 {
-		sb.WriteString(vertical)
-		for i, h := range ds.headers {
-			sb.WriteString(fmt.Sprintf(" %-*s ", widths[i], h))
-			sb.WriteString(vertical)
+		headers = append([]string{"#"}, ds.headers...)
+	}
+	result := NewDataset(headers)
+	result.title = ds.title
+	for k, v := range ds.separators {
+		result.separators[k] = v
+	}
+	for k, v := range ds.alignments {
+		result.alignments[k] = v
+	}
+	for k, v := range ds.formats {
+		result.formats[k] = v
+	}
+	for k, v := range ds.columnTags {
+		result.columnTags[k] = v
+	}
+	result.alignments["#"] = AlignRight
+
+	for i, row := range ds.data {
+		n := i + 1
+		if numbers != nil {
+			n = numbers[i]
 		}
-		sb.WriteString("\n")
-		writeMiddleBorder()
+		result.data = append(result.data, append([]any{n}, row...))
+		result.tags = append(result.tags, ds.tags[i])
 	}
+	return result, nil
+}
 
-	// Write data rows
-	for rowIdx, row := range ds.data {
-		// Check for separator before this row
-		if sep, ok := ds.GetSeparator(rowIdx); ok {
-			if opts.BorderStyle != "none" {
-				sb.WriteString(vertical)
-			}
-			totalWidth := 0
-			for _, w := range widths {
-				totalWidth += w + 3 // +3 for " | "
-			}
-			totalWidth-- // Remove last extra space
-			text := sep.Text
-			if len(text) > totalWidth-2 {
-				text = text[:totalWidth-2]
-			}
-			sb.WriteString(fmt.Sprintf(" %-*s ", totalWidth-2, text))
-			if opts.BorderStyle != "none" {
-				sb.WriteString(vertical)
+// defaultCLISampleRows is the number of rows a CLIWriter measures when
+// CLIOptions.SampleRows is zero.
+const defaultCLISampleRows = 100
+
+// CLIWriter streams a CLI table to an io.Writer row by row, so large inputs
+// start printing before they are fully read. Column widths come from
+// CLIOptions.ColumnWidths or are measured over the first SampleRows rows;
+// later cells that do not fit are truncated or wrapped like any other cell.
+// Titles are not supported since there is no dataset.
+type CLIWriter struct {
+	bw      *bufio.Writer
+	headers []string
+	opts    CLIOptions
+	r       *cliRenderer
+	pending [][]string
+	count   int
+}
+
+// NewCLIWriter returns a writer for a table with the given headers. Call
+// Close to write the footer and bottom border.
+func NewCLIWriter(w io.Writer, headers []string, opts CLIOptions) (*CLIWriter, error) {
+	if len(headers) == 0 {
+		return nil, ErrHeadersRequired
+	}
+	if opts.ShowRowNumbers {
+		headers = append([]string{"#"}, headers...)
+		if len(opts.Footer) > 0 {
+			opts.Footer = append([]any{""}, opts.Footer...)
+		}
+	}
+	if len(opts.Footer) > 0 && len(opts.Footer) != len(headers) {
+		return nil, ErrInvalidDimensions
+	}
+	if opts.SampleRows <= 0 {
+		opts.SampleRows = defaultCLISampleRows
+	}
+	return &CLIWriter{bw: bufio.NewWriter(w), headers: headers, opts: opts}, nil
+}
+
+// Write adds a row to the table. Rows are buffered until the column widths
+// are known, then written immediately.
+func (cw *CLIWriter) Write(row []any) error {
+	cells := cliStrings(row)
+	if cw.opts.ShowRowNumbers {
+		cells = append([]string{fmt.Sprint(cw.count + 1)}, cells...)
+	}
+	if len(cells) != len(cw.headers) {
+		return ErrInvalidDimensions
+	}
+	cw.count++
+
+	if cw.r != nil {
+		return cw.writeRows([][]string{cells})
+	}
+	cw.pending = append(cw.pending, cells)
+	if cw.opts.ColumnWidths != nil || len(cw.pending) >= cw.opts.SampleRows {
+		return cw.start()
+	}
+	return nil
+}
+
+// Count returns the number of rows written so far.
+func (cw *CLIWriter) Count() int {
+	return cw.count
+}
+
+// Close writes any buffered rows, the footer and the bottom border. It does
+// not close the underlying writer.
+func (cw *CLIWriter) Close() error {
+	if cw.r == nil {
+		if err := cw.start(); err != nil {
+			return err
+		}
+	}
+	if len(cw.opts.Footer) > 0 {
+		cw.r.writeMiddleBorder(cw.bw)
+		cw.r.writeCells(cw.bw, cliStrings(cw.opts.Footer))
+	}
+	cw.r.writeBottomBorder(cw.bw)
+	return cw.bw.Flush()
+}
+
+// start fixes the column widths from the buffered rows, then writes the
+// header and the buffered rows.
+func (cw *CLIWriter) start() error {
+	aligns := make([]Alignment, len(cw.headers))
+	if cw.opts.ShowRowNumbers {
+		aligns[0] = AlignRight
+	}
+	measure := func(cond *runewidth.Condition) []int {
+		widths := make([]int, len(cw.headers))
+		measureCells(widths, cw.headers, cond)
+		for _, cells := range cw.pending {
+			measureCells(widths, cells, cond)
+		}
+		measureCells(widths, cliStrings(cw.opts.Footer), cond)
+		return widths
+	}
+	r, err := newCLIRenderer(len(cw.headers), measure, aligns, cw.opts)
+	if err != nil {
+		return err
+	}
+	cw.r = r
+
+	r.writeTopBorder(cw.bw)
+	r.writeCells(cw.bw, cw.headers)
+	r.writeMiddleBorder(cw.bw)
+
+	pending := cw.pending
+	cw.pending = nil
+	return cw.writeRows(pending)
+}
+
+func (cw *CLIWriter) writeRows(rows [][]string) error {
+	for _, cells := range rows {
+		cw.r.writeCells(cw.bw, cells)
+	}
+	return cw.bw.Flush()
+}
+
+// cliVerticals are the column delimiters recognized when importing tables.
+const cliVerticals = "|│║┃"
+
+// cliRuleChars are the characters that make up border lines.
+const cliRuleChars = "+-=:|─━═│║┃┌┐└┘├┤┬┴┼╔╗╚╝╠╣╦╩╬┏┓┗┛┣┫┳┻╋"
+
+// importCLI parses a text table as written by the CLI exporter or by
+// database clients such as psql and mysql, with cells delimited by | or
+// box-drawing verticals. A row followed by a border line before any other
+// row becomes the headers, single-cell rows in a wider table become
+// separators, and a text line above the table becomes the title. Other
+// lines without delimiters (e.g. "(2 rows)") are skipped. Empty headers,
+// and headers missing for columns of wider rows, are named Column3,
+// Column4 and so on by position. All values are imported as strings.
+func importCLI(r io.Reader) (*Dataset, error) {
+	var rows [][]string
+	var title string
+	headerRow := -1
+	tableStarted := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		raw := strings.TrimSuffix(scanner.Text(), "\r")
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			continue
+		case isCLIRule(line):
+			if len(rows) == 1 && headerRow == -1 {
+				headerRow = 0
 			}
-			sb.WriteString("\n")
-			writeMiddleBorder()
+			tableStarted = true
+		case strings.ContainsAny(line, cliVerticals):
+			rows = append(rows, splitCLIRow(raw))
+			tableStarted = true
+		case !tableStarted && title == "":
+			title = line
 		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-		sb.WriteString(vertical)
-		for i, v := range row {
-			sb.WriteString(fmt.Sprintf(" %-*v ", widths[i], v))
-			sb.WriteString(vertical)
+	if len(rows) == 0 {
+		return NewDataset(nil), nil
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	var headers []string
+	if headerRow == 0 {
+		// Clients leave trailing header cells empty, so name the empty
+		// and missing ones
+		headers = rows[0]
+		for i := len(headers); i < width; i++ {
+			headers = append(headers, "")
+		}
+		for i, h := range headers {
+			if h == "" {
+				headers[i] = fmt.Sprintf("Column%d", i+1)
+			}
 		}
-		sb.WriteString("\n")
+		rows = rows[1:]
 	}
+	ds := NewDataset(headers)
+	ds.SetTitle(title)
 
-	// Check for separator after the last row
-	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
-		writeMiddleBorder()
-		sb.WriteString(vertical)
-		totalWidth := 0
-		for _, w := range widths {
-			totalWidth += w + 3
+	for _, cells := range rows {
+		if len(cells) == 1 && width > 1 {
+			ds.AppendSeparator(cells[0])
+			continue
 		}
-		totalWidth--
-		text := sep.Text
-		if len(text) > totalWidth-2 {
-			text = text[:totalWidth-2]
+		row := make([]any, width)
+		for i := range row {
+			row[i] = ""
+			if i < len(cells) {
+				row[i] = cells[i]
+			}
+		}
+		if err := ds.Append(row); err != nil {
+			return nil, err
 		}
-		sb.WriteString(fmt.Sprintf(" %-*s ", totalWidth-2, text))
-		sb.WriteString(vertical)
-		sb.WriteString("\n")
 	}
 
-	// Write bottom border
-	writeBottomBorder()
+	return ds, nil
+}
+
+// isCLIRule reports whether line is a border line such as "+---+---+",
+// "├───┼───┤" or psql's "----+----".
+func isCLIRule(line string) bool {
+	for _, r := range line {
+		if !strings.ContainsRune(cliRuleChars, r) {
+			return false
+		}
+	}
+	return strings.ContainsAny(line, "-=─━═")
+}
 
-	_, err := w.Write([]byte(sb.String()))
-	return err
+// splitCLIRow splits a table line into trimmed cells, dropping the outer
+// borders. Only a vertical at the very start or end of the line is a
+// border; psql instead indents an empty (NULL) first cell and pads an
+// empty last one with a space, and those cells are kept.
+func splitCLIRow(line string) []string {
+	var fields []string
+	start := 0
+	for i, r := range line {
+		if strings.ContainsRune(cliVerticals, r) {
+			fields = append(fields, line[start:i])
+			start = i + len(string(r))
+		}
+	}
+	fields = append(fields, line[start:])
+
+	if r, _ := utf8.DecodeRuneInString(line); strings.ContainsRune(cliVerticals, r) {
+		fields = fields[1:]
+	}
+	if r, _ := utf8.DecodeLastRuneInString(line); len(fields) > 0 && strings.ContainsRune(cliVerticals, r) {
+		fields = fields[:len(fields)-1]
+	}
+	cells := make([]string, len(fields))
+	for i, f := range fields {
+		cells[i] = strings.TrimSpace(f)
+	}
+	return cells
 }
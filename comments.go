@@ -0,0 +1,147 @@
+package tablib
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// cellKey identifies a commented cell by row index and column header, so
+// that comments follow their column when columns are added or removed.
+type cellKey struct {
+	row    int
+	header string
+}
+
+// SetCellComment attaches a comment to the cell at row and col, replacing
+// any previous one; an empty text removes it. XLSX and ODS exports render
+// comments as native cell comments and HTML as title attributes.
+//
+// Comments move with their rows on Insert, Pop and sorting, and are kept
+// by Copy and by row selections such as Head and Filter. The column must
+// have a header.
+func (ds *Dataset) SetCellComment(row, col int, text string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if row < 0 || row >= len(ds.data) {
+		return ErrInvalidRowIndex
+	}
+	c, ok := ds.columnAt(col)
+	if !ok {
+		return ErrInvalidColumnIndex
+	}
+	header, ok := ds.columnHeader(c)
+	if !ok {
+		return ErrHeadersRequired
+	}
+	key := cellKey{row: row, header: header}
+	if text == "" {
+		delete(ds.comments, key)
+		return nil
+	}
+	if ds.comments == nil {
+		ds.comments = make(map[cellKey]string)
+	}
+	ds.comments[key] = text
+	return nil
+}
+
+// CellComment returns the comment of the cell at row and col, or "" if it
+// has none.
+func (ds *Dataset) CellComment(row, col int) string {
+	c, ok := ds.columnAt(col)
+	if !ok {
+		return ""
+	}
+	header, ok := ds.columnHeader(c)
+	if !ok {
+		return ""
+	}
+	return ds.comments[cellKey{row: row, header: header}]
+}
+
+// cellComment is a comment with the position of its cell.
+type cellComment struct {
+	row, col int
+	text     string
+}
+
+// cellComments returns the comments of existing cells ordered by row and
+// column, for exporters.
+func (ds *Dataset) cellComments() []cellComment {
+	var comments []cellComment
+	for key, text := range ds.comments {
+		col := ds.headerIndex(key.header)
+		if col == -1 || key.row >= len(ds.data) || col >= len(ds.data[key.row]) {
+			continue
+		}
+		comments = append(comments, cellComment{row: key.row, col: col, text: text})
+	}
+	slices.SortFunc(comments, func(a, b cellComment) int {
+		return cmp.Or(cmp.Compare(a.row, b.row), cmp.Compare(a.col, b.col))
+	})
+	return comments
+}
+
+// selectComments returns the comments of a selection of rows, where row i
+// of the selection is row rows[i] of the dataset.
+func (ds *Dataset) selectComments(rows []int) map[cellKey]string {
+	if len(ds.comments) == 0 {
+		return nil
+	}
+	positions := make(map[int][]int, len(rows))
+	for i, r := range rows {
+		positions[r] = append(positions[r], i)
+	}
+	comments := make(map[cellKey]string)
+	for key, text := range ds.comments {
+		for _, i := range positions[key.row] {
+			comments[cellKey{row: i, header: key.header}] = text
+		}
+	}
+	return comments
+}
+
+// shiftComments moves the comments of rows from index on by delta, after
+// rows are inserted or removed. Comments of removed rows are dropped.
+func (ds *Dataset) shiftComments(index, delta int) {
+	if len(ds.comments) == 0 {
+		return
+	}
+	comments := make(map[cellKey]string, len(ds.comments))
+	for key, text := range ds.comments {
+		switch {
+		case key.row < index:
+		case delta < 0 && key.row < index-delta:
+			continue
+		default:
+			key.row += delta
+		}
+		comments[key] = text
+	}
+	ds.comments = comments
+}
+
+// renameCommentHeaders moves comments to renamed columns.
+func (ds *Dataset) renameCommentHeaders(names map[string]string) {
+	if len(ds.comments) == 0 {
+		return
+	}
+	comments := make(map[cellKey]string, len(ds.comments))
+	for key, text := range ds.comments {
+		if name, ok := names[key.header]; ok {
+			key.header = name
+		}
+		comments[key] = text
+	}
+	ds.comments = comments
+}
+
+// cloneComments returns a copy of the comments.
+func (ds *Dataset) cloneComments() map[cellKey]string {
+	if len(ds.comments) == 0 {
+		return nil
+	}
+	return maps.Clone(ds.comments)
+}
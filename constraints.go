@@ -0,0 +1,251 @@
+package tablib
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Constraint restricts the values of a column once attached with
+// SetConstraint. Like validation rules, constraints other than
+// NotNullConstraint accept nil and empty values.
+type Constraint struct {
+	name   string
+	check  func(v any) error
+	unique bool
+}
+
+var (
+	// NotNullConstraint rejects nil and empty values.
+	NotNullConstraint = Constraint{name: "not null", check: checkRequired}
+
+	// UniqueConstraint rejects values already present in another row.
+	// Values match when they have the same type and value.
+	UniqueConstraint = Constraint{name: "unique", unique: true}
+)
+
+// NewConstraint returns a constraint that rejects the values for which
+// check returns an error. The check of a validation Rule can be reused:
+// NewConstraint(rule.Name, rule.Check).
+func NewConstraint(name string, check func(v any) error) Constraint {
+	return Constraint{name: name, check: check}
+}
+
+// Pattern returns a constraint that rejects values whose text does not
+// match re.
+func Pattern(re *regexp.Regexp) Constraint {
+	return Constraint{name: "pattern", check: skipBlank(patternCheck(re))}
+}
+
+// Name returns the name of the constraint, as reported in violations.
+func (c Constraint) Name() string {
+	return c.name
+}
+
+// SetConstraint attaches constraints to the column with the specified
+// header, replacing any attached before; without constraints, it removes
+// them. Append, Insert, Set and the methods that rewrite many cells, such
+// as MapColumn, UpdateWhere, FillNA and Merge, then reject rows and values
+// that violate a constraint with an error wrapping ErrConstraintViolation,
+// leaving the dataset unchanged, unless CollectViolations is enabled. The
+// existing values are checked in the same way before the constraints are
+// attached.
+func (ds *Dataset) SetConstraint(header string, constraints ...Constraint) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return ErrColumnNotFound
+	}
+	if len(constraints) == 0 {
+		delete(ds.constraints, header)
+		return nil
+	}
+
+	var violations []Violation
+	seen := make(map[any]bool)
+	for i, row := range ds.data {
+		v := row[col]
+		for _, c := range constraints {
+			var err error
+			switch {
+			case c.unique:
+				// Later rows repeating a value are the violations
+				if !isBlank(v) && seen[indexKey(v)] {
+					err = fmt.Errorf("duplicate value %v", v)
+				}
+			case c.check != nil:
+				err = c.check(v)
+			}
+			if err != nil {
+				violations = append(violations, constraintViolation(i, header, c, v, err))
+			}
+		}
+		seen[indexKey(v)] = true
+	}
+	if err := ds.enforceConstraints(violations); err != nil {
+		return err
+	}
+
+	if ds.constraints == nil {
+		ds.constraints = make(map[string][]Constraint)
+	}
+	ds.constraints[header] = slices.Clone(constraints)
+	return nil
+}
+
+// CollectViolations sets whether constraint violations are collected
+// instead of rejected. When collecting, the methods checked against
+// constraints apply every change and record its violations, which
+// Violations returns.
+func (ds *Dataset) CollectViolations(collect bool) {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	ds.collectViolations = collect
+}
+
+// Violations returns the constraint violations collected so far, in the
+// order they occurred. Row indices are those at the time of the change.
+func (ds *Dataset) Violations() []Violation {
+	return slices.Clone(ds.violations)
+}
+
+// ClearViolations discards the collected constraint violations.
+func (ds *Dataset) ClearViolations() {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	ds.violations = nil
+}
+
+// rowViolations checks the values of a row about to be stored at index
+// against the constraints. Row skip, the row being replaced by Set, is
+// ignored by unique constraints; pass -1 for new rows. When col is not
+// -1, only that column is checked.
+func (ds *Dataset) rowViolations(row []any, index, skip, col int) []Violation {
+	if len(ds.constraints) == 0 {
+		return nil
+	}
+	var violations []Violation
+	for i, header := range ds.headers {
+		if col != -1 && i != col || i >= len(row) {
+			continue
+		}
+		for _, c := range ds.constraints[header] {
+			var err error
+			switch {
+			case c.unique:
+				if isBlank(row[i]) {
+					break
+				}
+				// Index the column so that loading rows is not quadratic
+				if _, ok := ds.indexes[header]; !ok {
+					ds.BuildIndex(header)
+				}
+				for _, r := range ds.LookupRows(header, row[i]) {
+					if r != skip {
+						err = fmt.Errorf("duplicate value %v", row[i])
+						break
+					}
+				}
+			case c.check != nil:
+				err = c.check(row[i])
+			}
+			if err != nil {
+				violations = append(violations, constraintViolation(index, header, c, row[i], err))
+			}
+		}
+	}
+	return violations
+}
+
+// cellChange is a value about to be written to a cell by a change to many
+// cells at once. Rows at or past the height are appended.
+type cellChange struct {
+	row, col int
+	value    any
+}
+
+// rowChanges appends to changes the cells of a row about to be stored at
+// index whose columns have constraints.
+func (ds *Dataset) rowChanges(changes []cellChange, index int, row []any) []cellChange {
+	for col, header := range ds.headers {
+		if len(ds.constraints[header]) > 0 {
+			changes = append(changes, cellChange{row: index, col: col, value: row[col]})
+		}
+	}
+	return changes
+}
+
+// changeViolations checks cells about to be written together, as by
+// MapColumn or Merge, against the constraints. Unique constraints compare
+// each new value with the column as it will be after the whole change.
+func (ds *Dataset) changeViolations(changes []cellChange) []Violation {
+	if len(ds.constraints) == 0 {
+		return nil
+	}
+	// Count the values of each unique column after the change
+	counts := make(map[int]map[any]int)
+	for _, ch := range changes {
+		if !slices.ContainsFunc(ds.constraints[ds.headers[ch.col]], func(c Constraint) bool { return c.unique }) {
+			continue
+		}
+		n, ok := counts[ch.col]
+		if !ok {
+			n = make(map[any]int)
+			for _, row := range ds.data {
+				if !isBlank(row[ch.col]) {
+					n[indexKey(row[ch.col])]++
+				}
+			}
+			counts[ch.col] = n
+		}
+		if ch.row < len(ds.data) && !isBlank(ds.data[ch.row][ch.col]) {
+			n[indexKey(ds.data[ch.row][ch.col])]--
+		}
+		if !isBlank(ch.value) {
+			n[indexKey(ch.value)]++
+		}
+	}
+
+	var violations []Violation
+	for _, ch := range changes {
+		header := ds.headers[ch.col]
+		for _, c := range ds.constraints[header] {
+			var err error
+			switch {
+			case c.unique:
+				if !isBlank(ch.value) && counts[ch.col][indexKey(ch.value)] > 1 {
+					err = fmt.Errorf("duplicate value %v", ch.value)
+				}
+			case c.check != nil:
+				err = c.check(ch.value)
+			}
+			if err != nil {
+				violations = append(violations, constraintViolation(ch.row, header, c, ch.value, err))
+			}
+		}
+	}
+	return violations
+}
+
+// constraintViolation describes a value rejected by a constraint.
+func constraintViolation(row int, header string, c Constraint, v any, err error) Violation {
+	return Violation{Row: row, Column: header, Rule: c.name, Value: v, Message: err.Error()}
+}
+
+// enforceConstraints records violations when collecting them, and
+// otherwise returns an error describing the first one.
+func (ds *Dataset) enforceConstraints(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	if ds.collectViolations {
+		ds.violations = append(ds.violations, violations...)
+		return nil
+	}
+	v := violations[0]
+	return fmt.Errorf("%w: row %d, column %q: %s", ErrConstraintViolation, v.Row, v.Column, v.Message)
+}
@@ -0,0 +1,68 @@
+package tablib
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// rowSharing records whether the rows of a dataset may be shared with
+// another dataset, in which case they are copied before a cell is
+// written, and whether they may be aliased by a SliceRows view, which
+// writes them in place. It is atomic because read-only operations such as
+// Copy share the rows of their receiver, possibly under a SyncDataset read
+// lock.
+type rowSharing struct {
+	shared atomic.Bool
+	viewed atomic.Bool
+}
+
+// shareRows marks the rows of ds and result as shared after result took
+// rows from ds without copying them. Rows that a SliceRows view may write
+// in place are copied instead, so that result does not see those writes.
+func (ds *Dataset) shareRows(result *Dataset) {
+	if len(result.data) == 0 {
+		return
+	}
+	if ds.sharing.viewed.Load() {
+		for i, row := range result.data {
+			result.data[i] = slices.Clone(row)
+		}
+		return
+	}
+	ds.sharing.shared.Store(true)
+	result.sharing.shared.Store(true)
+}
+
+// viewRows marks the rows of ds and view as aliased after view took rows
+// from ds to write them in place.
+func (ds *Dataset) viewRows(view *Dataset) {
+	if len(view.data) == 0 {
+		return
+	}
+	ds.sharing.viewed.Store(true)
+	view.sharing.viewed.Store(true)
+}
+
+// rowsShared reports whether the rows may be shared with another dataset.
+func (ds *Dataset) rowsShared() bool {
+	return ds.sharing.shared.Load()
+}
+
+// ownRows copies shared rows before cells are written in place.
+func (ds *Dataset) ownRows() {
+	if !ds.rowsShared() {
+		return
+	}
+	for i, row := range ds.data {
+		ds.data[i] = slices.Clone(row)
+	}
+	ds.sharing.shared.Store(false)
+}
+
+// ownRow copies row i, if it may be shared, before one of its cells is
+// written in place.
+func (ds *Dataset) ownRow(i int) {
+	if ds.rowsShared() {
+		ds.data[i] = slices.Clone(ds.data[i])
+	}
+}
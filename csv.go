@@ -1,9 +1,13 @@
 package tablib
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/csv"
 	"fmt"
 	"io"
+	"path"
+	"strings"
 )
 
 func init() {
@@ -11,6 +15,52 @@ func init() {
 	RegisterImporter(FormatCSV, ImporterFunc(importCSV))
 	RegisterExporter(FormatTSV, ExporterFunc(exportTSV))
 	RegisterImporter(FormatTSV, ImporterFunc(importTSV))
+	RegisterDatabookExporter(FormatCSV, DatabookExporterFunc(exportDatabookCSV))
+	RegisterDatabookImporter(FormatCSV, DatabookImporterFunc(importDatabookCSV))
+}
+
+// csvIndexName is the manifest written alongside the sheets of a Databook
+// CSV archive.
+const csvIndexName = "index.csv"
+
+// writeSheetIndex writes a CSV manifest listing each sheet's file name,
+// title and row count.
+func writeSheetIndex(w io.Writer, db *Databook, names []string) error {
+	manifest := csv.NewWriter(w)
+	manifest.Write([]string{"file", "title", "rows"})
+	for i, ds := range db.sheets {
+		manifest.Write([]string{names[i], ds.title, fmt.Sprint(ds.Height())})
+	}
+	manifest.Flush()
+	return manifest.Error()
+}
+
+// exportDatabookCSV writes a ZIP archive with one CSV file per sheet, named
+// after the sheet titles, and an index.csv listing each file with its sheet
+// title and row count.
+func exportDatabookCSV(db *Databook, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	names := sheetFileNames(db.sheets, ".csv")
+
+	index, err := zw.Create(csvIndexName)
+	if err != nil {
+		return err
+	}
+	if err := writeSheetIndex(index, db, names); err != nil {
+		return err
+	}
+
+	for i, ds := range db.sheets {
+		f, err := zw.Create(names[i])
+		if err != nil {
+			return err
+		}
+		if err := exportCSV(ds, f); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
 }
 
 // CSVOptions configures CSV export behavior.
@@ -52,7 +102,7 @@ func exportCSVWithOptions(ds *Dataset, w io.Writer, opts CSVOptions) error {
 	for _, row := range ds.data {
 		record := make([]string, len(row))
 		for i, v := range row {
-			record[i] = fmt.Sprintf("%v", v)
+			record[i] = valueString(v)
 		}
 		if err := writer.Write(record); err != nil {
 			return err
@@ -65,7 +115,7 @@ func exportCSVWithOptions(ds *Dataset, w io.Writer, opts CSVOptions) error {
 
 // ExportCSV exports the Dataset to CSV format with custom options.
 func (ds *Dataset) ExportCSV(w io.Writer, opts CSVOptions) error {
-	return exportCSVWithOptions(ds, w, opts)
+	return exportCSVWithOptions(ds.withDynamicColumns(), w, opts)
 }
 
 func importCSV(r io.Reader) (*Dataset, error) {
@@ -119,3 +169,114 @@ func importCSVWithOptions(r io.Reader, delimiter rune, hasHeaders bool) (*Datase
 func ImportCSV(r io.Reader, delimiter rune, hasHeaders bool) (*Dataset, error) {
 	return importCSVWithOptions(r, delimiter, hasHeaders)
 }
+
+// ImportDatabookZIP reads a ZIP archive of CSV and TSV files into a
+// Databook with one sheet per file, the inverse of the Databook CSV export.
+// When the archive has an index.csv manifest its order and sheet titles
+// are used; otherwise files are read in archive order and titled after
+// their names without the extension. Other files are ignored.
+func ImportDatabookZIP(r io.ReaderAt, size int64) (*Databook, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidData
+	}
+
+	files := make(map[string]*zip.File)
+	var names []string
+	for _, f := range zr.File {
+		ext := strings.ToLower(path.Ext(f.Name))
+		if f.FileInfo().IsDir() || (ext != ".csv" && ext != ".tsv") {
+			continue
+		}
+		if strings.HasPrefix(path.Base(f.Name), ".") || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		if f.Name == csvIndexName {
+			continue
+		}
+		files[f.Name] = f
+		names = append(names, f.Name)
+	}
+
+	titles := make(map[string]string)
+	if index, ok := findZIPFile(zr, csvIndexName); ok {
+		ordered, err := readSheetIndex(index, titles)
+		if err != nil {
+			return nil, err
+		}
+		names = ordered
+	}
+
+	db := NewDatabook()
+	for _, name := range names {
+		f, ok := files[name]
+		if !ok {
+			return nil, ErrInvalidData
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		delimiter := ','
+		if strings.EqualFold(path.Ext(name), ".tsv") {
+			delimiter = '\t'
+		}
+		ds, err := importCSVWithOptions(rc, delimiter, true)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		title, ok := titles[name]
+		if !ok {
+			title = strings.TrimSuffix(path.Base(name), path.Ext(name))
+		}
+		ds.SetTitle(title)
+		db.AddSheet(ds)
+	}
+	return db, nil
+}
+
+// importDatabookCSV reads a ZIP archive of CSV files as written by the
+// Databook CSV exporter.
+func importDatabookCSV(r io.Reader) (*Databook, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ImportDatabookZIP(bytes.NewReader(data), int64(len(data)))
+}
+
+// findZIPFile returns the archive member with the given name.
+func findZIPFile(zr *zip.Reader, name string) (*zip.File, bool) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// readSheetIndex reads an index.csv manifest, recording sheet titles by
+// file name and returning the file names in order.
+func readSheetIndex(f *zip.File, titles map[string]string) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	records, err := csv.NewReader(rc).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for i, record := range records {
+		if i == 0 || len(record) < 2 {
+			continue // header
+		}
+		names = append(names, record[0])
+		titles[record[0]] = record[1]
+	}
+	return names, nil
+}
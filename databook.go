@@ -1,15 +1,82 @@
 package tablib
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
 // Databook is a collection of Datasets, similar to a workbook with multiple sheets.
 type Databook struct {
-	sheets []*Dataset
+	sheets     []*Dataset
+	title      string // optional title for the databook
+	author     string
+	created    time.Time
+	properties map[string]string // custom document properties
 }
 
 // NewDatabook creates a new empty Databook.
 func NewDatabook() *Databook {
 	return &Databook{
-		sheets: make([]*Dataset, 0),
+		sheets:     make([]*Dataset, 0),
+		properties: make(map[string]string),
+	}
+}
+
+// Title returns the title of the databook.
+func (db *Databook) Title() string {
+	return db.title
+}
+
+// SetTitle sets the title of the databook.
+func (db *Databook) SetTitle(title string) {
+	db.title = title
+}
+
+// Author returns the author of the databook.
+func (db *Databook) Author() string {
+	return db.author
+}
+
+// SetAuthor sets the author of the databook.
+func (db *Databook) SetAuthor(author string) {
+	db.author = author
+}
+
+// Created returns the creation time of the databook, or the zero time if
+// it is not set.
+func (db *Databook) Created() time.Time {
+	return db.created
+}
+
+// SetCreated sets the creation time of the databook. Exports default to
+// the time of export when it is not set.
+func (db *Databook) SetCreated(created time.Time) {
+	db.created = created
+}
+
+// Property returns the custom document property with the given name.
+func (db *Databook) Property(name string) (string, bool) {
+	v, ok := db.properties[name]
+	return v, ok
+}
+
+// SetProperty sets a custom document property. XLSX and ODS exports write
+// custom properties into the document metadata.
+func (db *Databook) SetProperty(name, value string) {
+	db.properties[name] = value
+}
+
+// Properties returns a copy of the custom document properties.
+func (db *Databook) Properties() map[string]string {
+	result := make(map[string]string, len(db.properties))
+	for k, v := range db.properties {
+		result[k] = v
 	}
+	return result
 }
 
 // AddSheet adds a Dataset to the Databook.
@@ -60,3 +127,292 @@ func (db *Databook) RemoveSheet(index int) error {
 func (db *Databook) Wipe() {
 	db.sheets = make([]*Dataset, 0)
 }
+
+// Apply calls fn with each sheet in order, stopping at the first error.
+func (db *Databook) Apply(fn func(*Dataset) error) error {
+	for _, ds := range db.sheets {
+		if err := fn(ds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchHit locates a cell found by Search.
+type SearchHit struct {
+	Sheet int
+	Row   int
+	Col   int
+}
+
+// Search returns the location of every cell equal to value, comparing
+// values the same way as Sort.
+func (db *Databook) Search(value any) []SearchHit {
+	var hits []SearchHit
+	for s, ds := range db.sheets {
+		for r, row := range ds.data {
+			for c, v := range row {
+				if compareAny(v, value) == 0 {
+					hits = append(hits, SearchHit{Sheet: s, Row: r, Col: c})
+				}
+			}
+		}
+	}
+	return hits
+}
+
+// SubsetSheets returns a new Databook holding the sheets with the given
+// titles, in that order. The sheets are shared, not copied.
+func (db *Databook) SubsetSheets(titles ...string) (*Databook, error) {
+	result := NewDatabook()
+	result.title = db.title
+	result.author = db.author
+	result.created = db.created
+	result.properties = db.Properties()
+	for _, title := range titles {
+		ds, err := db.SheetByTitle(title)
+		if err != nil {
+			return nil, err
+		}
+		result.AddSheet(ds)
+	}
+	return result, nil
+}
+
+// sheetName returns the title of the sheet at index i, or Sheet1, Sheet2
+// and so on by position for untitled sheets.
+func sheetName(i int, ds *Dataset) string {
+	if ds.title != "" {
+		return ds.title
+	}
+	return fmt.Sprintf("Sheet%d", i+1)
+}
+
+// Dict returns the records of every sheet keyed by sheet title, in the
+// form of Dataset.Dict. Untitled sheets are keyed Sheet1, Sheet2 and so on
+// by position, and sheets sharing a title have their records combined.
+func (db *Databook) Dict() (map[string][]map[string]any, error) {
+	result := make(map[string][]map[string]any, len(db.sheets))
+	for i, ds := range db.sheets {
+		records, err := ds.Dict()
+		if err != nil {
+			return nil, err
+		}
+		name := sheetName(i, ds)
+		result[name] = append(result[name], records...)
+	}
+	return result, nil
+}
+
+// SplitBy returns a Databook with one sheet per distinct value of the
+// column with the specified header, in the order the values first appear.
+// Each sheet holds the matching rows with all columns and their tags, and
+// is titled with the value's text; values with the same text, such as 1
+// and "1", share a sheet, and nil values share an untitled sheet.
+func (ds *Dataset) SplitBy(header string) (*Databook, error) {
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return nil, ErrColumnNotFound
+	}
+	var titles []string
+	groups := make(map[string][]int)
+	for i, row := range ds.data {
+		title := valueString(row[col])
+		if _, ok := groups[title]; !ok {
+			titles = append(titles, title)
+		}
+		groups[title] = append(groups[title], i)
+	}
+
+	db := NewDatabook()
+	for _, title := range titles {
+		sheet := ds.selectRows(groups[title])
+		sheet.title = title
+		db.AddSheet(sheet)
+	}
+	return db, nil
+}
+
+// Flatten stacks every sheet into a single Dataset with a leading
+// sheetColumn holding each row's sheet title. The columns are the union of
+// the sheets' headers in the order first seen; cells missing from a sheet
+// are nil. Row tags are kept.
+func (db *Databook) Flatten(sheetColumn string) (*Dataset, error) {
+	headers := []string{sheetColumn}
+	index := map[string]int{sheetColumn: 0}
+	for _, ds := range db.sheets {
+		if len(ds.headers) == 0 {
+			return nil, ErrHeadersRequired
+		}
+		for _, h := range ds.headers {
+			if h == sheetColumn {
+				return nil, ErrInvalidData
+			}
+			if _, ok := index[h]; !ok {
+				index[h] = len(headers)
+				headers = append(headers, h)
+			}
+		}
+	}
+
+	result := NewDataset(headers)
+	for i, ds := range db.sheets {
+		name := sheetName(i, ds)
+		for r, row := range ds.data {
+			out := make([]any, len(headers))
+			out[0] = name
+			for c, h := range ds.headers {
+				out[index[h]] = row[c]
+			}
+			t := make([]string, len(ds.tags[r]))
+			copy(t, ds.tags[r])
+			result.data = append(result.data, out)
+			result.tags = append(result.tags, t)
+		}
+	}
+	return result, nil
+}
+
+// ConflictPolicy decides how Merge handles sheets whose titles collide.
+type ConflictPolicy int
+
+const (
+	// ConflictRename adds the incoming sheet with a numbered title, such
+	// as "Sales (2)".
+	ConflictRename ConflictPolicy = iota
+	// ConflictStack appends the incoming sheet's rows to the existing
+	// sheet of the same title. The sheets must have the same width.
+	ConflictStack
+)
+
+// Merge adds the sheets of other to the Databook. Sheets with a title
+// already present are renamed or stacked according to conflict; untitled
+// sheets never collide. The Databook is left unchanged on error.
+func (db *Databook) Merge(other *Databook, conflict ConflictPolicy) error {
+	sheets := make([]*Dataset, len(db.sheets), len(db.sheets)+len(other.sheets))
+	copy(sheets, db.sheets)
+
+	titles := make(map[string]int) // title -> index in sheets
+	for i, ds := range sheets {
+		if _, ok := titles[ds.title]; !ok && ds.title != "" {
+			titles[ds.title] = i
+		}
+	}
+
+	for _, ds := range other.sheets {
+		idx, collides := titles[ds.title]
+		switch {
+		case !collides:
+			if ds.title != "" {
+				titles[ds.title] = len(sheets)
+			}
+			sheets = append(sheets, ds)
+		case conflict == ConflictStack:
+			stacked, err := sheets[idx].StackRows(ds)
+			if err != nil {
+				return err
+			}
+			sheets[idx] = stacked
+		default:
+			renamed := ds.Copy()
+			for n := 2; ; n++ {
+				renamed.title = fmt.Sprintf("%s (%d)", ds.title, n)
+				if _, ok := titles[renamed.title]; !ok {
+					break
+				}
+			}
+			titles[renamed.title] = len(sheets)
+			sheets = append(sheets, renamed)
+		}
+	}
+
+	db.sheets = sheets
+	return nil
+}
+
+// formatExtensions maps formats to file extensions where they differ from
+// the format name.
+var formatExtensions = map[Format]string{
+	FormatMarkdown: ".md",
+	FormatLatex:    ".tex",
+	FormatCLI:      ".txt",
+	FormatJira:     ".txt",
+	FormatDokuWiki: ".txt",
+	FormatTracWiki: ".txt",
+}
+
+// formatExtension returns the file extension used for format.
+func formatExtension(format Format) string {
+	if ext, ok := formatExtensions[format]; ok {
+		return ext
+	}
+	return "." + string(format)
+}
+
+// ExportDir writes each sheet to its own file in dir, creating dir if
+// needed. Files are named after the sheet titles with unsafe characters
+// replaced, and an index.csv manifest lists each file with its sheet title
+// and row count. A sheet that would be named index.csv gets a numbered
+// name instead, so the manifest never replaces it.
+func (db *Databook) ExportDir(dir string, format Format) error {
+	if _, ok := exporters[format]; !ok {
+		return ErrUnsupportedFormat
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	names := sheetFileNames(db.sheets, formatExtension(format))
+	for i, ds := range db.sheets {
+		if err := writeFile(filepath.Join(dir, names[i]), func(f *os.File) error {
+			return ds.Export(format, f)
+		}); err != nil {
+			return err
+		}
+	}
+	return writeFile(filepath.Join(dir, csvIndexName), func(f *os.File) error {
+		return writeSheetIndex(f, db, names)
+	})
+}
+
+// writeFile creates path and fills it with write, reporting close errors.
+func writeFile(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// sheetFileNames returns a unique, filesystem-safe file name with the
+// given extension for each sheet, derived from its title. Untitled sheets
+// are named Sheet1, Sheet2 and so on by position. No sheet is named after
+// the index.csv manifest.
+func sheetFileNames(sheets []*Dataset, ext string) []string {
+	names := make([]string, len(sheets))
+	used := map[string]bool{csvIndexName: true}
+	for i, ds := range sheets {
+		base := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(" -_.", r) {
+				return r
+			}
+			return '_'
+		}, ds.title)
+		base = strings.Trim(base, " .")
+		if base == "" {
+			base = fmt.Sprintf("Sheet%d", i+1)
+		}
+
+		name := base + ext
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s_%d%s", base, n, ext)
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
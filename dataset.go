@@ -3,7 +3,12 @@ package tablib
 import (
 	"cmp"
 	"fmt"
+	"maps"
+	"math/rand/v2"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // DynamicColumn represents a function that computes a column value based on a row.
@@ -12,34 +17,83 @@ type DynamicColumn func(row []any) any
 // Formatter represents a function that formats cell values during export.
 type Formatter func(value any) any
 
+// namedFormatter is a registered formatter. Formatters added with
+// AddFormatter have no name.
+type namedFormatter struct {
+	name string
+	fn   Formatter
+}
+
 // Separator represents a separator row in the dataset.
 type Separator struct {
 	Text string
 }
 
+// Formula marks a cell as a spreadsheet formula. Expression holds the formula
+// text (e.g. "=SUM(A1:A3)") and Value an optional cached result that is
+// written alongside it for readers that do not recalculate.
+type Formula struct {
+	Expression string
+	Value      any
+}
+
+// String returns the formula expression.
+func (f Formula) String() string {
+	return f.Expression
+}
+
+// Alignment is a column alignment hint used by text exporters.
+type Alignment int
+
+const (
+	AlignDefault Alignment = iota // exporter's default (usually left)
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
 // Dataset is the primary data structure for tabular data.
 type Dataset struct {
 	headers     []string
 	data        [][]any
-	tags        [][]string // tags for each row
-	title       string     // optional title for the dataset
-	dynamicCols map[string]DynamicColumn
-	formatters  []Formatter
-	separators  map[int]Separator // row index -> separator (separator appears before the row)
+	tags        [][]string      // tags for each row
+	title       string          // optional title for the dataset
+	dynamicCols []dynamicColumn // in column order
+	formatters  []namedFormatter
+	separators  map[int]Separator        // row index -> separator (separator appears before the row)
+	alignments  map[string]Alignment     // header -> alignment hint
+	formats     map[string]columnFormat  // header -> display format
+	columnTags  map[string][]string      // header -> column tags
+	indexes     map[string]map[any][]int // header -> value -> row indices, see BuildIndex
+	comments    map[cellKey]string       // cell -> comment, see SetCellComment
+	sharing     *rowSharing              // whether rows are shared with a copy
+	widths      *widthCache              // cell widths for text exports
+
+	constraints       map[string][]Constraint // header -> constraints, see SetConstraint
+	collectViolations bool
+	violations        []Violation
+
+	history *history // states before recent changes, see EnableHistory
+	frozen  bool     // see Freeze
 }
 
 // NewDataset creates a new empty Dataset.
 func NewDataset(headers []string) *Dataset {
 	h := make([]string, len(headers))
 	copy(h, headers)
-	return &Dataset{
-		headers:     h,
-		data:        make([][]any, 0),
-		tags:        make([][]string, 0),
-		dynamicCols: make(map[string]DynamicColumn),
-		formatters:  make([]Formatter, 0),
-		separators:  make(map[int]Separator),
+	ds := &Dataset{
+		headers:    h,
+		data:       make([][]any, 0),
+		tags:       make([][]string, 0),
+		formatters: make([]namedFormatter, 0),
+		separators: make(map[int]Separator),
+		alignments: make(map[string]Alignment),
+		formats:    make(map[string]columnFormat),
+		columnTags: make(map[string][]string),
+		sharing:    &rowSharing{},
 	}
+	ds.widths = &widthCache{owner: ds}
+	return ds
 }
 
 // NewDatasetWithData creates a Dataset with initial data.
@@ -53,26 +107,108 @@ func NewDatasetWithData(headers []string, data [][]any) (*Dataset, error) {
 	return ds, nil
 }
 
-// Headers returns the headers of the dataset.
+// Headers returns the headers of the dataset, including those of dynamic
+// columns in their positions. Column indices throughout the Dataset API
+// are positions in this list; rows passed to Append, Insert and SetRow,
+// and returned by Row and Pop, hold the stored columns only.
 func (ds *Dataset) Headers() []string {
 	if ds.headers == nil {
 		return nil
 	}
-	h := make([]string, len(ds.headers))
-	copy(h, ds.headers)
-	return h
+	if len(ds.headers) == 0 {
+		return []string{}
+	}
+	return ds.effectiveHeaders()
 }
 
-// SetHeaders sets the headers of the dataset.
+// SetHeaders sets the headers of the stored columns of the dataset.
 func (ds *Dataset) SetHeaders(headers []string) error {
-	if len(ds.data) > 0 && len(headers) != ds.Width() {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if len(ds.data) > 0 && len(headers) != ds.storedWidth() {
 		return ErrInvalidDimensions
 	}
+	ds.record()
 	ds.headers = make([]string, len(headers))
 	copy(ds.headers, headers)
+	ds.dropIndexes()
 	return nil
 }
 
+// RenameColumn renames the column with the specified header, including a
+// dynamic column. See RenameColumns.
+func (ds *Dataset) RenameColumn(old, name string) error {
+	return ds.RenameColumns(map[string]string{old: name})
+}
+
+// RenameColumns renames columns by mapping old headers to new ones. All
+// renames apply at once, so two columns can swap names. Alignments,
+// display formats and dynamic columns follow their columns. An unknown
+// header returns ErrColumnNotFound, and a rename that would give two
+// columns the same header returns ErrInvalidData; either leaves the
+// dataset unchanged.
+func (ds *Dataset) RenameColumns(names map[string]string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	for old := range names {
+		if ds.dynamicIndex(old) == -1 && ds.headerIndex(old) == -1 {
+			return ErrColumnNotFound
+		}
+	}
+
+	headers := slices.Clone(ds.headers)
+	for i, h := range headers {
+		if name, ok := names[h]; ok {
+			headers[i] = name
+		}
+	}
+	counts := make(map[string]int)
+	for _, h := range headers {
+		counts[h]++
+	}
+	for _, dc := range ds.dynamicCols {
+		h := dc.header
+		if name, ok := names[h]; ok {
+			h = name
+		}
+		counts[h]++
+	}
+	for old, name := range names {
+		if old != name && counts[name] > 1 {
+			return ErrInvalidData
+		}
+	}
+
+	ds.record()
+	ds.headers = headers
+	ds.dropIndexes()
+	for i, dc := range ds.dynamicCols {
+		if name, ok := names[dc.header]; ok {
+			ds.dynamicCols[i].header = name
+		}
+	}
+	renameKeys(ds.alignments, names)
+	renameKeys(ds.formats, names)
+	renameKeys(ds.columnTags, names)
+	renameKeys(ds.constraints, names)
+	ds.renameCommentHeaders(names)
+	return nil
+}
+
+// renameKeys moves the entries of m to their new names.
+func renameKeys[V any](m map[string]V, names map[string]string) {
+	renamed := make(map[string]V)
+	for old, name := range names {
+		if v, ok := m[old]; ok {
+			renamed[name] = v
+			delete(m, old)
+		}
+	}
+	maps.Copy(m, renamed)
+}
+
 // Title returns the title of the dataset.
 func (ds *Dataset) Title() string {
 	return ds.title
@@ -80,6 +216,9 @@ func (ds *Dataset) Title() string {
 
 // SetTitle sets the title of the dataset.
 func (ds *Dataset) SetTitle(title string) {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
 	ds.title = title
 }
 
@@ -88,8 +227,15 @@ func (ds *Dataset) Height() int {
 	return len(ds.data)
 }
 
-// Width returns the number of columns in the dataset.
+// Width returns the number of columns in the dataset, including dynamic
+// columns.
 func (ds *Dataset) Width() int {
+	return ds.storedWidth() + len(ds.dynamicCols)
+}
+
+// storedWidth returns the number of columns stored in the rows, which
+// excludes dynamic columns.
+func (ds *Dataset) storedWidth() int {
 	if len(ds.headers) > 0 {
 		return len(ds.headers)
 	}
@@ -101,12 +247,21 @@ func (ds *Dataset) Width() int {
 
 // Append adds a row to the dataset.
 func (ds *Dataset) Append(row []any, rowTags ...string) error {
-	if ds.Width() > 0 && len(row) != ds.Width() {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if ds.storedWidth() > 0 && len(row) != ds.storedWidth() {
 		return ErrInvalidDimensions
 	}
+	if err := ds.enforceConstraints(ds.rowViolations(row, len(ds.data), -1, -1)); err != nil {
+		return err
+	}
+	ds.record()
 	r := make([]any, len(row))
 	copy(r, row)
 	ds.data = append(ds.data, r)
+	ds.indexRow(len(ds.data) - 1)
+	ds.dropWidths()
 
 	t := make([]string, len(rowTags))
 	copy(t, rowTags)
@@ -119,18 +274,33 @@ func (ds *Dataset) AppendTagged(row []any, tags []string) error {
 	return ds.Append(row, tags...)
 }
 
-// Insert inserts a row at the specified index.
+// Insert inserts a row at the specified index. Separators and cell
+// comments of the rows after it move down with them. Inserting at Height
+// adds the row at the end as Append does, after any trailing separator.
 func (ds *Dataset) Insert(index int, row []any, rowTags ...string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
 	if index < 0 || index > len(ds.data) {
 		return ErrInvalidRowIndex
 	}
-	if ds.Width() > 0 && len(row) != ds.Width() {
+	if ds.storedWidth() > 0 && len(row) != ds.storedWidth() {
 		return ErrInvalidDimensions
 	}
+	if err := ds.enforceConstraints(ds.rowViolations(row, index, -1, -1)); err != nil {
+		return err
+	}
+	ds.record()
 
 	r := make([]any, len(row))
 	copy(r, row)
+	atEnd := index == len(ds.data)
 	ds.data = slices.Insert(ds.data, index, r)
+	ds.dropIndexes()
+	ds.shiftComments(index, 1)
+	if !atEnd {
+		ds.shiftSeparators(index, 1)
+	}
 
 	t := make([]string, len(rowTags))
 	copy(t, rowTags)
@@ -138,14 +308,52 @@ func (ds *Dataset) Insert(index int, row []any, rowTags ...string) error {
 	return nil
 }
 
-// Pop removes and returns the row at the specified index.
+// SetRow replaces the row at the specified index and its tags. Separators
+// and cell comments stay in place, unlike with Pop and Insert.
+func (ds *Dataset) SetRow(index int, row []any, rowTags ...string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if index < 0 || index >= len(ds.data) {
+		return ErrInvalidRowIndex
+	}
+	if ds.storedWidth() > 0 && len(row) != ds.storedWidth() {
+		return ErrInvalidDimensions
+	}
+	if err := ds.enforceConstraints(ds.rowViolations(row, index, index, -1)); err != nil {
+		return err
+	}
+	ds.record()
+
+	old := ds.data[index]
+	ds.data[index] = slices.Clone(row)
+	ds.tags[index] = slices.Clone(rowTags)
+	for col := range old {
+		ds.reindexCell(index, col, old[col])
+	}
+	ds.dropWidths()
+	return nil
+}
+
+// Pop removes and returns the row at the specified index, along with its
+// separator and cell comments; those of the rows after it move up.
 func (ds *Dataset) Pop(index int) ([]any, error) {
+	if ds.frozen {
+		return nil, ErrFrozen
+	}
 	if index < 0 || index >= len(ds.data) {
 		return nil, ErrInvalidRowIndex
 	}
+	ds.record()
 	row := ds.data[index]
+	if ds.rowsShared() {
+		row = slices.Clone(row)
+	}
 	ds.data = slices.Delete(ds.data, index, index+1)
 	ds.tags = slices.Delete(ds.tags, index, index+1)
+	ds.dropIndexes()
+	ds.shiftComments(index, -1)
+	ds.shiftSeparators(index, -1)
 	return row, nil
 }
 
@@ -165,6 +373,62 @@ func (ds *Dataset) Rpop() ([]any, error) {
 	return ds.Pop(len(ds.data) - 1)
 }
 
+// DeleteRows removes the rows from start up to, but not including, end,
+// along with their tags, separators and cell comments.
+func (ds *Dataset) DeleteRows(start, end int) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if start < 0 || end > len(ds.data) || start > end {
+		return ErrInvalidRowIndex
+	}
+	if start == end {
+		return nil
+	}
+	ds.record()
+	ds.data = slices.Delete(ds.data, start, end)
+	ds.tags = slices.Delete(ds.tags, start, end)
+	ds.dropIndexes()
+	ds.shiftComments(start, start-end)
+	ds.shiftSeparators(start, start-end)
+	return nil
+}
+
+// DeleteRowsWhere removes every row for which pred returns true, along
+// with its tags, separator and cell comments, and returns the number of rows
+// removed. pred must not modify the row.
+func (ds *Dataset) DeleteRowsWhere(pred func(row []any) bool) int {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	kept := make([]int, 0, len(ds.data))
+	for i, row := range ds.data {
+		if !pred(row) {
+			kept = append(kept, i)
+		}
+	}
+	n := len(ds.data) - len(kept)
+	if n == 0 {
+		return 0
+	}
+	ds.record()
+
+	comments := ds.selectComments(kept)
+	separators := ds.selectSeparators(kept)
+	if sep, ok := ds.separators[len(ds.data)]; ok {
+		separators[len(kept)] = sep
+	}
+	data := make([][]any, len(kept))
+	tags := make([][]string, len(kept))
+	for i, r := range kept {
+		data[i] = ds.data[r]
+		tags[i] = ds.tags[r]
+	}
+	ds.data, ds.tags, ds.comments, ds.separators = data, tags, comments, separators
+	ds.dropIndexes()
+	return n
+}
+
 // Lpush adds a row at the beginning of the dataset.
 func (ds *Dataset) Lpush(row []any, rowTags ...string) error {
 	return ds.Insert(0, row, rowTags...)
@@ -195,118 +459,331 @@ func (ds *Dataset) Row(index int) ([]any, error) {
 	return row, nil
 }
 
-// Column returns all values in the specified column by index.
+// Column returns all values in the specified column by index, computing
+// them for a dynamic column.
 func (ds *Dataset) Column(index int) ([]any, error) {
-	if index < 0 || index >= ds.Width() {
+	c, ok := ds.columnAt(index)
+	if !ok {
 		return nil, ErrInvalidColumnIndex
 	}
 	col := make([]any, len(ds.data))
 	for i, row := range ds.data {
-		col[i] = row[index]
+		col[i] = ds.cellValue(c, row)
 	}
 	return col, nil
 }
 
-// ColumnByHeader returns all values in the column with the specified header.
+// ColumnByHeader returns all values in the column with the specified
+// header, computing them for a dynamic column.
 func (ds *Dataset) ColumnByHeader(header string) ([]any, error) {
-	index := ds.headerIndex(header)
+	index := ds.columnIndex(header)
 	if index == -1 {
 		return nil, ErrColumnNotFound
 	}
 	return ds.Column(index)
 }
 
-// AppendCol adds a column to the dataset.
+// ColumnOptions configures AppendColWith and InsertColWith.
+type ColumnOptions struct {
+	// Pad accepts a column whose length differs from the row count. Short
+	// columns are padded with Fill, and a longer column adds rows holding
+	// Fill in every other column.
+	Pad  bool
+	Fill any
+}
+
+// AppendCol adds a column after the stored columns, and before dynamic
+// columns added by AddDynamicColumn.
 func (ds *Dataset) AppendCol(header string, col []any) error {
-	if len(ds.data) > 0 && len(col) != len(ds.data) {
-		return ErrInvalidDimensions
-	}
+	return ds.AppendColWith(header, col, ColumnOptions{})
+}
 
-	// If dataset is empty, initialize rows
-	if len(ds.data) == 0 && len(col) > 0 {
-		ds.data = make([][]any, len(col))
-		ds.tags = make([][]string, len(col))
-		for i := range ds.data {
-			ds.data[i] = make([]any, 0)
-			ds.tags[i] = make([]string, 0)
-		}
-	}
+// AppendColWith adds a column to the dataset with custom options.
+func (ds *Dataset) AppendColWith(header string, col []any, opts ColumnOptions) error {
+	return ds.insertCol(ds.storedWidth(), header, col, opts)
+}
 
-	ds.headers = append(ds.headers, header)
-	for i := range ds.data {
-		ds.data[i] = append(ds.data[i], col[i])
+// InsertCol inserts a column at the specified index.
+func (ds *Dataset) InsertCol(index int, header string, col []any) error {
+	return ds.InsertColWith(index, header, col, ColumnOptions{})
+}
+
+// InsertColWith inserts a column at the specified index with custom options.
+func (ds *Dataset) InsertColWith(index int, header string, col []any, opts ColumnOptions) error {
+	layout := ds.columnLayout()
+	if index < 0 || index > len(layout) {
+		return ErrInvalidColumnIndex
+	}
+	stored, pos := layoutCounts(layout[:index])
+	if err := ds.insertCol(stored, header, col, opts); err != nil {
+		return err
+	}
+	// Keep dynamic columns on their side of the new column
+	for d := range ds.dynamicCols {
+		dc := &ds.dynamicCols[d]
+		switch {
+		case d < pos && dc.before == -1:
+			dc.before = stored
+		case d >= pos && dc.before == stored:
+			dc.before++
+		}
 	}
 	return nil
 }
 
-// InsertCol inserts a column at the specified index.
-func (ds *Dataset) InsertCol(index int, header string, col []any) error {
-	if index < 0 || index > ds.Width() {
+func (ds *Dataset) insertCol(index int, header string, col []any, opts ColumnOptions) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if index < 0 || index > ds.storedWidth() {
 		return ErrInvalidColumnIndex
 	}
-	if len(ds.data) > 0 && len(col) != len(ds.data) {
+	if !opts.Pad && len(ds.data) > 0 && len(col) != len(ds.data) {
 		return ErrInvalidDimensions
 	}
-
-	// If dataset is empty, initialize rows
-	if len(ds.data) == 0 && len(col) > 0 {
-		ds.data = make([][]any, len(col))
-		ds.tags = make([][]string, len(col))
-		for i := range ds.data {
-			ds.data[i] = make([]any, 0)
-			ds.tags[i] = make([]string, 0)
+	ds.record()
+
+	// Add rows for the cells beyond the current height
+	width := ds.storedWidth()
+	for len(ds.data) < len(col) {
+		row := make([]any, width)
+		for i := range row {
+			row[i] = opts.Fill
 		}
+		ds.data = append(ds.data, row)
+		ds.tags = append(ds.tags, make([]string, 0))
 	}
 
-	ds.headers = slices.Insert(ds.headers, index, header)
+	// Datasets without headers gain a header for the new column only
+	ds.headers = slices.Insert(ds.headers, min(index, len(ds.headers)), header)
+	ds.shiftDynamicColumns(index, 1)
 	for i := range ds.data {
-		ds.data[i] = slices.Insert(ds.data[i], index, col[i])
+		v := opts.Fill
+		if i < len(col) {
+			v = col[i]
+		}
+		// Rows may be shared with SliceRows, so never insert in place
+		ds.data[i] = slices.Insert(slices.Clip(ds.data[i]), index, v)
 	}
+	ds.dropIndexes()
 	return nil
 }
 
-// DeleteCol removes the column at the specified index.
+// DeleteCol removes the column at the specified index, which may be a
+// dynamic column. Unless another column has the same header, its
+// constraints, alignment, format, tags and cell comments are removed too.
 func (ds *Dataset) DeleteCol(index int) error {
-	if index < 0 || index >= ds.Width() {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	c, ok := ds.columnAt(index)
+	if !ok {
 		return ErrInvalidColumnIndex
 	}
+	ds.record()
+	if c.dynamic {
+		header := ds.dynamicCols[c.index].header
+		ds.dynamicCols = slices.Delete(ds.dynamicCols, c.index, c.index+1)
+		ds.dropColumnSettings(header)
+		return nil
+	}
+	index = c.index
+	header := ds.headers[index]
 	ds.headers = slices.Delete(ds.headers, index, index+1)
-	for i := range ds.data {
-		ds.data[i] = slices.Delete(ds.data[i], index, index+1)
+	ds.shiftDynamicColumns(index, -1)
+	for i, row := range ds.data {
+		// Rows may be shared with SliceRows, so never delete in place
+		ds.data[i] = slices.Concat(row[:index], row[index+1:])
 	}
+	ds.dropColumnSettings(header)
+	ds.dropIndexes()
 	return nil
 }
 
+// dropColumnSettings removes the settings kept for header once no column
+// has it, so that a column added later under that header does not inherit
+// them.
+func (ds *Dataset) dropColumnSettings(header string) {
+	if ds.columnIndex(header) != -1 {
+		return
+	}
+	delete(ds.constraints, header)
+	delete(ds.alignments, header)
+	delete(ds.formats, header)
+	delete(ds.columnTags, header)
+	for k := range ds.comments {
+		if k.header == header {
+			delete(ds.comments, k)
+		}
+	}
+}
+
 // DeleteColByHeader removes the column with the specified header.
 func (ds *Dataset) DeleteColByHeader(header string) error {
-	index := ds.headerIndex(header)
+	index := ds.columnIndex(header)
 	if index == -1 {
 		return ErrColumnNotFound
 	}
 	return ds.DeleteCol(index)
 }
 
-// AddDynamicColumn adds a dynamic (computed) column to the dataset.
-func (ds *Dataset) AddDynamicColumn(header string, fn DynamicColumn) {
-	ds.dynamicCols[header] = fn
-}
-
 // AddFormatter adds a formatter function that will be applied to cell values during export.
 func (ds *Dataset) AddFormatter(fn Formatter) {
-	ds.formatters = append(ds.formatters, fn)
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	ds.formatters = append(ds.formatters, namedFormatter{fn: fn})
+}
+
+// AddNamedFormatter adds a formatter that can be removed by name. Adding a
+// formatter under a name already in use replaces it in its position.
+func (ds *Dataset) AddNamedFormatter(name string, fn Formatter) {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	if i := ds.formatterIndex(name); i != -1 {
+		ds.formatters[i].fn = fn
+		return
+	}
+	ds.formatters = append(ds.formatters, namedFormatter{name: name, fn: fn})
+}
+
+// RemoveFormatter removes the formatter with the specified name and
+// reports whether it was found. Unnamed formatters cannot be removed.
+func (ds *Dataset) RemoveFormatter(name string) bool {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	i := ds.formatterIndex(name)
+	if i == -1 {
+		return false
+	}
+	ds.formatters = slices.Delete(ds.formatters, i, i+1)
+	return true
+}
+
+// Formatters returns the names of the formatters in the order they are
+// applied, with "" for formatters added by AddFormatter.
+func (ds *Dataset) Formatters() []string {
+	names := make([]string, len(ds.formatters))
+	for i, f := range ds.formatters {
+		names[i] = f.name
+	}
+	return names
+}
+
+// formatterIndex returns the index of the named formatter, or -1.
+func (ds *Dataset) formatterIndex(name string) int {
+	if name == "" {
+		return -1
+	}
+	return slices.IndexFunc(ds.formatters, func(f namedFormatter) bool {
+		return f.name == name
+	})
 }
 
 // ApplyFormatters applies all registered formatters to a value.
 func (ds *Dataset) ApplyFormatters(value any) any {
 	result := value
-	for _, fn := range ds.formatters {
-		result = fn(result)
+	for _, f := range ds.formatters {
+		result = f.fn(result)
 	}
 	return result
 }
 
+// SetAlignment sets the alignment hint for the column with the specified header.
+func (ds *Dataset) SetAlignment(header string, align Alignment) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if ds.headerIndex(header) == -1 {
+		return ErrColumnNotFound
+	}
+	ds.alignments[header] = align
+	return nil
+}
+
+// Alignment returns the alignment hint for the column with the specified header.
+func (ds *Dataset) Alignment(header string) Alignment {
+	return ds.alignments[header]
+}
+
+// TagColumn adds tags to the column with the specified header. Column
+// tags select columns with SubsetByTag and DropColumnsByTag.
+func (ds *Dataset) TagColumn(header string, tags ...string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if ds.headerIndex(header) == -1 {
+		return ErrColumnNotFound
+	}
+	colTags := slices.Clone(ds.columnTags[header])
+	for _, tag := range tags {
+		if !slices.Contains(colTags, tag) {
+			colTags = append(colTags, tag)
+		}
+	}
+	ds.columnTags[header] = colTags
+	return nil
+}
+
+// UntagColumn removes tags from the column with the specified header.
+func (ds *Dataset) UntagColumn(header string, tags ...string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if ds.headerIndex(header) == -1 {
+		return ErrColumnNotFound
+	}
+	colTags := slices.DeleteFunc(slices.Clone(ds.columnTags[header]), func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+	if len(colTags) == 0 {
+		delete(ds.columnTags, header)
+		return nil
+	}
+	ds.columnTags[header] = colTags
+	return nil
+}
+
+// ColumnTags returns the tags of the column with the specified header.
+func (ds *Dataset) ColumnTags(header string) []string {
+	return slices.Clone(ds.columnTags[header])
+}
+
+// taggedColumns returns the headers of the columns tagged with tag, in
+// column order.
+func (ds *Dataset) taggedColumns(tag string) []string {
+	var headers []string
+	for _, h := range ds.headers {
+		if slices.Contains(ds.columnTags[h], tag) {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// SubsetByTag returns a new Dataset with only the columns tagged with tag.
+func (ds *Dataset) SubsetByTag(tag string) *Dataset {
+	result, _ := ds.Subset(ds.taggedColumns(tag))
+	return result
+}
+
+// DropColumnsByTag removes the columns tagged with tag.
+func (ds *Dataset) DropColumnsByTag(tag string) {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	for _, h := range ds.taggedColumns(tag) {
+		ds.DeleteColByHeader(h)
+	}
+}
+
 // InsertSeparator inserts a separator before the row at the specified index.
 func (ds *Dataset) InsertSeparator(index int, text string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
 	if index < 0 || index > len(ds.data) {
 		return ErrInvalidRowIndex
 	}
@@ -315,7 +792,12 @@ func (ds *Dataset) InsertSeparator(index int, text string) error {
 }
 
 // AppendSeparator adds a separator at the end of the dataset (after the last row).
+// Like any separator, it stays before the row at its index, so a row added
+// later with Append or Insert at Height follows it.
 func (ds *Dataset) AppendSeparator(text string) {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
 	ds.separators[len(ds.data)] = Separator{Text: text}
 }
 
@@ -340,69 +822,360 @@ func (ds *Dataset) Separators() map[int]Separator {
 	return result
 }
 
-// Get returns a cell value by row and column index.
+// shiftSeparators moves the separators of rows from index on by delta,
+// after rows are inserted or removed. Separators of removed rows are
+// dropped; one after the last row stays there.
+func (ds *Dataset) shiftSeparators(index, delta int) {
+	if len(ds.separators) == 0 {
+		return
+	}
+	separators := make(map[int]Separator, len(ds.separators))
+	for i, sep := range ds.separators {
+		switch {
+		case i < index:
+		case delta < 0 && i < index-delta:
+			continue
+		default:
+			i += delta
+		}
+		separators[i] = sep
+	}
+	ds.separators = separators
+}
+
+// selectSeparators returns the separators of a selection of rows, where
+// row i of the selection is row rows[i] of the dataset, so that each
+// separator stays before its row. A separator after the last row stays
+// after the selection when it includes the last row.
+func (ds *Dataset) selectSeparators(rows []int) map[int]Separator {
+	separators := make(map[int]Separator)
+	for j, i := range rows {
+		if sep, ok := ds.separators[i]; ok {
+			separators[j] = sep
+		}
+	}
+	if sep, ok := ds.separators[len(ds.data)]; ok && slices.Contains(rows, len(ds.data)-1) {
+		separators[len(rows)] = sep
+	}
+	return separators
+}
+
+// Get returns a cell value by row and column index, computing it for a
+// dynamic column.
 func (ds *Dataset) Get(row, col int) (any, error) {
 	if row < 0 || row >= len(ds.data) {
 		return nil, ErrInvalidRowIndex
 	}
-	if col < 0 || col >= ds.Width() {
+	c, ok := ds.columnAt(col)
+	if !ok {
 		return nil, ErrInvalidColumnIndex
 	}
-	return ds.data[row][col], nil
+	return ds.cellValue(c, ds.data[row]), nil
 }
 
-// Set sets a cell value by row and column index.
+// Set sets a cell value by row and column index. Dynamic columns cannot be
+// set and return ErrInvalidColumnIndex.
 func (ds *Dataset) Set(row, col int, value any) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
 	if row < 0 || row >= len(ds.data) {
 		return ErrInvalidRowIndex
 	}
-	if col < 0 || col >= ds.Width() {
+	c, ok := ds.columnAt(col)
+	if !ok || c.dynamic {
 		return ErrInvalidColumnIndex
 	}
+	col = c.index
+	if len(ds.constraints) > 0 {
+		updated := slices.Clone(ds.data[row])
+		updated[col] = value
+		if err := ds.enforceConstraints(ds.rowViolations(updated, row, row, col)); err != nil {
+			return err
+		}
+	}
+	ds.record()
+	ds.ownRow(row)
+	old := ds.data[row][col]
 	ds.data[row][col] = value
+	ds.reindexCell(row, col, old)
+	ds.dropWidths()
+	return nil
+}
+
+// MapColumn replaces each cell of the column with the specified header
+// with the result of fn.
+func (ds *Dataset) MapColumn(header string, fn func(any) any) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	idx := ds.headerIndex(header)
+	if idx == -1 {
+		return ErrColumnNotFound
+	}
+	values := make([]any, len(ds.data))
+	for i, row := range ds.data {
+		values[i] = fn(row[idx])
+	}
+	if len(ds.constraints[header]) > 0 {
+		changes := make([]cellChange, len(values))
+		for i, v := range values {
+			changes[i] = cellChange{row: i, col: idx, value: v}
+		}
+		if err := ds.enforceConstraints(ds.changeViolations(changes)); err != nil {
+			return err
+		}
+	}
+	ds.record()
+	ds.ownRows()
+	for i, row := range ds.data {
+		row[idx] = values[i]
+	}
+	ds.dropIndexes()
+	return nil
+}
+
+// UpdateWhere sets the column with the specified header to newValue in
+// every row for which predicate returns true, and returns the number of
+// rows updated.
+func (ds *Dataset) UpdateWhere(predicate func(row []any) bool, header string, newValue any) (int, error) {
+	if ds.frozen {
+		return 0, ErrFrozen
+	}
+	idx := ds.headerIndex(header)
+	if idx == -1 {
+		return 0, ErrColumnNotFound
+	}
+	var matched []int
+	for i, row := range ds.data {
+		if predicate(row) {
+			matched = append(matched, i)
+		}
+	}
+	if len(matched) == 0 {
+		return 0, nil
+	}
+	if len(ds.constraints[header]) > 0 {
+		changes := make([]cellChange, len(matched))
+		for i, row := range matched {
+			changes[i] = cellChange{row: row, col: idx, value: newValue}
+		}
+		if err := ds.enforceConstraints(ds.changeViolations(changes)); err != nil {
+			return 0, err
+		}
+	}
+	ds.record()
+	for _, i := range matched {
+		ds.ownRow(i)
+		ds.data[i][idx] = newValue
+	}
+	ds.dropIndexes()
+	return len(matched), nil
+}
+
+// MapRows replaces each row with the result of fn, which receives a copy
+// of the row. If fn returns a row of a different width, MapRows returns
+// ErrInvalidDimensions and leaves the dataset unchanged.
+func (ds *Dataset) MapRows(fn func(row []any) []any) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	mapped := make([][]any, len(ds.data))
+	for i, row := range ds.data {
+		r := fn(slices.Clone(row))
+		if len(r) != len(row) {
+			return ErrInvalidDimensions
+		}
+		mapped[i] = r
+	}
+	if len(ds.constraints) > 0 {
+		var changes []cellChange
+		for i, row := range mapped {
+			changes = ds.rowChanges(changes, i, row)
+		}
+		if err := ds.enforceConstraints(ds.changeViolations(changes)); err != nil {
+			return err
+		}
+	}
+	ds.record()
+	ds.data = mapped
+	ds.dropIndexes()
 	return nil
 }
 
 // Filter returns a new Dataset containing only rows with the specified tag.
 func (ds *Dataset) Filter(tag string) *Dataset {
-	result := NewDataset(ds.headers)
-	result.title = ds.title
-	for k, v := range ds.dynamicCols {
-		result.dynamicCols[k] = v
+	return ds.filterTags(func(rowTags []string) bool {
+		return slices.Contains(rowTags, tag)
+	})
+}
+
+// FilterAll returns a new Dataset containing only rows with all of the
+// specified tags. Without tags, every row matches.
+func (ds *Dataset) FilterAll(tags ...string) *Dataset {
+	return ds.filterTags(func(rowTags []string) bool {
+		return !slices.ContainsFunc(tags, func(tag string) bool {
+			return !slices.Contains(rowTags, tag)
+		})
+	})
+}
+
+// FilterAny returns a new Dataset containing only rows with at least one
+// of the specified tags. Without tags, no row matches.
+func (ds *Dataset) FilterAny(tags ...string) *Dataset {
+	return ds.filterTags(func(rowTags []string) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool {
+			return slices.Contains(rowTags, tag)
+		})
+	})
+}
+
+// filterTags returns a new Dataset containing the rows whose tags match.
+func (ds *Dataset) filterTags(match func(rowTags []string) bool) *Dataset {
+	var rows []int
+	for i := range ds.data {
+		if match(ds.tags[i]) {
+			rows = append(rows, i)
+		}
 	}
+	return ds.selectRows(rows)
+}
 
+// TagsAt returns the tags of the row at the specified index.
+func (ds *Dataset) TagsAt(index int) ([]string, error) {
+	if index < 0 || index >= len(ds.data) {
+		return nil, ErrInvalidRowIndex
+	}
+	return slices.Clone(ds.tags[index]), nil
+}
+
+// AddTag adds a tag to the row at the specified index. Tags already on the
+// row are not added again.
+func (ds *Dataset) AddTag(index int, tag string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if index < 0 || index >= len(ds.data) {
+		return ErrInvalidRowIndex
+	}
+	if !slices.Contains(ds.tags[index], tag) {
+		ds.tags[index] = append(ds.tags[index], tag)
+	}
+	return nil
+}
+
+// RemoveTag removes a tag from the row at the specified index.
+func (ds *Dataset) RemoveTag(index int, tag string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if index < 0 || index >= len(ds.data) {
+		return ErrInvalidRowIndex
+	}
+	ds.tags[index] = slices.DeleteFunc(ds.tags[index], func(t string) bool {
+		return t == tag
+	})
+	return nil
+}
+
+// AllTags returns the distinct tags of all rows, sorted.
+func (ds *Dataset) AllTags() []string {
+	var tags []string
+	for _, rowTags := range ds.tags {
+		tags = append(tags, rowTags...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// Partition splits the rows in a single pass into a new Dataset of the
+// rows for which fn returns true and another of the rest, both keeping
+// their order, tags and the dataset's column settings.
+func (ds *Dataset) Partition(fn func(row []any) bool) (match, rest *Dataset) {
+	var matched, others []int
 	for i, row := range ds.data {
-		if slices.Contains(ds.tags[i], tag) {
-			r := make([]any, len(row))
-			copy(r, row)
-			result.data = append(result.data, r)
-			t := make([]string, len(ds.tags[i]))
-			copy(t, ds.tags[i])
-			result.tags = append(result.tags, t)
+		if fn(row) {
+			matched = append(matched, i)
+		} else {
+			others = append(others, i)
 		}
 	}
-	return result
+	return ds.selectRows(matched), ds.selectRows(others)
 }
 
-// Sort returns a new Dataset sorted by the specified column.
+// Sort returns a new Dataset sorted by the specified column. Rows with
+// equal values keep their relative order.
 func (ds *Dataset) Sort(colIndex int, reverse bool) (*Dataset, error) {
-	if colIndex < 0 || colIndex >= ds.Width() {
+	col, ok := ds.columnAt(colIndex)
+	if !ok {
 		return nil, ErrInvalidColumnIndex
 	}
+	return ds.sortRows(func(a, b []any) int {
+		c := compareAny(ds.cellValue(col, a), ds.cellValue(col, b))
+		if reverse {
+			return -c
+		}
+		return c
+	}), nil
+}
+
+// SortFunc returns a new Dataset sorted by the specified column using cmp,
+// which returns a negative number when a sorts before b, a positive number
+// when it sorts after and zero when they are equal. Rows with equal values
+// keep their relative order.
+func (ds *Dataset) SortFunc(colIndex int, cmp func(a, b any) int) (*Dataset, error) {
+	col, ok := ds.columnAt(colIndex)
+	if !ok {
+		return nil, ErrInvalidColumnIndex
+	}
+	return ds.sortRows(func(a, b []any) int {
+		return cmp(ds.cellValue(col, a), ds.cellValue(col, b))
+	}), nil
+}
 
+// SortKey is a column to sort by with SortBy.
+type SortKey struct {
+	Header     string
+	Descending bool
+}
+
+// SortBy returns a new Dataset sorted by each key in turn: rows that
+// compare equal on the first key are ordered by the second, and so on.
+// Rows equal on every key keep their relative order.
+func (ds *Dataset) SortBy(keys []SortKey) (*Dataset, error) {
+	cols := make([]layoutColumn, len(keys))
+	for i, k := range keys {
+		col, ok := ds.columnAt(ds.columnIndex(k.Header))
+		if !ok {
+			return nil, ErrColumnNotFound
+		}
+		cols[i] = col
+	}
+	return ds.sortRows(func(a, b []any) int {
+		for i, col := range cols {
+			c := compareAny(ds.cellValue(col, a), ds.cellValue(col, b))
+			if keys[i].Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}), nil
+}
+
+// sortRows returns a copy of the dataset with rows stably sorted by compare,
+// keeping each row's tags, separator and cell comments.
+func (ds *Dataset) sortRows(compare func(a, b []any) int) *Dataset {
 	result := ds.Copy()
 	indices := make([]int, len(result.data))
 	for i := range indices {
 		indices[i] = i
 	}
 
-	slices.SortFunc(indices, func(i, j int) int {
-		a, b := result.data[i][colIndex], result.data[j][colIndex]
-		c := compareAny(a, b)
-		if reverse {
-			return -c
-		}
-		return c
+	slices.SortStableFunc(indices, func(i, j int) int {
+		return compare(result.data[i], result.data[j])
 	})
 
 	newData := make([][]any, len(result.data))
@@ -413,52 +1186,98 @@ func (ds *Dataset) Sort(colIndex int, reverse bool) (*Dataset, error) {
 	}
 	result.data = newData
 	result.tags = newTags
-	return result, nil
+	result.comments = ds.selectComments(indices)
+	result.separators = ds.selectSeparators(indices)
+	return result
 }
 
 // SortByHeader returns a new Dataset sorted by the specified header.
 func (ds *Dataset) SortByHeader(header string, reverse bool) (*Dataset, error) {
-	index := ds.headerIndex(header)
+	index := ds.columnIndex(header)
 	if index == -1 {
 		return nil, ErrColumnNotFound
 	}
 	return ds.Sort(index, reverse)
 }
 
-// Transpose returns a new Dataset with rows and columns swapped.
+// TransposeHeaders selects the headers of a transposed Dataset.
+type TransposeHeaders int
+
+const (
+	// TransposeFirstColumn promotes the values of the first column to
+	// headers, consuming that column. Datasets without headers transpose
+	// with no headers.
+	TransposeFirstColumn TransposeHeaders = iota
+	// TransposePositional numbers the new columns "1", "2" and so on,
+	// keeping every column as data.
+	TransposePositional
+	// TransposeNoHeaders leaves the result without headers, keeping every
+	// column as data.
+	TransposeNoHeaders
+)
+
+// TransposeOptions configures TransposeWith.
+type TransposeOptions struct {
+	Headers TransposeHeaders
+
+	// KeepHeaders writes the original headers down the first column of
+	// the result, so no header is lost.
+	KeepHeaders bool
+}
+
+// Transpose returns a new Dataset with rows and columns swapped, promoting
+// the first column to headers. It is TransposeWith with the zero options.
 func (ds *Dataset) Transpose() *Dataset {
-	if len(ds.data) == 0 {
+	return ds.TransposeWith(TransposeOptions{})
+}
+
+// TransposeWith returns a new Dataset with rows and columns swapped, with
+// headers chosen by opts.
+func (ds *Dataset) TransposeWith(opts TransposeOptions) *Dataset {
+	height := len(ds.data)
+	keep := opts.KeepHeaders && len(ds.headers) > 0
+	if height == 0 && !keep {
 		return NewDataset(nil)
 	}
 
-	width := ds.Width()
-	height := ds.Height()
+	mode := opts.Headers
+	if mode == TransposeFirstColumn && len(ds.headers) == 0 {
+		mode = TransposeNoHeaders
+	}
 
 	var newHeaders []string
-	if len(ds.headers) > 0 {
-		// First column becomes headers
-		newHeaders = make([]string, height)
-		for i := 0; i < height; i++ {
-			if v, ok := ds.data[i][0].(string); ok {
-				newHeaders[i] = v
-			} else {
-				newHeaders[i] = ""
+	startCol := 0
+	switch mode {
+	case TransposeFirstColumn:
+		startCol = 1
+		if keep {
+			newHeaders = append(newHeaders, ds.headers[0])
+		}
+		for _, row := range ds.data {
+			h := ""
+			if row[0] != nil {
+				h = fmt.Sprintf("%v", row[0])
 			}
+			newHeaders = append(newHeaders, h)
+		}
+	case TransposePositional:
+		if keep {
+			newHeaders = append(newHeaders, "")
+		}
+		for i := range height {
+			newHeaders = append(newHeaders, strconv.Itoa(i+1))
 		}
 	}
 
 	result := NewDataset(newHeaders)
 	result.title = ds.title
-
-	startCol := 0
-	if len(ds.headers) > 0 {
-		startCol = 1
-	}
-
-	for col := startCol; col < width; col++ {
-		row := make([]any, height)
-		for r := 0; r < height; r++ {
-			row[r] = ds.data[r][col]
+	for col := startCol; col < ds.storedWidth(); col++ {
+		row := make([]any, 0, height+1)
+		if keep {
+			row = append(row, ds.headers[col])
+		}
+		for _, data := range ds.data {
+			row = append(row, data[col])
 		}
 		result.data = append(result.data, row)
 		result.tags = append(result.tags, []string{})
@@ -467,13 +1286,17 @@ func (ds *Dataset) Transpose() *Dataset {
 	return result
 }
 
-// StackRows stacks another dataset below this one.
+// StackRows stacks another dataset below this one. Separators move with
+// their rows as in Concat.
 func (ds *Dataset) StackRows(other *Dataset) (*Dataset, error) {
-	if ds.Width() != other.Width() {
+	if ds.storedWidth() != other.storedWidth() {
 		return nil, ErrInvalidDimensions
 	}
 
 	result := ds.Copy()
+	for i, sep := range other.separators {
+		result.separators[len(ds.data)+i] = sep
+	}
 	for i, row := range other.data {
 		r := make([]any, len(row))
 		copy(r, row)
@@ -485,7 +1308,89 @@ func (ds *Dataset) StackRows(other *Dataset) (*Dataset, error) {
 	return result, nil
 }
 
-// StackCols stacks another dataset to the right of this one.
+// Concat stacks datasets top to bottom into a new Dataset, copying every
+// row once. Columns are matched by header, so datasets may list the
+// headers of the first in any order; a different set of headers returns
+// ErrInvalidData. Datasets without headers, or concatenated with one
+// that has none, must match the first's width instead. Row tags are kept,
+// and separators move with their rows; when two land on the same row the
+// later dataset's wins. The result keeps the title, alignments, column
+// formats, formatters and dynamic columns of the first dataset.
+func Concat(datasets ...*Dataset) (*Dataset, error) {
+	if len(datasets) == 0 {
+		return NewDataset(nil), nil
+	}
+
+	first := datasets[0]
+	height := 0
+	orders := make([][]int, len(datasets)) // column of ds for each result column, nil when aligned
+	for n, ds := range datasets {
+		if ds.storedWidth() != first.storedWidth() && ds.Height() > 0 {
+			return nil, ErrInvalidDimensions
+		}
+		if len(ds.headers) > 0 && len(first.headers) > 0 && !slices.Equal(ds.headers, first.headers) {
+			order, ok := headerOrder(first.headers, ds.headers)
+			if !ok {
+				return nil, ErrInvalidData
+			}
+			orders[n] = order
+		}
+		height += len(ds.data)
+	}
+
+	result := NewDataset(first.headers)
+	result.title = first.title
+	result.dynamicCols = slices.Clone(first.dynamicCols)
+	maps.Copy(result.alignments, first.alignments)
+	maps.Copy(result.formats, first.formats)
+	maps.Copy(result.columnTags, first.columnTags)
+	result.formatters = append(result.formatters, first.formatters...)
+	result.data = make([][]any, 0, height)
+	result.tags = make([][]string, 0, height)
+	for n, ds := range datasets {
+		offset := len(result.data)
+		for i, sep := range ds.separators {
+			result.separators[offset+i] = sep
+		}
+		for i, row := range ds.data {
+			r := slices.Clone(row)
+			if order := orders[n]; order != nil {
+				for j, col := range order {
+					r[j] = row[col]
+				}
+			}
+			result.data = append(result.data, r)
+			result.tags = append(result.tags, slices.Clone(ds.tags[i]))
+		}
+	}
+	return result, nil
+}
+
+// headerOrder returns the index in headers of each header in want,
+// reporting false unless both hold the same headers.
+func headerOrder(want, headers []string) ([]int, bool) {
+	if len(want) != len(headers) {
+		return nil, false
+	}
+	order := make([]int, len(want))
+	used := make([]bool, len(headers))
+	for i, h := range want {
+		order[i] = -1
+		for j, other := range headers {
+			if other == h && !used[j] {
+				order[i], used[j] = j, true
+				break
+			}
+		}
+		if order[i] == -1 {
+			return nil, false
+		}
+	}
+	return order, true
+}
+
+// StackCols stacks another dataset to the right of this one, keeping the
+// separators of this one.
 func (ds *Dataset) StackCols(other *Dataset) (*Dataset, error) {
 	if ds.Height() != other.Height() {
 		return nil, ErrInvalidDimensions
@@ -494,12 +1399,13 @@ func (ds *Dataset) StackCols(other *Dataset) (*Dataset, error) {
 	result := ds.Copy()
 	result.headers = append(result.headers, other.headers...)
 	for i := range result.data {
-		result.data[i] = append(result.data[i], other.data[i]...)
+		result.data[i] = slices.Concat(result.data[i], other.data[i])
 	}
 	return result, nil
 }
 
-// Subset returns a new Dataset with only the specified columns.
+// Subset returns a new Dataset with only the specified columns, keeping
+// the row tags and separators.
 func (ds *Dataset) Subset(headers []string) (*Dataset, error) {
 	indices := make([]int, len(headers))
 	for i, h := range headers {
@@ -512,6 +1418,18 @@ func (ds *Dataset) Subset(headers []string) (*Dataset, error) {
 
 	result := NewDataset(headers)
 	result.title = ds.title
+	maps.Copy(result.separators, ds.separators)
+	for _, h := range headers {
+		if a, ok := ds.alignments[h]; ok {
+			result.alignments[h] = a
+		}
+		if f, ok := ds.formats[h]; ok {
+			result.formats[h] = f
+		}
+		if t, ok := ds.columnTags[h]; ok {
+			result.columnTags[h] = t
+		}
+	}
 	for i, row := range ds.data {
 		newRow := make([]any, len(indices))
 		for j, idx := range indices {
@@ -525,49 +1443,223 @@ func (ds *Dataset) Subset(headers []string) (*Dataset, error) {
 	return result, nil
 }
 
-// RemoveDuplicates returns a new Dataset with duplicate rows removed.
+// RemoveDuplicates returns a new Dataset with duplicate rows removed,
+// along with their separators and cell comments, as Filter selects rows.
 func (ds *Dataset) RemoveDuplicates() *Dataset {
-	result := NewDataset(ds.headers)
-	result.title = ds.title
-	for k, v := range ds.dynamicCols {
-		result.dynamicCols[k] = v
-	}
-
 	seen := make(map[string]bool)
+	var kept []int
 	for i, row := range ds.data {
 		key := rowKey(row)
 		if !seen[key] {
 			seen[key] = true
-			r := make([]any, len(row))
-			copy(r, row)
-			result.data = append(result.data, r)
-			t := make([]string, len(ds.tags[i]))
-			copy(t, ds.tags[i])
-			result.tags = append(result.tags, t)
+			kept = append(kept, i)
+		}
+	}
+	return ds.selectRows(kept)
+}
+
+// FillNA replaces every nil cell with value. nil is the null value: text
+// exports render it as an empty cell, JSON and YAML as null and SQL as NULL.
+// If value violates a constraint of a column with nil cells, FillNA
+// returns an error wrapping ErrConstraintViolation and leaves the dataset
+// unchanged.
+func (ds *Dataset) FillNA(value any) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if len(ds.constraints) > 0 {
+		var changes []cellChange
+		for i, row := range ds.data {
+			for j, v := range row {
+				if v == nil && len(ds.constraints[ds.headers[j]]) > 0 {
+					changes = append(changes, cellChange{row: i, col: j, value: value})
+				}
+			}
+		}
+		if err := ds.enforceConstraints(ds.changeViolations(changes)); err != nil {
+			return err
+		}
+	}
+	ds.record()
+	ds.ownRows()
+	for _, row := range ds.data {
+		for i, v := range row {
+			if v == nil {
+				row[i] = value
+			}
 		}
 	}
+	ds.dropIndexes()
+	return nil
+}
+
+// FillNAColumn replaces the nil cells of the column with the specified
+// header with value.
+func (ds *Dataset) FillNAColumn(header string, value any) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	idx := ds.headerIndex(header)
+	if idx == -1 {
+		return ErrColumnNotFound
+	}
+	if len(ds.constraints[header]) > 0 {
+		var changes []cellChange
+		for i, row := range ds.data {
+			if row[idx] == nil {
+				changes = append(changes, cellChange{row: i, col: idx, value: value})
+			}
+		}
+		if err := ds.enforceConstraints(ds.changeViolations(changes)); err != nil {
+			return err
+		}
+	}
+	ds.record()
+	ds.ownRows()
+	for _, row := range ds.data {
+		if row[idx] == nil {
+			row[idx] = value
+		}
+	}
+	ds.dropIndexes()
+	return nil
+}
+
+// DropNA returns a new Dataset without the rows that contain a nil cell
+// and their separators and cell comments, as Filter selects rows.
+func (ds *Dataset) DropNA() *Dataset {
+	var kept []int
+	for i, row := range ds.data {
+		if !slices.Contains(row, nil) {
+			kept = append(kept, i)
+		}
+	}
+	return ds.selectRows(kept)
+}
+
+// Head returns a new Dataset with the first n rows, or all rows if there
+// are fewer.
+func (ds *Dataset) Head(n int) *Dataset {
+	n = max(min(n, len(ds.data)), 0)
+	rows := make([]int, n)
+	for i := range rows {
+		rows[i] = i
+	}
+	return ds.selectRows(rows)
+}
+
+// Tail returns a new Dataset with the last n rows, or all rows if there
+// are fewer.
+func (ds *Dataset) Tail(n int) *Dataset {
+	n = max(min(n, len(ds.data)), 0)
+	rows := make([]int, n)
+	for i := range rows {
+		rows[i] = len(ds.data) - n + i
+	}
+	return ds.selectRows(rows)
+}
+
+// SliceRows returns a new Dataset holding rows start through end-1
+// without copying their cells: the rows are shared with ds, so cell
+// changes made with Set, MapColumn and the like on either dataset are
+// visible in both, and are not tracked by the other's index. Rows that ds
+// still shares with a copy (see Copy) are copied on write instead. Adding or
+// removing rows or columns on one does not affect the other. Use Copy for
+// an independent page.
+func (ds *Dataset) SliceRows(start, end int) (*Dataset, error) {
+	if start < 0 || end > len(ds.data) || start > end {
+		return nil, ErrInvalidRowIndex
+	}
+	result := NewDataset(ds.headers)
+	result.title = ds.title
+	result.dynamicCols = slices.Clone(ds.dynamicCols)
+	maps.Copy(result.alignments, ds.alignments)
+	maps.Copy(result.formats, ds.formats)
+	maps.Copy(result.columnTags, ds.columnTags)
+	result.data = slices.Clone(ds.data[start:end])
+	// Rows shared with a copy stay copy-on-write in the slice too
+	result.sharing.shared.Store(ds.rowsShared())
+	ds.viewRows(result)
+	result.tags = make([][]string, end-start)
+	rows := make([]int, end-start)
+	for i, t := range ds.tags[start:end] {
+		result.tags[i] = append([]string{}, t...)
+		rows[i] = start + i
+	}
+	result.separators = ds.selectSeparators(rows)
+	result.comments = ds.selectComments(rows)
+	return result, nil
+}
+
+// Sample returns a new Dataset with n rows chosen at random without
+// replacement, kept in their original order. The same seed always selects
+// the same rows. If n is at least the height, every row is returned.
+func (ds *Dataset) Sample(n int, seed int64) *Dataset {
+	n = max(min(n, len(ds.data)), 0)
+	r := rand.New(rand.NewPCG(uint64(seed), 0))
+	rows := r.Perm(len(ds.data))[:n]
+	slices.Sort(rows)
+	return ds.selectRows(rows)
+}
+
+// selectRows returns a new Dataset with the specified rows, shared copy-on-
+// write, copies of their tags and their separators and cell comments,
+// keeping the title and column settings.
+func (ds *Dataset) selectRows(rows []int) *Dataset {
+	result := NewDataset(ds.headers)
+	result.title = ds.title
+	result.dynamicCols = slices.Clone(ds.dynamicCols)
+	maps.Copy(result.alignments, ds.alignments)
+	maps.Copy(result.formats, ds.formats)
+	maps.Copy(result.columnTags, ds.columnTags)
+	result.comments = ds.selectComments(rows)
+	result.separators = ds.selectSeparators(rows)
+
+	result.data = make([][]any, len(rows))
+	result.tags = make([][]string, len(rows))
+	for i, idx := range rows {
+		result.data[i] = ds.data[idx]
+		result.tags[i] = append([]string{}, ds.tags[idx]...)
+	}
+	ds.shareRows(result)
 	return result
 }
 
-// Copy returns a deep copy of the dataset.
+// Copy returns an independent copy of the dataset. The rows are copied on
+// write: both datasets share them until either one changes a cell, so
+// copying a large dataset is cheap. Rows that a SliceRows view shares with
+// ds are copied right away instead. Cell values themselves, such as
+// slices, are not copied.
 func (ds *Dataset) Copy() *Dataset {
 	result := NewDataset(ds.headers)
 	result.title = ds.title
-	for k, v := range ds.dynamicCols {
-		result.dynamicCols[k] = v
+	result.dynamicCols = slices.Clone(ds.dynamicCols)
+	for k, v := range ds.alignments {
+		result.alignments[k] = v
 	}
+	for k, v := range ds.formats {
+		result.formats[k] = v
+	}
+	for k, v := range ds.columnTags {
+		result.columnTags[k] = v
+	}
+	for k, v := range ds.constraints {
+		if result.constraints == nil {
+			result.constraints = make(map[string][]Constraint)
+		}
+		result.constraints[k] = slices.Clone(v)
+	}
+	result.collectViolations = ds.collectViolations
+	result.comments = ds.cloneComments()
 	result.formatters = append(result.formatters, ds.formatters...)
 	for k, v := range ds.separators {
 		result.separators[k] = v
 	}
-	for i, row := range ds.data {
-		r := make([]any, len(row))
-		copy(r, row)
-		result.data = append(result.data, r)
-		t := make([]string, len(ds.tags[i]))
-		copy(t, ds.tags[i])
-		result.tags = append(result.tags, t)
+	result.data = slices.Clone(ds.data)
+	for _, t := range ds.tags {
+		result.tags = append(result.tags, append([]string{}, t...))
 	}
+	ds.shareRows(result)
 	return result
 }
 
@@ -579,38 +1671,57 @@ func (ds *Dataset) Dict() ([]map[string]any, error) {
 
 	result := make([]map[string]any, len(ds.data))
 	for i, row := range ds.data {
-		m := make(map[string]any)
-		for j, h := range ds.headers {
-			m[h] = row[j]
-		}
-		// Add dynamic columns
-		for h, fn := range ds.dynamicCols {
-			m[h] = fn(row)
-		}
-		result[i] = m
+		result[i] = ds.rowDict(row)
 	}
 	return result, nil
 }
 
-// Records returns all rows as a slice of slices.
+// RowDict returns the row at the specified index as a map keyed by
+// header, including dynamic columns, as one entry of Dict.
+func (ds *Dataset) RowDict(index int) (map[string]any, error) {
+	if len(ds.headers) == 0 {
+		return nil, ErrHeadersRequired
+	}
+	if index < 0 || index >= len(ds.data) {
+		return nil, ErrInvalidRowIndex
+	}
+	return ds.rowDict(ds.data[index]), nil
+}
+
+// rowDict maps the headers and dynamic columns to the values of row.
+func (ds *Dataset) rowDict(row []any) map[string]any {
+	m := make(map[string]any, len(ds.headers)+len(ds.dynamicCols))
+	for j, h := range ds.headers {
+		m[h] = row[j]
+	}
+	// Add dynamic columns
+	for _, dc := range ds.dynamicCols {
+		m[dc.header] = dc.fn(row)
+	}
+	return m
+}
+
+// Records returns all rows as a slice of slices, with the values of
+// dynamic columns in their positions.
 func (ds *Dataset) Records() [][]any {
+	layout := ds.columnLayout()
 	result := make([][]any, len(ds.data))
 	for i, row := range ds.data {
-		r := make([]any, len(row))
-		copy(r, row)
-		// Add dynamic columns
-		for _, fn := range ds.dynamicCols {
-			r = append(r, fn(row))
-		}
-		result[i] = r
+		result[i] = ds.effectiveRow(layout, row)
 	}
 	return result
 }
 
 // Wipe clears all data from the dataset.
 func (ds *Dataset) Wipe() {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	ds.record()
 	ds.data = make([][]any, 0)
 	ds.tags = make([][]string, 0)
+	ds.dropIndexes()
+	ds.comments = nil
 }
 
 // headerIndex returns the index of the header, or -1 if not found.
@@ -655,3 +1766,49 @@ func compareAny(a, b any) int {
 	// Fallback to string comparison
 	return cmp.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
 }
+
+// valueString returns the display text of a cell value. nil is the null
+// value and renders as an empty string, and times render in RFC 3339.
+func valueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// numericValue converts numbers and numeric strings to float64.
+func numericValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -1,9 +1,22 @@
 package tablib
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
 )
 
 func TestNewDataset(t *testing.T) {
@@ -765,3 +778,3805 @@ func TestCopyWithFormattersAndSeparators(t *testing.T) {
 	}
 }
 
+func TestODSFormulaRoundTrip(t *testing.T) {
+	ds := NewDataset([]string{"Item", "Total"})
+	ds.Append([]any{"a", 1})
+	ds.Append([]any{"sum", Formula{Expression: "=SUM([.B2:.B2])", Value: 1}})
+
+	var buf bytes.Buffer
+	if err := ds.Export(FormatODS, &buf); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+
+	imported, err := ImportODS(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "")
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+
+	v, _ := imported.Get(1, 1)
+	f, ok := v.(Formula)
+	if !ok {
+		t.Fatalf("expected Formula, got %T", v)
+	}
+	if f.Expression != "=SUM([.B2:.B2])" {
+		t.Errorf("unexpected expression: %s", f.Expression)
+	}
+	if f.Value != "1" {
+		t.Errorf("expected cached value 1, got %v", f.Value)
+	}
+}
+
+func TestImportXLSDatabook(t *testing.T) {
+	db := NewDatabook()
+	users := NewDataset([]string{"Name"})
+	users.SetTitle("Users")
+	users.Append([]any{"Alice"})
+	products := NewDataset([]string{"Product", "Price"})
+	products.SetTitle("Products")
+	products.Append([]any{"Laptop", 999})
+	db.AddSheet(users)
+	db.AddSheet(products)
+
+	var buf bytes.Buffer
+	if err := db.Export(FormatXLS, &buf); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+
+	imported, err := ImportXLSDatabook(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if imported.Size() != 2 {
+		t.Fatalf("expected 2 sheets, got %d", imported.Size())
+	}
+
+	sheet, err := imported.SheetByTitle("Products")
+	if err != nil {
+		t.Fatalf("expected Products sheet: %v", err)
+	}
+	v, _ := sheet.Get(0, 1)
+	if v != "999" {
+		t.Errorf("expected 999, got %v", v)
+	}
+
+	ds, err := Import(FormatXLS, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("registry import error: %v", err)
+	}
+	if ds.Title() != "Users" {
+		t.Errorf("expected first sheet Users, got %q", ds.Title())
+	}
+}
+
+func TestExportXLSStyling(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Notes", "Score"})
+	ds.Append([]any{"Alice", strings.Repeat("long text ", 10), 9.5})
+
+	output, err := ds.ExportString(FormatXLS)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{`<Column ss:Width=`, `ss:WrapText="1"`, `ss:StyleID="Wrap"`, `ss:StyleID="Decimal"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %s in output:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, "<Column ") != 3 {
+		t.Errorf("expected 3 column definitions, got:\n%s", output)
+	}
+}
+
+func TestDBFMemoFields(t *testing.T) {
+	long := strings.Repeat("memo text ", 60)
+	ds := NewDataset([]string{"Name", "Notes"})
+	ds.Append([]any{"Alice", long})
+	ds.Append([]any{"Bob", ""})
+
+	var dbf, memo bytes.Buffer
+	if err := ds.ExportDBF(&dbf, DBFOptions{Memo: &memo}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if dbf.Bytes()[0] != 0x83 {
+		t.Errorf("expected memo version byte 0x83, got 0x%02x", dbf.Bytes()[0])
+	}
+	if memo.Len()%512 != 0 || memo.Len() < 1024 {
+		t.Errorf("unexpected memo size %d", memo.Len())
+	}
+
+	imported, err := ImportDBF(bytes.NewReader(dbf.Bytes()), DBFImportOptions{Memo: bytes.NewReader(memo.Bytes())})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	v, _ := imported.Get(0, 1)
+	if v != long {
+		t.Errorf("expected memo text to round-trip, got %d chars", len(v.(string)))
+	}
+	v, _ = imported.Get(1, 1)
+	if v != "" {
+		t.Errorf("expected empty memo, got %q", v)
+	}
+}
+
+func TestDBFCodePage(t *testing.T) {
+	ds := NewDataset([]string{"Name", "City"})
+	ds.Append([]any{"Иван", "Москва"})
+	ds.Append([]any{"José", "São Paulo"})
+
+	var buf bytes.Buffer
+	if err := ds.ExportDBF(&buf, DBFOptions{CodePage: DBFCodePageCP1251}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if buf.Bytes()[29] != byte(DBFCodePageCP1251) {
+		t.Errorf("expected language driver 0x%02x, got 0x%02x", DBFCodePageCP1251, buf.Bytes()[29])
+	}
+
+	imported, err := Import(FormatDBF, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	v, _ := imported.Get(0, 1)
+	if v != "Москва" {
+		t.Errorf("expected Москва, got %q", v)
+	}
+
+	buf.Reset()
+	if err := ds.ExportDBF(&buf, DBFOptions{CodePage: DBFCodePageGBK}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	imported, err = ImportDBF(bytes.NewReader(buf.Bytes()), DBFImportOptions{})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	v, _ = imported.Get(0, 0)
+	if v != "Иван" {
+		t.Errorf("expected Иван, got %q", v)
+	}
+
+	ds = NewDataset([]string{"Имя", "Наименование"})
+	ds.Append([]any{"Иван", "Москва"})
+	// Cyrillic takes one byte in CP1251 and two in GBK
+	for cp, expected := range map[DBFCodePage]string{
+		DBFCodePageCP1251: "[ИМЯ НАИМЕНОВАН]",
+		DBFCodePageGBK:    "[ИМЯ НАИМЕ]",
+	} {
+		buf.Reset()
+		if err := ds.ExportDBF(&buf, DBFOptions{CodePage: cp}); err != nil {
+			t.Fatalf("export error: %v", err)
+		}
+		imported, err = ImportDBF(bytes.NewReader(buf.Bytes()), DBFImportOptions{})
+		if err != nil {
+			t.Fatalf("import error: %v", err)
+		}
+		if h := fmt.Sprint(imported.Headers()); h != expected {
+			t.Errorf("expected headers %s for 0x%02x, got %s", expected, cp, h)
+		}
+	}
+}
+
+func TestDBFWriterAppend(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "*.dbf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dw, err := NewDBFWriter(f, []DBFField{{Name: "Name", Length: 10}, {Name: "Age", Length: 3}}, DBFOptions{})
+	if err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+	if err := dw.Append([]any{"Alice", 30}); err != nil {
+		t.Fatalf("append error: %v", err)
+	}
+
+	// Reopen and keep appending
+	dw, err = OpenDBFWriter(f, DBFOptions{})
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	if err := dw.Append([]any{"Bob", 25}); err != nil {
+		t.Fatalf("append error: %v", err)
+	}
+	if dw.Count() != 2 {
+		t.Errorf("expected count 2, got %d", dw.Count())
+	}
+
+	f.Seek(0, io.SeekStart)
+	imported, err := Import(FormatDBF, f)
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if imported.Height() != 2 {
+		t.Fatalf("expected height 2, got %d", imported.Height())
+	}
+	row, _ := imported.Row(1)
+	if row[0] != "Bob" || row[1] != "25" {
+		t.Errorf("unexpected row: %v", row)
+	}
+}
+
+func TestDBFWriterAppendDates(t *testing.T) {
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	ds := NewDataset([]string{"Name", "Due"})
+	ds.Append([]any{"Alice", day})
+
+	f, err := os.CreateTemp(t.TempDir(), "*.dbf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := ds.ExportDBF(f, DBFOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+
+	dw, err := OpenDBFWriter(f, DBFOptions{})
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	if err := dw.Append([]any{"Bob", day.AddDate(0, 0, 1)}); err != nil {
+		t.Fatalf("append error: %v", err)
+	}
+	if err := dw.Append([]any{"Cid", nil}); err != nil {
+		t.Fatalf("append error: %v", err)
+	}
+
+	f.Seek(0, io.SeekStart)
+	imported, err := ImportDBF(f, DBFImportOptions{Typed: true})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	col, _ := imported.Column(1)
+	if len(col) != 3 || col[0] != day || col[1] != day.AddDate(0, 0, 1) || col[2] != nil {
+		t.Errorf("expected appended dates to be stored as dates, got %v", col)
+	}
+}
+
+func TestImportDBFTyped(t *testing.T) {
+	fields := []dbfFieldDescriptor{
+		{Type: dbfFieldTypeNumber, Length: 5},
+		{Type: dbfFieldTypeNumber, Length: 6, DecimalCount: 2},
+		{Type: dbfFieldTypeLogical, Length: 1},
+		{Type: dbfFieldTypeDate, Length: 8},
+	}
+	for i, name := range []string{"QTY", "PRICE", "PAID", "DUE"} {
+		copy(fields[i].Name[:], name)
+	}
+
+	var buf bytes.Buffer
+	header := dbfHeader{Version: dbfVersion, RecordCount: 2, HeaderSize: 32 + 32*4 + 1, RecordSize: 1 + 5 + 6 + 1 + 8}
+	binary.Write(&buf, binary.LittleEndian, &header)
+	for _, fd := range fields {
+		binary.Write(&buf, binary.LittleEndian, &fd)
+	}
+	buf.WriteByte(dbfHeaderTerminator)
+	buf.WriteString("    42 12.50T20240131")
+	buf.WriteString("          ?        ")
+	buf.WriteByte(dbfEOF)
+
+	ds, err := ImportDBF(bytes.NewReader(buf.Bytes()), DBFImportOptions{Typed: true})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+
+	row, _ := ds.Row(0)
+	if row[0] != int64(42) || row[1] != 12.5 || row[2] != true {
+		t.Errorf("unexpected typed values: %#v", row)
+	}
+	if d, ok := row[3].(time.Time); !ok || !d.Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected date 2024-01-31, got %v", row[3])
+	}
+
+	row, _ = ds.Row(1)
+	for i, v := range row {
+		if v != nil {
+			t.Errorf("expected nil for blank field %d, got %#v", i, v)
+		}
+	}
+
+	untyped, _ := Import(FormatDBF, bytes.NewReader(buf.Bytes()))
+	if v, _ := untyped.Get(0, 0); v != "42" {
+		t.Errorf("expected string 42 without Typed, got %#v", v)
+	}
+}
+
+func TestDBFVersions(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	ds := NewDataset([]string{"Name", "Notes"})
+	ds.Append([]any{"Alice", long})
+
+	tests := []struct {
+		version DBFVersion
+		want    byte
+	}{
+		{DBFVersionDBaseIII, 0x83},
+		{DBFVersionDBaseIV, 0x8B},
+		{DBFVersionVisualFoxPro, 0x30},
+	}
+	for _, tt := range tests {
+		var dbf, memo bytes.Buffer
+		if err := ds.ExportDBF(&dbf, DBFOptions{Memo: &memo, Version: tt.version}); err != nil {
+			t.Fatalf("export error: %v", err)
+		}
+		if dbf.Bytes()[0] != tt.want {
+			t.Errorf("version %d: expected byte 0x%02x, got 0x%02x", tt.version, tt.want, dbf.Bytes()[0])
+		}
+
+		imported, err := ImportDBF(bytes.NewReader(dbf.Bytes()), DBFImportOptions{Memo: bytes.NewReader(memo.Bytes())})
+		if err != nil {
+			t.Fatalf("version %d: import error: %v", tt.version, err)
+		}
+		row, _ := imported.Row(0)
+		if row[0] != "Alice" || row[1] != long {
+			t.Errorf("version %d: memo did not round-trip: %q", tt.version, row[0])
+		}
+	}
+}
+
+func TestDBFVisualFoxProFieldFlags(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Notes", "Age"})
+	ds.Append([]any{"Alice", strings.Repeat("x", 300), 30})
+	ds.Append([]any{"Bob", nil, nil})
+
+	var dbf, memo bytes.Buffer
+	if err := ds.ExportDBF(&dbf, DBFOptions{Memo: &memo, Version: DBFVersionVisualFoxPro}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	data := dbf.Bytes()
+	fields, err := parseDBFFields(data, dbfHeader{HeaderSize: binary.LittleEndian.Uint16(data[8:])})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var flags []string
+	for _, f := range fields {
+		name, _, _ := strings.Cut(string(f.Name[:]), "\x00")
+		flags = append(flags, fmt.Sprintf("%s:%c:%02x", name, f.Type, data[32*(len(flags)+1)+18]))
+	}
+	expected := "[NAME:C:00 NOTES:M:06 AGE:C:02 _NullFlags:0:05]"
+	if fmt.Sprint(flags) != expected {
+		t.Errorf("expected field flags %s, got %v", expected, flags)
+	}
+
+	imported, err := ImportDBF(bytes.NewReader(data), DBFImportOptions{Memo: bytes.NewReader(memo.Bytes())})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if h := fmt.Sprint(imported.Headers()); h != "[NAME NOTES AGE]" {
+		t.Errorf("expected the system field to be hidden, got %s", h)
+	}
+	if row, _ := imported.Row(1); row[0] != "Bob" || row[1] != nil || row[2] != nil {
+		t.Errorf("expected nulls to round-trip, got %v", row)
+	}
+	if row, _ := imported.Row(0); row[2] != "30" {
+		t.Errorf("expected non-null values to be kept, got %v", row)
+	}
+
+	// Appending keeps the null flags of an existing table
+	f, err := os.CreateTemp(t.TempDir(), "*.dbf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ds.DeleteColByHeader("Notes")
+	if err := ds.ExportDBF(f, DBFOptions{Version: DBFVersionVisualFoxPro}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	dw, err := OpenDBFWriter(f, DBFOptions{})
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	if err := dw.Append([]any{"Cid", nil, 40}); err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+	if err := dw.Append([]any{"Cid", nil}); err != nil {
+		t.Fatalf("append error: %v", err)
+	}
+	f.Seek(0, io.SeekStart)
+	imported, err = ImportDBF(f, DBFImportOptions{})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if col, _ := imported.Column(1); fmt.Sprint(col) != "[30 <nil> <nil>]" {
+		t.Errorf("expected appended nulls to be flagged, got %v", col)
+	}
+}
+
+func TestExportODSMeta(t *testing.T) {
+	db := NewDatabook()
+	db.SetTitle("Quarterly Report")
+	ds := NewDataset([]string{"Name"})
+	ds.Append([]any{"Alice"})
+	db.AddSheet(ds)
+
+	var buf bytes.Buffer
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	if err := db.ExportODS(&buf, ODSOptions{Author: "Finance", Created: created}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip error: %v", err)
+	}
+	var meta string
+	for _, f := range zr.File {
+		if f.Name == "meta.xml" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			meta = string(data)
+		}
+	}
+
+	for _, want := range []string{
+		"<dc:title>Quarterly Report</dc:title>",
+		"<dc:creator>Finance</dc:creator>",
+		"<meta:creation-date>2024-03-01T09:30:00</meta:creation-date>",
+		"<meta:generator>tablib-go</meta:generator>",
+	} {
+		if !strings.Contains(meta, want) {
+			t.Errorf("expected %s in meta.xml:\n%s", want, meta)
+		}
+	}
+}
+
+func TestExportHTMLFullDocument(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	ds.SetTitle("Users & Roles")
+	ds.Append([]any{"Alice"})
+
+	var buf bytes.Buffer
+	opts := HTMLOptions{
+		FullDocument: true,
+		Stylesheets:  []string{"style.css"},
+		CSS:          "td { color: red; }",
+		DefaultStyle: true,
+	}
+	if err := ds.ExportHTML(&buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Users &amp; Roles</title>",
+		`<link rel="stylesheet" href="style.css">`,
+		"border-collapse",
+		"td { color: red; }",
+		"<caption>Users &amp; Roles</caption>",
+		"</body>\n</html>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestExportHTMLCaptionAndFooter(t *testing.T) {
+	ds := NewDataset([]string{"Item", "Amount"})
+	ds.SetTitle("Expenses")
+	ds.Append([]any{"Rent", 1000})
+	ds.Append([]any{"Food", 300})
+
+	var buf bytes.Buffer
+	opts := HTMLOptions{Caption: true, Footer: [][]any{{"Total", 1300}}}
+	if err := ds.ExportHTML(&buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<caption>Expenses</caption>") {
+		t.Errorf("expected caption, got:\n%s", output)
+	}
+	if !strings.Contains(output, "<tfoot>\n    <tr>\n      <td>Total</td>\n      <td>1300</td>") {
+		t.Errorf("expected tfoot row, got:\n%s", output)
+	}
+
+	plain, _ := ds.ExportString(FormatHTML)
+	if strings.Contains(plain, "<caption>") || strings.Contains(plain, "<tfoot>") {
+		t.Errorf("expected no caption or footer by default, got:\n%s", plain)
+	}
+}
+
+func TestExportHTMLCellAttributes(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Score"})
+	ds.AppendTagged([]any{"Alice", 90}, []string{"top", "active"})
+	ds.Append([]any{"Bob", nil})
+
+	var buf bytes.Buffer
+	opts := HTMLOptions{
+		ColumnClasses:  map[string]string{"Name": "name"},
+		TypeAttributes: true,
+		TagAttributes:  true,
+		CellClass: func(row, col int, v any) string {
+			if v == nil {
+				return "missing"
+			}
+			return ""
+		},
+	}
+	if err := ds.ExportHTML(&buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		`<th class="name">Name</th>`,
+		`<tr data-tags="top active">`,
+		`<td class="name" data-type="string">Alice</td>`,
+		`<td class="num" data-type="number">90</td>`,
+		`<td class="missing" data-type="null">`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestExportHTMLNestedValues(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Skills", "Address"})
+	ds.Append([]any{"Alice", []any{"Go", "<SQL>"}, map[string]any{"city": "Paris", "zip": 75001}})
+
+	var buf bytes.Buffer
+	if err := ds.ExportHTML(&buf, HTMLOptions{NestedValues: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<ul><li>Go</li><li>&lt;SQL&gt;</li></ul>") {
+		t.Errorf("expected nested list, got:\n%s", output)
+	}
+	if !strings.Contains(output, "<table><tr><th>city</th><td>Paris</td></tr><tr><th>zip</th><td>75001</td></tr></table>") {
+		t.Errorf("expected nested table, got:\n%s", output)
+	}
+}
+
+func TestExportHTMLRawCells(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Link", "Status"})
+	ds.Append([]any{"<b>Alice</b>", `<a href="/u/1">profile</a>`, HTML(`<span class="ok">active</span>`)})
+
+	var buf bytes.Buffer
+	if err := ds.ExportHTML(&buf, HTMLOptions{RawColumns: []string{"Link"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"<td>&lt;b&gt;Alice&lt;/b&gt;</td>",
+		`<td><a href="/u/1">profile</a></td>`,
+		`<td><span class="ok">active</span></td>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestExportMarkdownAlignmentAndEscaping(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Score", "Note"})
+	ds.Append([]any{"Alice", 90, "a|b\nc"})
+	ds.SetAlignment("Score", AlignRight)
+	ds.SetAlignment("Note", AlignCenter)
+
+	output, err := ds.ExportString(FormatMarkdown)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(output, "\n")
+	if lines[1] != "| ----- | ----: | :-------: |" {
+		t.Errorf("unexpected separator line: %q", lines[1])
+	}
+	if lines[2] != "| Alice |    90 | a\\|b<br>c |" {
+		t.Errorf("unexpected data line: %q", lines[2])
+	}
+
+	if err := ds.SetAlignment("Missing", AlignLeft); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestExportMarkdownCompact(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alexander", 30})
+	ds.SetAlignment("Age", AlignRight)
+
+	var buf bytes.Buffer
+	if err := ds.ExportMarkdown(&buf, MarkdownOptions{Compact: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "| Name | Age |\n| --- | --: |\n| Alexander | 30 |\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportMarkdownSeparators(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Team"})
+	ds.Append([]any{"Alice", "Eng"})
+	ds.Append([]any{"Bob", "Sales"})
+	ds.InsertSeparator(1, "Sales")
+
+	output, err := ds.ExportString(FormatMarkdown)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "| **Sales** |       |\n| Bob       | Sales |") {
+		t.Errorf("expected separator row, got:\n%s", output)
+	}
+
+	var buf bytes.Buffer
+	if err := ds.ExportMarkdown(&buf, MarkdownOptions{Separators: MarkdownSeparatorHeading}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "| Name  | Team  |\n| ----- | ----- |\n| Alice | Eng   |\n\n### Sales\n\n| Name  | Team  |\n| ----- | ----- |\n| Bob   | Sales |\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportDisplayWidth(t *testing.T) {
+	ds := NewDataset([]string{"Name", "City"})
+	ds.Append([]any{"张伟", "北京"})
+	ds.Append([]any{"Alice", "NYC"})
+
+	output, err := ds.ExportString(FormatCLI)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "│ 张伟  │ 北京 │\n│ Alice │ NYC  │") {
+		t.Errorf("expected CJK cells aligned by display width, got:\n%s", output)
+	}
+
+	output, _ = ds.ExportString(FormatMarkdown)
+	if !strings.Contains(output, "| 张伟  | 北京 |") {
+		t.Errorf("expected CJK cells aligned in Markdown, got:\n%s", output)
+	}
+
+	output, _ = ds.ExportString(FormatRST)
+	if !strings.Contains(output, "| 张伟  | 北京 |") {
+		t.Errorf("expected CJK cells aligned in RST, got:\n%s", output)
+	}
+
+	ambiguous := NewDataset([]string{"X"})
+	ambiguous.Append([]any{"±1"})
+	var buf bytes.Buffer
+	ambiguous.ExportCLI(&buf, CLIOptions{BorderStyle: "ascii", EastAsianWidth: true})
+	if !strings.Contains(buf.String(), "+-----+") {
+		t.Errorf("expected ambiguous character counted as wide, got:\n%s", buf.String())
+	}
+}
+
+func TestExportCLIWidthLimits(t *testing.T) {
+	ds := NewDataset([]string{"ID", "Description"})
+	ds.Append([]any{1, "the quick brown fox jumps"})
+
+	var buf bytes.Buffer
+	if err := ds.ExportCLI(&buf, CLIOptions{BorderStyle: "ascii", MaxColumnWidth: 10}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| 1  | the quick… |") {
+		t.Errorf("expected truncated cell, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ds.ExportCLI(&buf, CLIOptions{BorderStyle: "ascii", MaxTableWidth: 20, WordWrap: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if len(line) > 20 {
+			t.Errorf("line exceeds table width: %q", line)
+		}
+	}
+	if !strings.Contains(buf.String(), "| 1  | the quick   |\n|    | brown fox   |\n|    | jumps       |") {
+		t.Errorf("expected wrapped cell, got:\n%s", buf.String())
+	}
+
+	wide := NewDataset([]string{"A"})
+	wide.Append([]any{"日本"})
+	buf.Reset()
+	if err := wide.ExportCLI(&buf, CLIOptions{BorderStyle: "ascii", MaxColumnWidth: 1, WordWrap: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| … |\n| … |") {
+		t.Errorf("expected runes wider than the column as ellipses, got:\n%s", buf.String())
+	}
+}
+
+func TestExportCLITitleAndFooter(t *testing.T) {
+	ds := NewDataset([]string{"Item", "Cost"})
+	ds.SetTitle("Budget")
+	ds.Append([]any{"Rent", 1000})
+	ds.Append([]any{"Food", 300})
+
+	var buf bytes.Buffer
+	opts := CLIOptions{BorderStyle: "ascii", ShowTitle: true, Footer: []any{"Total", 1300}}
+	if err := ds.ExportCLI(&buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `     Budget
++-------+------+
+| Item  | Cost |
++-------+------+
+| Rent  | 1000 |
+| Food  | 300  |
++-------+------+
+| Total | 1300 |
++-------+------+
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	opts.Footer = []any{"Total"}
+	if err := ds.ExportCLI(&buf, opts); err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+}
+
+func TestExportCLIRowNumbers(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	for _, name := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"} {
+		ds.Append([]any{name})
+	}
+
+	var buf bytes.Buffer
+	if err := ds.ExportCLI(&buf, CLIOptions{BorderStyle: "ascii", ShowRowNumbers: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "|  # | Name |") || !strings.Contains(output, "|  1 | A    |") || !strings.Contains(output, "| 10 | J    |") {
+		t.Errorf("expected row number column, got:\n%s", output)
+	}
+
+	buf.Reset()
+	small := NewDataset([]string{"Name"})
+	small.Append([]any{"X"})
+	if err := small.ExportCLI(&buf, CLIOptions{BorderStyle: "ascii", ShowRowNumbers: true, RowNumbers: []int{42}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| 42 | X    |") {
+		t.Errorf("expected explicit row number, got:\n%s", buf.String())
+	}
+}
+
+func TestExportCLICustomBorders(t *testing.T) {
+	ds := NewDataset([]string{"A"})
+	ds.Append([]any{"x"})
+
+	dotted := BorderChars{
+		TopLeft: ".", TopRight: ".", BottomLeft: "'", BottomRight: "'",
+		Horizontal: ".", Vertical: ":", Cross: ":", TopT: ".", BottomT: "'", LeftT: ":", RightT: ":",
+	}
+	var buf bytes.Buffer
+	if err := ds.ExportCLI(&buf, CLIOptions{Border: &dotted}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := ".....\n: A :\n:...:\n: x :\n'...'\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	RegisterBorderStyle("dotted", dotted)
+	buf.Reset()
+	if err := ds.ExportCLI(&buf, CLIOptions{BorderStyle: "dotted"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected registered style output, got:\n%s", buf.String())
+	}
+}
+
+func TestCLIWriterStreaming(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Qty"})
+	ds.Append([]any{"Apple", 3})
+	ds.Append([]any{"Banana", 12})
+
+	var expected bytes.Buffer
+	if err := ds.ExportCLI(&expected, CLIOptions{BorderStyle: "ascii"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cw, err := NewCLIWriter(&buf, []string{"Name", "Qty"}, CLIOptions{BorderStyle: "ascii"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cw.Write([]any{"Apple", 3})
+	cw.Write([]any{"Banana", 12})
+	if buf.Len() != 0 {
+		t.Errorf("expected rows to be buffered while sampling, got:\n%s", buf.String())
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected.String() {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected.String(), buf.String())
+	}
+
+	// Fixed widths write each row immediately
+	buf.Reset()
+	cw, _ = NewCLIWriter(&buf, []string{"Name"}, CLIOptions{BorderStyle: "ascii", ColumnWidths: []int{4}})
+	cw.Write([]any{"Cherry"})
+	if !strings.Contains(buf.String(), "| Che… |") {
+		t.Errorf("expected row to be written and truncated, got:\n%s", buf.String())
+	}
+	if err := cw.Write([]any{"a", "b"}); err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+	if cw.Count() != 1 {
+		t.Errorf("expected count 1, got %d", cw.Count())
+	}
+}
+
+func TestPager(t *testing.T) {
+	if _, err := os.Stat("/dev/stdin"); err != nil {
+		t.Skip("no /dev/stdin")
+	}
+	out := filepath.Join(t.TempDir(), "paged.txt")
+	t.Setenv("PAGER", "cp /dev/stdin "+out)
+
+	p, err := NewPager()
+	if err != nil {
+		t.Skipf("cannot start a pager: %v", err)
+	}
+	cw, _ := NewCLIWriter(p, []string{"Name"}, CLIOptions{BorderStyle: "ascii"})
+	cw.Write([]any{"Apple"})
+	if err := cw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paged, _ := os.ReadFile(out)
+	if !strings.Contains(string(paged), "| Apple |") {
+		t.Errorf("expected the table to reach the pager, got:\n%s", paged)
+	}
+
+	t.Setenv("PAGER", "tablib-no-such-pager")
+	if _, err := NewPager(); err == nil {
+		t.Error("expected an error for a missing pager")
+	}
+}
+
+func TestExportLatexBooktabs(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+
+	var buf bytes.Buffer
+	if err := ds.ExportLatex(&buf, LatexOptions{Booktabs: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "\\begin{tabular}{ll}\n\\toprule\nName & Age \\\\\n\\midrule\nAlice & 30 \\\\\n\\bottomrule\n\\end{tabular}"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportLatexLongtable(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	ds.SetTitle("People")
+	ds.Append([]any{"Alice"})
+
+	var buf bytes.Buffer
+	if err := ds.ExportLatex(&buf, LatexOptions{Longtable: true, Caption: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "\\begin{longtable}{l}\n" +
+		"\\caption{People} \\\\\n\\hline\nName \\\\\n\\hline\n\\endfirsthead\n" +
+		"\\caption[]{People (continued)} \\\\\n\\hline\nName \\\\\n\\hline\n\\endhead\n" +
+		"\\hline\n\\multicolumn{1}{r}{Continued on next page} \\\\\n\\endfoot\n" +
+		"\\hline\n\\endlastfoot\n" +
+		"Alice \\\\\n" +
+		"\\end{longtable}"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportLatexFloat(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Score"})
+	ds.SetTitle("Results")
+	ds.SetAlignment("Score", AlignRight)
+	ds.Append([]any{"Alice", 95})
+
+	var buf bytes.Buffer
+	opts := LatexOptions{Caption: true, Label: "tab:results", Float: true, Placement: "htbp"}
+	if err := ds.ExportLatex(&buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "\\begin{table}[htbp]\n\\centering\n\\caption{Results}\n\\label{tab:results}\n" +
+		"\\begin{tabular}{lr}\n\\hline\nName & Score \\\\\n\\hline\nAlice & 95 \\\\\n\\hline\n\\end{tabular}\n" +
+		"\\end{table}"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	ds.ExportLatex(&buf, LatexOptions{ColumnSpec: "p{3cm}r"})
+	if !strings.HasPrefix(buf.String(), "\\begin{tabular}{p{3cm}r}\n") {
+		t.Errorf("expected custom column spec, got:\n%s", buf.String())
+	}
+}
+
+func TestExportLatexSeparators(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Dept"})
+	ds.Append([]any{"Alice", "Eng"})
+	ds.Append([]any{"Bob", "Ops"})
+	ds.InsertSeparator(0, "Engineering")
+	ds.InsertSeparator(1, "R&D")
+
+	var buf bytes.Buffer
+	if err := ds.ExportLatex(&buf, LatexOptions{Booktabs: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "\\begin{tabular}{ll}\n\\toprule\nName & Dept \\\\\n\\midrule\n" +
+		"\\multicolumn{2}{l}{\\textbf{Engineering}} \\\\\n\\midrule\nAlice & Eng \\\\\n" +
+		"\\midrule\n\\multicolumn{2}{l}{\\textbf{R\\&D}} \\\\\n\\midrule\nBob & Ops \\\\\n" +
+		"\\bottomrule\n\\end{tabular}"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportRSTSimple(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	ds.Append([]any{"", 25})
+	ds.InsertSeparator(1, "Others")
+
+	var buf bytes.Buffer
+	if err := ds.ExportRST(&buf, RSTOptions{Style: RSTSimple}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "=====  ===\n" +
+		"Name   Age\n" +
+		"=====  ===\n" +
+		"Alice  30\n" +
+		"Others\n" +
+		"----------\n" +
+		"\\      25\n" +
+		"=====  ===\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportRSTListTable(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Notes"})
+	ds.SetTitle("People")
+	ds.Append([]any{"Alice", "first line\nsecond line"})
+	ds.Append([]any{"Bob", ""})
+
+	var buf bytes.Buffer
+	if err := ds.ExportRST(&buf, RSTOptions{Style: RSTListTable}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := ".. list-table:: People\n" +
+		"   :header-rows: 1\n" +
+		"   :widths: 5 21\n" +
+		"\n" +
+		"   * - Name\n" +
+		"     - Notes\n" +
+		"   * - Alice\n" +
+		"     - first line\n" +
+		"       second line\n" +
+		"   * - Bob\n" +
+		"     -\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportJiraEscaping(t *testing.T) {
+	ds := NewDataset([]string{"Date", "Note"})
+	ds.Append([]any{"2024-01-02", "a|b *c*"})
+
+	var buf bytes.Buffer
+	if err := ds.ExportJira(&buf, JiraOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "|2024-01-02|a\\|b *c*|") {
+		t.Errorf("expected only table characters escaped, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ds.ExportJira(&buf, JiraOptions{EscapeMarkup: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "|2024\\-01\\-02|a\\|b \\*c\\*|") {
+		t.Errorf("expected full escaping, got:\n%s", buf.String())
+	}
+}
+
+func TestExportOrg(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	ds.Append([]any{"Bob|B", 25})
+	ds.InsertSeparator(1, "Other")
+
+	var buf bytes.Buffer
+	if err := ds.Export(FormatOrg, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "| Name        | Age |\n" +
+		"|-------------+-----|\n" +
+		"| Alice       | 30  |\n" +
+		"|-------------+-----|\n" +
+		"| *Other*     |     |\n" +
+		"| Bob\\vert{}B | 25  |\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportDokuWikiAndTracWiki(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Note"})
+	ds.Append([]any{"Alice", "a|b"})
+	ds.AppendSeparator("End")
+
+	var buf bytes.Buffer
+	if err := ds.Export(FormatDokuWiki, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "^ Name ^ Note ^\n| Alice | %%a|b%% |\n| **End** ||\n"
+	if buf.String() != expected {
+		t.Errorf("expected DokuWiki:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := ds.Export(FormatTracWiki, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = "||= Name =||= Note =||\n|| Alice || a|b ||\n|||| '''End''' ||\n"
+	if buf.String() != expected {
+		t.Errorf("expected TracWiki:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestImportCLI(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.SetTitle("People")
+	ds.Append([]any{"Alice", 30})
+	ds.Append([]any{"", 25})
+	ds.InsertSeparator(1, "Others")
+
+	for _, style := range []string{"single", "double", "ascii"} {
+		var buf bytes.Buffer
+		if err := ds.ExportCLI(&buf, CLIOptions{BorderStyle: style, ShowTitle: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		imported, err := Import(FormatCLI, &buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", style, err)
+		}
+		if imported.Title() != "People" || imported.Height() != 2 || imported.Headers()[1] != "Age" {
+			t.Errorf("%s: unexpected import: title %q, %d rows, headers %v", style, imported.Title(), imported.Height(), imported.Headers())
+		}
+		if row, _ := imported.Row(1); row[0] != "" || row[1] != "25" {
+			t.Errorf("%s: expected empty first cell, got %v", style, row)
+		}
+		if sep, ok := imported.GetSeparator(1); !ok || sep.Text != "Others" {
+			t.Errorf("%s: expected separator, got %v", style, sep)
+		}
+	}
+
+	psql := " id | name\n----+-------\n  1 | Alice\n  2 | Bob\n(2 rows)\n"
+	imported, err := Import(FormatCLI, strings.NewReader(psql))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imported.Height() != 2 || imported.Headers()[0] != "id" {
+		t.Errorf("unexpected psql import: %v, %d rows", imported.Headers(), imported.Height())
+	}
+	if row, _ := imported.Row(1); row[1] != "Bob" {
+		t.Errorf("expected Bob, got %v", row)
+	}
+
+	// psql shows NULL as an empty cell, indented when first
+	nulls := " id | name \n----+------\n  1 | \n    | bob\n  3 | carol\n(3 rows)\n"
+	imported, err = Import(FormatCLI, strings.NewReader(nulls))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(imported.Headers()) != "[id name]" || imported.Height() != 3 {
+		t.Errorf("unexpected psql import: %v, %d rows", imported.Headers(), imported.Height())
+	}
+	if row, _ := imported.Row(0); row[0] != "1" || row[1] != "" {
+		t.Errorf("expected empty last cell, got %v", row)
+	}
+	if row, _ := imported.Row(1); row[0] != "" || row[1] != "bob" {
+		t.Errorf("expected empty first cell, got %v", row)
+	}
+
+	// An empty trailing header cell is named like a missing one
+	short := " id | name | \n----+------+----\n  1 | Alice | x\n"
+	imported, err = Import(FormatCLI, strings.NewReader(short))
+	if err != nil {
+		t.Fatalf("expected short headers to be padded, got %v", err)
+	}
+	if h := fmt.Sprint(imported.Headers()); h != "[id name Column3]" || imported.Height() != 1 {
+		t.Errorf("unexpected headers %s with %d rows", h, imported.Height())
+	}
+}
+
+func TestExportColumns(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age", "City"})
+	ds.Append([]any{"Alice", 30, "Paris"})
+
+	var buf bytes.Buffer
+	if err := ds.ExportColumns(FormatCSV, &buf, []string{"City", "Name"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "City,Name\nParis,Alice\n" {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	if ds.Width() != 3 {
+		t.Errorf("expected dataset to be unchanged, got width %d", ds.Width())
+	}
+
+	if err := ds.ExportColumns(FormatCSV, &buf, []string{"Missing"}); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestExportRowSelection(t *testing.T) {
+	ds := NewDataset([]string{"N"})
+	for i := 0; i < 6; i++ {
+		var tags []string
+		if i%2 == 1 {
+			tags = []string{"failed"}
+		}
+		ds.AppendTagged([]any{i}, tags)
+	}
+
+	var buf bytes.Buffer
+	if err := ds.ExportWith(FormatCSV, &buf, ExportOptions{Offset: 2, Limit: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "N\n2\n3\n4\n" {
+		t.Errorf("unexpected range output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ds.ExportWith(FormatCSV, &buf, ExportOptions{Tags: []string{"failed"}, Offset: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "N\n3\n5\n" {
+		t.Errorf("unexpected tag output:\n%s", buf.String())
+	}
+}
+
+func TestExportTotals(t *testing.T) {
+	ds := NewDataset([]string{"Item", "Qty", "Price"})
+	ds.Append([]any{"Apple", 3, 1.5})
+	ds.Append([]any{"Pear", 2, 2.5})
+	opts := ExportOptions{Totals: map[string]Aggregate{"Qty": AggregateSum, "Price": AggregateAvg}}
+
+	var buf bytes.Buffer
+	if err := ds.ExportWith(FormatCSV, &buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "Total,5,2\n") {
+		t.Errorf("expected totals row, got:\n%s", buf.String())
+	}
+	if ds.Height() != 2 {
+		t.Errorf("expected dataset to be unchanged, got %d rows", ds.Height())
+	}
+
+	buf.Reset()
+	if err := ds.ExportWith(FormatHTML, &buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<tfoot>\n    <tr>\n      <td>Total</td>\n      <td>5</td>") {
+		t.Errorf("expected totals in tfoot, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ds.ExportWith(FormatCLI, &buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasPrefix(lines[len(lines)-3], "├") || !strings.Contains(lines[len(lines)-2], "Total") {
+		t.Errorf("expected bordered totals footer, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ds.ExportWith(FormatXLSX, &buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	styleID, _ := f.GetCellStyle("Sheet1", "B4")
+	style, _ := f.GetStyle(styleID)
+	if style == nil || style.Font == nil || !style.Font.Bold {
+		t.Errorf("expected bold totals row")
+	}
+}
+
+func TestExportTransposed(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	ds.Append([]any{"Bob", 25})
+
+	var buf bytes.Buffer
+	if err := ds.ExportWith(FormatCSV, &buf, ExportOptions{Transpose: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "Name,Alice,Bob\nAge,30,25\n" {
+		t.Errorf("unexpected transposed output:\n%s", buf.String())
+	}
+}
+
+func TestExportShowTitle(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	ds.SetTitle("People")
+	ds.Append([]any{"Alice"})
+
+	expected := map[Format]string{
+		FormatHTML:     "<caption>People</caption>",
+		FormatMarkdown: "## People\n\n| Name",
+		FormatRST:      "People\n======\n\n+-",
+		FormatLatex:    "\\caption{People}",
+		FormatCLI:      "People",
+	}
+	for format, want := range expected {
+		var buf bytes.Buffer
+		if err := ds.ExportWith(format, &buf, ExportOptions{ShowTitle: true}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s: expected %q, got:\n%s", format, want, buf.String())
+		}
+
+		buf.Reset()
+		ds.Export(format, &buf)
+		if strings.Contains(buf.String(), "People") {
+			t.Errorf("%s: expected no title by default, got:\n%s", format, buf.String())
+		}
+	}
+}
+
+func TestDatabookApplySearchSubset(t *testing.T) {
+	a := NewDataset([]string{"Name"})
+	a.SetTitle("A")
+	a.Append([]any{"Alice"})
+	b := NewDataset([]string{"Name"})
+	b.SetTitle("B")
+	b.Append([]any{"Bob"})
+	b.Append([]any{"Alice"})
+
+	db := NewDatabook()
+	db.AddSheet(a)
+	db.AddSheet(b)
+
+	hits := db.Search("Alice")
+	if len(hits) != 2 || hits[1] != (SearchHit{Sheet: 1, Row: 1, Col: 0}) {
+		t.Errorf("unexpected hits: %v", hits)
+	}
+
+	if err := db.Apply(func(ds *Dataset) error { return ds.Append([]any{"Carol"}) }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Height() != 2 || b.Height() != 3 {
+		t.Errorf("expected Apply to reach every sheet")
+	}
+
+	subset, err := db.SubsetSheets("B")
+	if err != nil || subset.Size() != 1 {
+		t.Fatalf("unexpected subset: %v, %v", subset, err)
+	}
+	if _, err := db.SubsetSheets("Missing"); err == nil {
+		t.Errorf("expected error for missing sheet")
+	}
+}
+
+func TestDatabookMerge(t *testing.T) {
+	newSheet := func(title string, names ...string) *Dataset {
+		ds := NewDataset([]string{"Name"})
+		ds.SetTitle(title)
+		for _, n := range names {
+			ds.Append([]any{n})
+		}
+		return ds
+	}
+
+	db := NewDatabook()
+	db.AddSheet(newSheet("Sales", "Alice"))
+	other := NewDatabook()
+	other.AddSheet(newSheet("Sales", "Bob"))
+	other.AddSheet(newSheet("Ops", "Carol"))
+
+	renamed := NewDatabook()
+	renamed.AddSheet(db.sheets[0])
+	if err := renamed.Merge(other, ConflictRename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renamed.Size() != 3 || renamed.sheets[1].Title() != "Sales (2)" || other.sheets[0].Title() != "Sales" {
+		t.Errorf("unexpected renamed titles: %q, %q", renamed.sheets[1].Title(), other.sheets[0].Title())
+	}
+
+	if err := db.Merge(other, ConflictStack); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sales, _ := db.SheetByTitle("Sales")
+	if db.Size() != 2 || sales.Height() != 2 {
+		t.Errorf("expected stacked Sales sheet, got %d sheets and %d rows", db.Size(), sales.Height())
+	}
+}
+
+func TestExportDatabookCSVZip(t *testing.T) {
+	db := NewDatabook()
+	for _, title := range []string{"Sales/2024", "", "Sales/2024"} {
+		ds := NewDataset([]string{"Name"})
+		ds.SetTitle(title)
+		ds.Append([]any{"Alice"})
+		db.AddSheet(ds)
+	}
+
+	var buf bytes.Buffer
+	if err := db.Export(FormatCSV, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	expected := "index.csv,Sales_2024.csv,Sheet2.csv,Sales_2024_2.csv"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("expected files %s, got %v", expected, names)
+	}
+
+	f, _ := zr.Open("index.csv")
+	index, _ := io.ReadAll(f)
+	if !strings.Contains(string(index), "Sales_2024_2.csv,Sales/2024,1") {
+		t.Errorf("unexpected index:\n%s", index)
+	}
+
+	db = NewDatabook()
+	ds := NewDataset([]string{"Name"})
+	ds.SetTitle("index")
+	ds.Append([]any{"Alice"})
+	db.AddSheet(ds)
+	buf.Reset()
+	if err := db.Export(FormatCSV, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := ImportDatabookZIP(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("expected a sheet titled index not to clash with the manifest: %v", err)
+	}
+	if sheet, _ := got.Sheet(0); sheet.Title() != "index" || sheet.Height() != 1 {
+		t.Errorf("unexpected sheet %q with %d rows", sheet.Title(), sheet.Height())
+	}
+}
+
+func TestDatabookExportDir(t *testing.T) {
+	db := NewDatabook()
+	ds := NewDataset([]string{"Name"})
+	ds.SetTitle("Team: A")
+	ds.Append([]any{"Alice"})
+	db.AddSheet(ds)
+
+	dir := t.TempDir()
+	if err := db.ExportDir(dir, FormatMarkdown); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(dir + "/Team_ A.md")
+	if err != nil {
+		t.Fatalf("expected sheet file: %v", err)
+	}
+	if !strings.Contains(string(data), "| Alice |") {
+		t.Errorf("unexpected sheet content:\n%s", data)
+	}
+	index, err := os.ReadFile(dir + "/index.csv")
+	if err != nil || !strings.Contains(string(index), "Team_ A.md,Team: A,1") {
+		t.Errorf("unexpected index: %s, %v", index, err)
+	}
+
+	ds = NewDataset([]string{"Name"})
+	ds.SetTitle("Index")
+	ds.Append([]any{"Bob"})
+	db.AddSheet(ds)
+	dir = t.TempDir()
+	if err := db.ExportDir(dir, FormatCSV); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(dir + "/Index_2.csv"); err != nil || string(data) != "Name\nBob\n" {
+		t.Errorf("expected the sheet titled Index beside the manifest, got %q, %v", data, err)
+	}
+	if index, _ := os.ReadFile(dir + "/index.csv"); !strings.Contains(string(index), "Index_2.csv,Index,1") {
+		t.Errorf("unexpected index: %s", index)
+	}
+
+	if err := db.ExportDir(dir, Format("bogus")); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestExportDatabookSQL(t *testing.T) {
+	users := NewDataset([]string{"Name", "Age"})
+	users.SetTitle("users")
+	users.Append([]any{"Alice", 30})
+	users.Append([]any{"Bob", nil})
+	prices := NewDataset([]string{"Price"})
+	prices.Append([]any{1})
+	prices.Append([]any{2.5})
+
+	db := NewDatabook()
+	db.AddSheet(users)
+	db.AddSheet(prices)
+
+	var buf bytes.Buffer
+	if err := db.Export(FormatSQL, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "BEGIN;\n" +
+		"CREATE TABLE \"users\" (\"Name\" TEXT, \"Age\" INTEGER);\n" +
+		"INSERT INTO \"users\" (\"Name\", \"Age\") VALUES ('Alice', 30);\n" +
+		"INSERT INTO \"users\" (\"Name\", \"Age\") VALUES ('Bob', NULL);\n" +
+		"CREATE TABLE \"sheet2\" (\"Price\" REAL);\n" +
+		"INSERT INTO \"sheet2\" (\"Price\") VALUES (1);\n" +
+		"INSERT INTO \"sheet2\" (\"Price\") VALUES (2.5);\n" +
+		"COMMIT;\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestImportDatabookZIP(t *testing.T) {
+	db := NewDatabook()
+	for _, title := range []string{"Sales/2024", "Ops"} {
+		ds := NewDataset([]string{"Name"})
+		ds.SetTitle(title)
+		ds.Append([]any{"Alice"})
+		db.AddSheet(ds)
+	}
+	var buf bytes.Buffer
+	if err := db.Export(FormatCSV, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	imported, err := ImportDatabookZIP(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imported.Size() != 2 || imported.sheets[0].Title() != "Sales/2024" || imported.sheets[1].Height() != 1 {
+		t.Errorf("unexpected round trip: %d sheets, first %q", imported.Size(), imported.sheets[0].Title())
+	}
+
+	// Without a manifest, titles come from file names
+	buf.Reset()
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create("data/people.tsv")
+	f.Write([]byte("Name\tAge\nBob\t25\n"))
+	zw.Close()
+	imported, err = ImportDatabookZIP(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imported.Size() != 1 || imported.sheets[0].Title() != "people" || imported.sheets[0].Width() != 2 {
+		t.Errorf("unexpected import: %d sheets", imported.Size())
+	}
+}
+
+func TestImportDatabookRegistry(t *testing.T) {
+	db := NewDatabook()
+	for _, title := range []string{"Users", "Teams"} {
+		ds := NewDataset([]string{"Name"})
+		ds.SetTitle(title)
+		ds.Append([]any{"Alice"})
+		db.AddSheet(ds)
+	}
+
+	for _, format := range []Format{FormatXLSX, FormatXLS, FormatODS, FormatJSON, FormatYAML, FormatCSV} {
+		var buf bytes.Buffer
+		if err := db.Export(format, &buf); err != nil {
+			t.Fatalf("%s: unexpected export error: %v", format, err)
+		}
+		imported, err := ImportDatabook(format, &buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected import error: %v", format, err)
+		}
+		if imported.Size() != 2 || imported.sheets[1].Title() != "Teams" {
+			t.Errorf("%s: expected 2 sheets, got %d", format, imported.Size())
+			continue
+		}
+		if v, _ := imported.sheets[1].Get(0, 0); v != "Alice" {
+			t.Errorf("%s: expected Alice, got %v", format, v)
+		}
+	}
+
+	if _, err := ImportDatabook(FormatSQL, strings.NewReader("")); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestDatabookMetadata(t *testing.T) {
+	db := NewDatabook()
+	db.SetTitle("Quarterly")
+	db.SetAuthor("Finance")
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	db.SetCreated(created)
+	db.SetProperty("Department", "Sales")
+	ds := NewDataset([]string{"Name"})
+	ds.SetTitle("People")
+	ds.Append([]any{"Alice"})
+	db.AddSheet(ds)
+
+	for _, format := range []Format{FormatXLSX, FormatODS} {
+		var buf bytes.Buffer
+		if err := db.Export(format, &buf); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		imported, err := ImportDatabook(format, &buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if imported.Title() != "Quarterly" || imported.Author() != "Finance" || !imported.Created().Equal(created) {
+			t.Errorf("%s: unexpected metadata: %q, %q, %v", format, imported.Title(), imported.Author(), imported.Created())
+		}
+		if v, ok := imported.Property("Department"); !ok || v != "Sales" {
+			t.Errorf("%s: expected custom property, got %q", format, v)
+		}
+	}
+}
+
+func TestDatabookDictAndFlatten(t *testing.T) {
+	users := NewDataset([]string{"Name", "Age"})
+	users.SetTitle("Users")
+	users.Append([]any{"Alice", 30}, "admin")
+	products := NewDataset([]string{"Name", "Price"})
+	products.Append([]any{"Laptop", 999.0})
+
+	db := NewDatabook()
+	db.AddSheet(users)
+	db.AddSheet(products)
+
+	dict, err := db.Dict()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dict["Users"][0]["Age"] != 30 || dict["Sheet2"][0]["Price"] != 999.0 {
+		t.Errorf("unexpected dict: %v", dict)
+	}
+
+	flat, err := db.Flatten("Sheet")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(flat.Headers(), ","); got != "Sheet,Name,Age,Price" {
+		t.Errorf("unexpected headers: %v", got)
+	}
+	row, _ := flat.Row(1)
+	if row[0] != "Sheet2" || row[1] != "Laptop" || row[2] != nil || row[3] != 999.0 {
+		t.Errorf("unexpected row: %v", row)
+	}
+	if flat.Filter("admin").Height() != 1 {
+		t.Error("expected row tags to be kept")
+	}
+
+	if _, err := db.Flatten("Name"); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+}
+
+func TestTransposeWith(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	ds.Append([]any{"Bob", 25})
+
+	tr := ds.Transpose()
+	if got := strings.Join(tr.Headers(), ","); got != "Alice,Bob" || tr.Height() != 1 {
+		t.Errorf("unexpected transpose: %v, height %d", got, tr.Height())
+	}
+
+	tr = ds.TransposeWith(TransposeOptions{KeepHeaders: true})
+	row, _ := tr.Row(0)
+	if got := strings.Join(tr.Headers(), ","); got != "Name,Alice,Bob" || row[0] != "Age" || row[1] != 30 {
+		t.Errorf("unexpected transpose: %v, %v", got, row)
+	}
+
+	tr = ds.TransposeWith(TransposeOptions{Headers: TransposePositional})
+	row, _ = tr.Row(0)
+	if got := strings.Join(tr.Headers(), ","); got != "1,2" || tr.Height() != 2 || row[1] != "Bob" {
+		t.Errorf("unexpected transpose: %v, %v", got, row)
+	}
+
+	tr = ds.TransposeWith(TransposeOptions{Headers: TransposeNoHeaders, KeepHeaders: true})
+	row, _ = tr.Row(1)
+	if len(tr.Headers()) != 0 || row[0] != "Age" || row[2] != 25 {
+		t.Errorf("unexpected transpose: %v", row)
+	}
+}
+
+func TestAppendColWithFill(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	ds.Append([]any{"Alice"})
+	ds.Append([]any{"Bob"})
+
+	if err := ds.AppendCol("Age", []any{30}); err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+	if err := ds.AppendColWith("Age", []any{30}, ColumnOptions{Pad: true, Fill: 0}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := ds.Get(1, 1); v != 0 {
+		t.Errorf("expected padded 0, got %v", v)
+	}
+
+	if err := ds.InsertColWith(1, "City", []any{"NYC", "LA", "SF"}, ColumnOptions{Pad: true, Fill: "-"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ds.Height() != 3 {
+		t.Fatalf("expected 3 rows, got %d", ds.Height())
+	}
+	row, _ := ds.Row(2)
+	if row[0] != "-" || row[1] != "SF" || row[2] != "-" {
+		t.Errorf("unexpected new row: %v", row)
+	}
+}
+
+func TestConcat(t *testing.T) {
+	shards := make([]*Dataset, 3)
+	for i := range shards {
+		shards[i] = NewDataset([]string{"ID", "Name"})
+		shards[i].Append([]any{i, fmt.Sprintf("row%d", i)}, "shard")
+	}
+	shards[0].SetTitle("All")
+
+	result, err := Concat(shards...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Height() != 3 || result.Title() != "All" || result.Filter("shard").Height() != 3 {
+		t.Errorf("unexpected result: height %d, title %q", result.Height(), result.Title())
+	}
+	if v, _ := result.Get(2, 1); v != "row2" {
+		t.Errorf("expected row2, got %v", v)
+	}
+
+	other := NewDataset([]string{"ID", "Title"})
+	if _, err := Concat(shards[0], other); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+
+	reordered := NewDataset([]string{"Name", "ID"})
+	reordered.Append([]any{"late", 9})
+	reordered.InsertSeparator(0, "Shard B")
+	shards[0].InsertSeparator(0, "Shard A")
+	result, err = Concat(shards[0], shards[1], reordered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row, _ := result.Row(2); row[0] != 9 || row[1] != "late" {
+		t.Errorf("expected columns matched by header, got %v", row)
+	}
+	if sep, ok := result.GetSeparator(0); !ok || sep.Text != "Shard A" {
+		t.Errorf("expected first separator at row 0")
+	}
+	if sep, ok := result.GetSeparator(2); !ok || sep.Text != "Shard B" {
+		t.Errorf("expected separator moved to row 2")
+	}
+}
+
+func TestColumnFormat(t *testing.T) {
+	ds := NewDataset([]string{"Item", "Price", "Share", "Ratio"})
+	ds.Append([]any{"A", 1234.5, 0.125, 2})
+	ds.Append([]any{"B", "n/a", 0.5, 3.25})
+	if err := ds.SetColumnFormat("Price", "currency:USD"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ds.SetColumnFormat("Share", "percent:1")
+	ds.SetColumnFormat("Ratio", "%.2f%%")
+
+	if err := ds.SetColumnFormat("Price", "%d %d"); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+	if err := ds.SetColumnFormat("Missing", "percent"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+	if ds.ColumnFormat("Price") != "currency:USD" {
+		t.Errorf("unexpected format: %q", ds.ColumnFormat("Price"))
+	}
+
+	md, _ := ds.ExportString(FormatMarkdown)
+	for _, want := range []string{"$1,234.50", "12.5%", "2.00%", "n/a", "3.25%"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in markdown:\n%s", want, md)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ds.Export(FormatXLSX, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	styleID, _ := f.GetCellStyle("Sheet1", "C2")
+	style, _ := f.GetStyle(styleID)
+	if style.CustomNumFmt == nil || *style.CustomNumFmt != "0.0%" {
+		t.Errorf("expected percent number format, got %v", style.CustomNumFmt)
+	}
+	if v, _ := f.GetCellValue("Sheet1", "B2", excelize.Options{RawCellValue: true}); v != "1234.5" {
+		t.Errorf("expected numeric value to be kept, got %q", v)
+	}
+
+	buf.Reset()
+	if err := ds.Export(FormatODS, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	db, err := ImportODSDatabook(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := db.sheets[0].Get(0, 2); v != "0.125" {
+		t.Errorf("expected ODS value 0.125, got %v", v)
+	}
+}
+
+func TestImportWithLimits(t *testing.T) {
+	input := "Name,Bio\nAlice,Engineer\nBob,Designer\n"
+
+	if _, err := ImportWithLimits(FormatCSV, strings.NewReader(input), ImportLimits{MaxBytes: int64(len(input))}); err != nil {
+		t.Errorf("unexpected error at the byte limit: %v", err)
+	}
+	limits := []ImportLimits{
+		{MaxBytes: 10},
+		{MaxRows: 1},
+		{MaxColumns: 1},
+		{MaxCellSize: 5},
+	}
+	for _, l := range limits {
+		if _, err := ImportWithLimits(FormatCSV, strings.NewReader(input), l); err != ErrImportLimit {
+			t.Errorf("%+v: expected ErrImportLimit, got %v", l, err)
+		}
+	}
+
+	// A highly compressible sheet trips the expansion ratio
+	ds := NewDataset([]string{"Text"})
+	ds.Append([]any{strings.Repeat("a", 1<<20)})
+	db := NewDatabook()
+	db.AddSheet(ds)
+	var buf bytes.Buffer
+	if err := db.Export(FormatODS, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ImportDatabookWithLimits(FormatODS, bytes.NewReader(buf.Bytes()), ImportLimits{MaxExpansionRatio: 100}); err != ErrImportLimit {
+		t.Errorf("expected ErrImportLimit, got %v", err)
+	}
+	if _, err := ImportDatabookWithLimits(FormatODS, bytes.NewReader(buf.Bytes()), ImportLimits{MaxExpansionRatio: 1e6}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDatasetHash(t *testing.T) {
+	a := NewDataset([]string{"Name", "Age"})
+	a.Append([]any{"Alice", 30})
+	a.Append([]any{"Bob", 25})
+
+	b := a.Copy()
+	b.SetTitle("Other")
+	if a.Hash() != b.Hash() {
+		t.Error("expected copies to hash the same")
+	}
+
+	b.Set(1, 1, "25")
+	if a.Hash() == b.Hash() {
+		t.Error("expected typed values to change the hash")
+	}
+
+	reversed := NewDataset([]string{"Name", "Age"})
+	reversed.Append([]any{"Bob", 25})
+	reversed.Append([]any{"Alice", 30})
+	if a.Hash() == reversed.Hash() {
+		t.Error("expected row order to change the hash")
+	}
+	opts := HashOptions{IgnoreRowOrder: true}
+	if a.HashWith(opts) != reversed.HashWith(opts) {
+		t.Error("expected the same hash ignoring row order")
+	}
+}
+
+func TestNativeFormatRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	ds := NewDataset([]string{"Name", "Age", "Score", "Joined", "Total"})
+	ds.SetTitle("People")
+	ds.Append([]any{"Alice", 30, 9.5, created, Formula{Expression: "=B2*2", Value: int64(60)}}, "admin")
+	ds.Append([]any{nil, uint8(7), float32(1.5), true, HTML("<b>x</b>")})
+	ds.InsertSeparator(1, "Guests")
+	ds.SetAlignment("Age", AlignRight)
+	ds.SetColumnFormat("Score", "%.1f")
+	ds.AddDynamicColumn("Double", func(row []any) any { return row[1] })
+	ds.SetCellComment(1, 0, "Unknown")
+	ds.TagColumn("Score", "metric", "public")
+
+	var buf bytes.Buffer
+	if err := ds.Export(FormatTablib, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := Import(FormatTablib, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Title() != "People" || got.Hash() != ds.Hash() {
+		t.Errorf("expected identical content, got title %q", got.Title())
+	}
+	if v, _ := got.Get(0, 3); v != created {
+		t.Errorf("expected time value, got %#v", v)
+	}
+	if v, _ := got.Get(1, 1); v != uint8(7) {
+		t.Errorf("expected uint8 value, got %#v", v)
+	}
+	if got.Filter("admin").Height() != 1 {
+		t.Error("expected row tags to be kept")
+	}
+	if sep, ok := got.GetSeparator(1); !ok || sep.Text != "Guests" {
+		t.Errorf("expected separator, got %v", sep)
+	}
+	if got.Alignment("Age") != AlignRight || got.ColumnFormat("Score") != "%.1f" {
+		t.Error("expected column metadata to be kept")
+	}
+	if got.dynamicIndex("Double") == -1 {
+		t.Error("expected dynamic column name to be kept")
+	}
+	if got.CellComment(1, 0) != "Unknown" {
+		t.Error("expected cell comments to be kept")
+	}
+	if tags := got.ColumnTags("Score"); fmt.Sprint(tags) != "[metric public]" {
+		t.Errorf("expected column tags to be kept, got %v", tags)
+	}
+
+	if _, err := ImportString(FormatTablib, "TBLB\x09"); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat for a newer version, got %v", err)
+	}
+	if _, err := ImportString(FormatTablib, "TBLB\x01\x05ab"); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData for truncated input, got %v", err)
+	}
+	if _, err := ImportString(FormatTablib, "TBLB\x02\x00\x00\x00\x80\x80\x80\x80\x08"); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData for a count beyond the input, got %v", err)
+	}
+	if _, err := ImportString(FormatTablib, "TBLB\x02\x00\x00\x00\x01\x05\x00\x00\x00\x00"); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData for a separator beyond the rows, got %v", err)
+	}
+	if _, err := ImportString(FormatTablib, "TBLB\x03\x00\x00\x00\x00\x00\x00\x00\x01\x00\x01A\x01x"); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData for a comment beyond the rows, got %v", err)
+	}
+
+	// A deeply nested formula must fail rather than overflow the stack
+	nested := "TBLB\x02\x00\x00\x01\x01" + strings.Repeat("\x11\x00", 1<<20) + "\x00\x00\x00\x00\x00\x00"
+	if _, err := ImportString(FormatTablib, nested); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData for a nested formula, got %v", err)
+	}
+	inner := Formula{Expression: "=A1", Value: Formula{Expression: "=B1", Value: 1}}
+	nestedDs := NewDataset([]string{"Total"})
+	nestedDs.Append([]any{inner})
+	if err := nestedDs.Export(FormatTablib, io.Discard); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData exporting a nested formula, got %v", err)
+	}
+}
+
+// chunkWriter records the largest single write it receives.
+type chunkWriter struct {
+	largest int
+	total   int
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	cw.largest = max(cw.largest, len(p))
+	cw.total += len(p)
+	return len(p), nil
+}
+
+func TestTextExportsStream(t *testing.T) {
+	ds := NewDataset([]string{"ID", "Name"})
+	for i := range 5000 {
+		ds.Append([]any{i, "row"})
+	}
+
+	for _, format := range []Format{FormatMarkdown, FormatHTML, FormatRST, FormatCLI, FormatLatex, FormatJira} {
+		var cw chunkWriter
+		if err := ds.Export(format, &cw); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if cw.largest > 4096 || cw.total < 5000*10 {
+			t.Errorf("%s: expected buffered writes, largest %d of %d bytes", format, cw.largest, cw.total)
+		}
+	}
+
+	sampled := NewDataset([]string{"Name"})
+	sampled.Append([]any{"Al"})
+	sampled.Append([]any{"Bartholomew"})
+	var buf bytes.Buffer
+	if err := sampled.ExportMarkdown(&buf, MarkdownOptions{SampleRows: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| Name |\n| ---- |\n| Al   |\n| Bartholomew |") {
+		t.Errorf("unexpected sampled markdown:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := sampled.ExportCLI(&buf, CLIOptions{SampleRows: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "│ Bar… │") {
+		t.Errorf("unexpected sampled CLI table:\n%s", buf.String())
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Score"})
+	ds.Append([]any{"Bob", 2})
+	ds.Append([]any{"Alice", "4"})
+	ds.Append([]any{"Bob", nil})
+	ds.Append([]any{"", 6.0})
+
+	summary := ds.Describe()
+	if summary.Height() != 2 || summary.Width() != 8 {
+		t.Fatalf("unexpected summary size %dx%d", summary.Height(), summary.Width())
+	}
+
+	name, _ := summary.Row(0)
+	if name[1] != 3 || name[2] != 2 || name[3] != 1 || name[4] != "Alice" || name[5] != "Bob" || name[6] != nil {
+		t.Errorf("unexpected text column summary: %v", name)
+	}
+
+	score, _ := summary.Row(1)
+	if score[1] != 3 || score[3] != 1 || score[4] != 2.0 || score[5] != 6.0 || score[6] != 4.0 || score[7] != 2.0 {
+		t.Errorf("unexpected numeric column summary: %v", score)
+	}
+}
+
+func TestNullHandling(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"John", nil})
+	ds.AppendTagged([]any{nil, 25}, []string{"b"})
+	ds.Append([]any{"Jane", 30})
+
+	for _, format := range []Format{FormatCSV, FormatMarkdown, FormatCLI, FormatHTML} {
+		out, err := ds.ExportString(format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if strings.Contains(out, "<nil>") {
+			t.Errorf("%s export renders nil as <nil>:\n%s", format, out)
+		}
+	}
+	csvOut, _ := ds.ExportString(FormatCSV)
+	if !strings.Contains(csvOut, "John,\n") || !strings.Contains(csvOut, ",25\n") {
+		t.Errorf("expected empty CSV cells for nil, got:\n%s", csvOut)
+	}
+	jsonOut, _ := ds.ExportString(FormatJSON)
+	if !strings.Contains(jsonOut, "null") {
+		t.Errorf("expected JSON null, got:\n%s", jsonOut)
+	}
+	sqlOut, _ := ds.ExportString(FormatSQL)
+	if !strings.Contains(sqlOut, "NULL") {
+		t.Errorf("expected SQL NULL, got:\n%s", sqlOut)
+	}
+
+	dropped := ds.DropNA()
+	if dropped.Height() != 1 {
+		t.Fatalf("expected 1 row after DropNA, got %d", dropped.Height())
+	}
+	if row, _ := dropped.Row(0); row[0] != "Jane" {
+		t.Errorf("unexpected row after DropNA: %v", row)
+	}
+
+	if err := ds.FillNAColumn("Missing", 0); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+	if err := ds.FillNAColumn("Age", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row, _ := ds.Row(0); row[1] != 0 {
+		t.Errorf("expected filled Age, got %v", row[1])
+	}
+	if row, _ := ds.Row(1); row[0] != nil {
+		t.Errorf("FillNAColumn changed another column: %v", row)
+	}
+	ds.FillNA("n/a")
+	if row, _ := ds.Row(1); row[0] != "n/a" {
+		t.Errorf("expected filled Name, got %v", row[0])
+	}
+	if ds.DropNA().Height() != 3 {
+		t.Errorf("expected no nil cells after FillNA")
+	}
+}
+
+func TestMapColumnAndRows(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"john", 30})
+	ds.Append([]any{"jane", 25})
+
+	if err := ds.MapColumn("Name", func(v any) any { return strings.ToUpper(v.(string)) }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ds.MapColumn("Missing", func(v any) any { return v }); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+	if v, _ := ds.Get(1, 0); v != "JANE" {
+		t.Errorf("expected JANE, got %v", v)
+	}
+
+	err := ds.MapRows(func(row []any) []any {
+		row[1] = row[1].(int) + 1
+		return row
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := ds.Get(0, 1); v != 31 {
+		t.Errorf("expected 31, got %v", v)
+	}
+
+	err = ds.MapRows(func(row []any) []any { return row[:1] })
+	if err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+	if v, _ := ds.Get(0, 1); v != 31 {
+		t.Errorf("failed MapRows changed the dataset: %v", v)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age", "Home City"})
+	ds.Append([]any{"John", 35, "London"})
+	ds.AppendTagged([]any{"Jane", "42", "Paris"}, []string{"vip"})
+	ds.Append([]any{"Bob", 28, nil})
+	ds.Append([]any{"Ann", 35, "Rome"})
+
+	result, err := ds.Query("SELECT Name, Age WHERE Age > 30 ORDER BY Age DESC, Name LIMIT 2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(result.Headers(), ",") != "Name,Age" || result.Height() != 2 {
+		t.Fatalf("unexpected result %v with %d rows", result.Headers(), result.Height())
+	}
+	first, _ := result.Row(0)
+	second, _ := result.Row(1)
+	if first[0] != "Jane" || second[0] != "Ann" {
+		t.Errorf("unexpected order: %v, %v", first, second)
+	}
+	if result.Filter("vip").Height() != 1 {
+		t.Errorf("expected row tags to be kept")
+	}
+
+	result, err = ds.Query(`select * where "Home City" is null or (Name = 'Ann' and not Age <> 35)`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Height() != 2 || result.Width() != 3 {
+		t.Fatalf("unexpected result size %dx%d", result.Height(), result.Width())
+	}
+
+	if _, err := ds.Query("SELECT Name WHERE"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+	if _, err := ds.Query("SELECT Salary"); !errors.Is(err, ErrColumnNotFound) {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+
+	ds.AddDynamicColumn("Initial", func(row []any) any { return row[0].(string)[:1] })
+	result, err = ds.Query("SELECT Initial, Name WHERE Initial = 'J' ORDER BY Initial, Name DESC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if col, _ := result.Column(1); fmt.Sprint(col) != "[John Jane]" {
+		t.Errorf("expected a dynamic column to be queried, got %v", col)
+	}
+	if result, _ = ds.Query("SELECT *"); strings.Join(result.Headers(), ",") != "Name,Age,Home City,Initial" {
+		t.Errorf("expected * to include dynamic columns, got %v", result.Headers())
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	ds := NewDataset([]string{"Dept", "Name", "Age"})
+	ds.Append([]any{"Eng", "Bob", 30})
+	ds.Append([]any{"Ops", "Ann", 41})
+	ds.AppendTagged([]any{"Eng", "Cid", 45}, []string{"lead"})
+	ds.Append([]any{"Eng", "Dee", 30})
+
+	sorted, err := ds.SortBy([]SortKey{{Header: "Dept"}, {Header: "Age", Descending: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for i := 0; i < sorted.Height(); i++ {
+		row, _ := sorted.Row(i)
+		names = append(names, row[1].(string))
+	}
+	if got := strings.Join(names, ","); got != "Cid,Bob,Dee,Ann" {
+		t.Errorf("unexpected order %s", got)
+	}
+	if sorted.Filter("lead").Height() != 1 {
+		t.Errorf("expected tags to follow their rows")
+	}
+	if first, _ := sorted.Row(0); first[2] != 45 {
+		t.Errorf("tagged row moved without its data: %v", first)
+	}
+
+	if _, err := ds.SortBy([]SortKey{{Header: "Missing"}}); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestSortFunc(t *testing.T) {
+	months := map[string]int{"Jan": 1, "Feb": 2, "Mar": 3}
+	ds := NewDataset([]string{"Month", "Sales"})
+	ds.Append([]any{"Mar", 3})
+	ds.Append([]any{"Jan", 1})
+	ds.Append([]any{"Feb", 2})
+
+	sorted, err := ds.SortFunc(0, func(a, b any) int {
+		return months[a.(string)] - months[b.(string)]
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < sorted.Height(); i++ {
+		if row, _ := sorted.Row(i); row[1] != i+1 {
+			t.Errorf("unexpected row %d: %v", i, row)
+		}
+	}
+
+	if _, err := ds.SortFunc(5, func(a, b any) int { return 0 }); err != ErrInvalidColumnIndex {
+		t.Errorf("expected ErrInvalidColumnIndex, got %v", err)
+	}
+}
+
+func TestRenameColumns(t *testing.T) {
+	ds := NewDataset([]string{"first", "last", "age"})
+	ds.Append([]any{"Alice", "Smith", 30})
+	ds.Append([]any{"Bob", "Jones", 41})
+	ds.SetAlignment("age", AlignRight)
+	ds.InsertSeparator(1, "Group")
+	ds.AddDynamicColumn("full", func(row []any) any {
+		return row[0].(string) + " " + row[1].(string)
+	})
+
+	if err := ds.RenameColumns(map[string]string{"first": "last", "last": "first", "full": "Full Name"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ds.RenameColumn("age", "Age"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(ds.Headers(), ","); got != "last,first,Age,Full Name" {
+		t.Errorf("unexpected headers %s", got)
+	}
+	if ds.Alignment("Age") != AlignRight {
+		t.Errorf("expected alignment to follow the renamed column")
+	}
+	if _, ok := ds.GetSeparator(1); !ok {
+		t.Errorf("expected separator to be kept")
+	}
+	dict, _ := ds.Dict()
+	if dict[0]["Full Name"] != "Alice Smith" {
+		t.Errorf("expected renamed dynamic column, got %v", dict[0])
+	}
+
+	if err := ds.RenameColumn("Missing", "x"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+	if err := ds.RenameColumn("Age", "first"); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+	if got := strings.Join(ds.Headers(), ","); got != "last,first,Age,Full Name" {
+		t.Errorf("failed rename changed headers: %s", got)
+	}
+}
+
+func TestUpdateWhere(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Phone"})
+	ds.Append([]any{"Alice", "N/A"})
+	ds.Append([]any{"Bob", "555-0100"})
+	ds.Append([]any{"Cid", "N/A"})
+
+	n, err := ds.UpdateWhere(func(row []any) bool { return row[1] == "N/A" }, "Phone", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 updated rows, got %d", n)
+	}
+	if v, _ := ds.Get(2, 1); v != nil {
+		t.Errorf("expected nil, got %v", v)
+	}
+	if v, _ := ds.Get(1, 1); v != "555-0100" {
+		t.Errorf("unexpected update of non-matching row: %v", v)
+	}
+
+	if _, err := ds.UpdateWhere(func([]any) bool { return true }, "Missing", 0); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestFromStructs(t *testing.T) {
+	type Base struct {
+		ID int `tablib:"id"`
+	}
+	type Person struct {
+		Base
+		Name   string
+		Age    int    `tablib:"Years"`
+		Secret string `tablib:"-"`
+		note   string
+	}
+
+	ds, err := FromStructs([]*Person{
+		{Base: Base{ID: 1}, Name: "Alice", Age: 30, Secret: "x", note: "y"},
+		nil,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(ds.Headers(), ","); got != "id,Name,Years" {
+		t.Errorf("unexpected headers %s", got)
+	}
+	row, _ := ds.Row(0)
+	if row[0] != 1 || row[1] != "Alice" || row[2] != 30 {
+		t.Errorf("unexpected row %v", row)
+	}
+	if row, _ := ds.Row(1); row[0] != nil || row[1] != nil {
+		t.Errorf("expected nil cells for a nil pointer, got %v", row)
+	}
+
+	empty, err := FromStructs([]Person{})
+	if err != nil || empty.Width() != 3 || empty.Height() != 0 {
+		t.Errorf("expected headers from an empty slice, got %v, %v", empty, err)
+	}
+	if _, err := FromStructs([]int{1}); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+}
+
+func TestToStructs(t *testing.T) {
+	type Meta struct {
+		Active bool
+	}
+	type Person struct {
+		*Meta
+		Name   string
+		Age    int `tablib:"Years"`
+		Score  *float64
+		Joined time.Time
+		ID     uint64
+	}
+
+	ds, _ := ImportString(FormatCSV, "Name,Years,Score,Joined,Active,ID,Extra\n"+
+		"Alice,30,9.5,2024-03-01,true,9007199254740993,x\n"+
+		"Bob,,,,false,1,y\n")
+
+	var people []*Person
+	if err := ds.ToStructs(&people); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(people) != 2 {
+		t.Fatalf("expected 2 people, got %d", len(people))
+	}
+	alice := people[0]
+	if alice.Name != "Alice" || alice.Age != 30 || alice.Score == nil || *alice.Score != 9.5 {
+		t.Errorf("unexpected person %+v", alice)
+	}
+	if !alice.Active || alice.ID != 9007199254740993 || alice.Joined.Format("2006-01-02") != "2024-03-01" {
+		t.Errorf("unexpected converted fields %+v", alice)
+	}
+	if bob := people[1]; bob.Age != 0 || bob.Score != nil || bob.Active {
+		t.Errorf("expected empty cells to leave zero values, got %+v", bob)
+	}
+
+	round, _ := FromStructs([]Person{{Name: "Cid", Age: 41}})
+	var back []Person
+	if err := round.ToStructs(&back); err != nil || back[0].Name != "Cid" || back[0].Age != 41 {
+		t.Errorf("unexpected round trip %+v, %v", back, err)
+	}
+
+	bad := NewDataset([]string{"Years"})
+	bad.Append([]any{"old"})
+	if err := bad.ToStructs(&back); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+	if err := ds.ToStructs(back); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData for a non-pointer, got %v", err)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	ds := NewDataset([]string{"ID", "Name"})
+	ds.Append([]any{"a", "Alice"})
+	ds.Append([]any{"b", "Bob"})
+	ds.Append([]any{"a", "Ann"})
+
+	if err := ds.BuildIndex("Missing"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+	if err := ds.BuildIndex("ID"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fmt.Sprint(ds.LookupRows("ID", "a")); got != "[0 2]" {
+		t.Errorf("unexpected lookup %s", got)
+	}
+
+	ds.Append([]any{"a", "Abe"})
+	if got := fmt.Sprint(ds.LookupRows("ID", "a")); got != "[0 2 3]" {
+		t.Errorf("expected appended row to be indexed, got %s", got)
+	}
+
+	ds.Set(0, 0, "c")
+	if got := fmt.Sprint(ds.LookupRows("ID", "a")); got != "[2 3]" {
+		t.Errorf("expected stale index to be discarded, got %s", got)
+	}
+	if rows := ds.LookupRows("ID", "z"); len(rows) != 0 {
+		t.Errorf("expected no rows, got %v", rows)
+	}
+	if rows := ds.LookupRows("Missing", "a"); rows != nil {
+		t.Errorf("expected nil for unknown header, got %v", rows)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	ds := NewDataset([]string{"City", "Tags"})
+	ds.Append([]any{"Paris", []string{"a"}})
+	ds.Append([]any{"Rome", []string{"b"}})
+	ds.Append([]any{"Paris", []string{"a"}})
+	ds.Append([]any{nil, nil})
+	ds.Append([]any{1, nil})
+	ds.Append([]any{"1", nil})
+
+	values, err := ds.Unique("City")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fmt.Sprint(values); got != "[Paris Rome <nil> 1 1]" {
+		t.Errorf("unexpected unique values %s", got)
+	}
+	if tags, _ := ds.Unique("Tags"); len(tags) != 3 {
+		t.Errorf("expected 3 unique slice values, got %v", tags)
+	}
+	if _, err := ds.Unique("Missing"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestValueCounts(t *testing.T) {
+	ds := NewDataset([]string{"City"})
+	for _, city := range []any{"Rome", "Paris", "Paris", nil, "Rome", "Paris", "Oslo"} {
+		ds.Append([]any{city})
+	}
+
+	counts, err := ds.ValueCounts("City")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(counts.Headers(), ","); got != "City,Count" {
+		t.Errorf("unexpected headers %s", got)
+	}
+	var got []string
+	for i := 0; i < counts.Height(); i++ {
+		row, _ := counts.Row(i)
+		got = append(got, fmt.Sprintf("%v=%v", row[0], row[1]))
+	}
+	if strings.Join(got, " ") != "Paris=3 Rome=2 <nil>=1 Oslo=1" {
+		t.Errorf("unexpected counts %v", got)
+	}
+	if _, err := ds.ValueCounts("Missing"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestHeadTailSample(t *testing.T) {
+	ds := NewDataset([]string{"N"})
+	for i := range 10 {
+		ds.Append([]any{i}, fmt.Sprintf("t%d", i))
+	}
+	ids := func(d *Dataset) string {
+		col, _ := d.Column(0)
+		return fmt.Sprint(col)
+	}
+
+	if got := ids(ds.Head(3)); got != "[0 1 2]" {
+		t.Errorf("unexpected head %s", got)
+	}
+	if got := ids(ds.Tail(2)); got != "[8 9]" {
+		t.Errorf("unexpected tail %s", got)
+	}
+	if ds.Head(20).Height() != 10 || ds.Tail(-1).Height() != 0 {
+		t.Errorf("expected n to be clamped")
+	}
+	if ds.Tail(1).Filter("t9").Height() != 1 {
+		t.Errorf("expected tags to be kept")
+	}
+
+	sample := ds.Sample(4, 42)
+	if sample.Height() != 4 {
+		t.Fatalf("expected 4 rows, got %d", sample.Height())
+	}
+	if ids(ds.Sample(4, 42)) != ids(sample) {
+		t.Errorf("expected the same seed to select the same rows")
+	}
+	col, _ := sample.Column(0)
+	for i := 1; i < len(col); i++ {
+		if col[i].(int) <= col[i-1].(int) {
+			t.Errorf("expected sampled rows in original order, got %v", col)
+		}
+	}
+}
+
+func TestSliceRows(t *testing.T) {
+	ds := NewDataset([]string{"N", "Sq"})
+	for i := range 6 {
+		ds.Append([]any{i, i * i})
+	}
+
+	page, err := ds.SliceRows(2, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if col, _ := page.Column(0); fmt.Sprint(col) != "[2 3 4]" {
+		t.Errorf("unexpected page %v", col)
+	}
+	ds.SetCellComment(3, 1, "nine")
+	if withComment, _ := ds.SliceRows(2, 5); withComment.CellComment(1, 1) != "nine" {
+		t.Errorf("expected the page to keep cell comments")
+	}
+
+	page.Set(0, 1, "shared")
+	if v, _ := ds.Get(2, 1); v != "shared" {
+		t.Errorf("expected cells to be shared, got %v", v)
+	}
+
+	ds.Pop(0)
+	ds.DeleteCol(1)
+	if col, _ := page.Column(0); fmt.Sprint(col) != "[2 3 4]" || page.Width() != 2 {
+		t.Errorf("row and column changes leaked into the page: %v", col)
+	}
+	page.Append([]any{9, 81})
+	if ds.Height() != 5 {
+		t.Errorf("append to the page changed the source height to %d", ds.Height())
+	}
+
+	view, _ := ds.SliceRows(0, 2)
+	copied := ds.Copy()
+	filtered := ds.Head(2)
+	view.Set(0, 0, 99)
+	if v, _ := copied.Get(0, 0); v != 1 {
+		t.Errorf("expected a copy not to see writes through a page, got %v", v)
+	}
+	if v, _ := filtered.Get(0, 0); v != 1 {
+		t.Errorf("expected a selection not to see writes through a page, got %v", v)
+	}
+	if v, _ := ds.Get(0, 0); v != 99 {
+		t.Errorf("expected the page to stay shared with its source, got %v", v)
+	}
+
+	if _, err := ds.SliceRows(3, 2); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+	if _, err := ds.SliceRows(0, 10); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	newLeft := func() *Dataset {
+		ds := NewDataset([]string{"ID", "Name", "Score"})
+		ds.Append([]any{1, "Alice", 10}, "old")
+		ds.Append([]any{2, "Bob", 20})
+		return ds
+	}
+	right := NewDataset([]string{"Score", "ID", "Name", "Extra"})
+	right.Append([]any{15, 1, "Alicia", "x"})
+	right.Append([]any{30, 3, "Cid", "y"}, "new")
+
+	ds := newLeft()
+	if err := ds.Merge(right, "ID", PreferRight); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ds.Height() != 3 || ds.Width() != 3 {
+		t.Fatalf("unexpected size %dx%d", ds.Height(), ds.Width())
+	}
+	if row, _ := ds.Row(0); row[1] != "Alicia" || row[2] != 15 {
+		t.Errorf("expected right row to win, got %v", row)
+	}
+	if ds.Filter("old").Height() != 1 || ds.Filter("new").Height() != 1 {
+		t.Errorf("expected tags of merged and appended rows to be kept")
+	}
+
+	ds = newLeft()
+	ds.Merge(right, "ID", PreferLeft)
+	if row, _ := ds.Row(0); row[1] != "Alice" {
+		t.Errorf("expected left row to win, got %v", row)
+	}
+
+	ds = newLeft()
+	err := ds.Merge(right, "ID", func(left, right []any) []any {
+		left[2] = left[2].(int) + right[2].(int)
+		return left
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row, _ := ds.Row(0); row[1] != "Alice" || row[2] != 25 {
+		t.Errorf("unexpected resolved row %v", row)
+	}
+
+	ds = newLeft()
+	if err := ds.Merge(right, "ID", func(left, right []any) []any { return left[:1] }); err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+	if ds.Height() != 2 {
+		t.Errorf("failed merge changed the dataset")
+	}
+	if err := ds.Merge(NewDataset([]string{"ID"}), "ID", nil); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestCrossJoin(t *testing.T) {
+	sizes := NewDataset([]string{"Size"})
+	sizes.Append([]any{"S"}, "small")
+	sizes.Append([]any{"L"})
+	colors := NewDataset([]string{"Color", "Hex"})
+	colors.Append([]any{"red", "#f00"})
+	colors.Append([]any{"blue", "#00f"}, "small")
+	colors.Append([]any{"green", "#0f0"})
+
+	grid := sizes.CrossJoin(colors)
+	if got := strings.Join(grid.Headers(), ","); got != "Size,Color,Hex" {
+		t.Errorf("unexpected headers %s", got)
+	}
+	if grid.Height() != 6 {
+		t.Fatalf("expected 6 rows, got %d", grid.Height())
+	}
+	if row, _ := grid.Row(4); row[0] != "L" || row[1] != "blue" || row[2] != "#00f" {
+		t.Errorf("unexpected row %v", row)
+	}
+	if grid.Filter("small").Height() != 4 {
+		t.Errorf("expected tags from both sides, got %d tagged rows", grid.Filter("small").Height())
+	}
+	if sizes.CrossJoin(NewDataset([]string{"X"})).Height() != 0 {
+		t.Errorf("expected an empty product with an empty dataset")
+	}
+}
+
+func TestFindSimilarRows(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	for _, name := range []any{"Jon Smith", "John  Smith", "Alice Jones", "jon smith", nil, "Bob"} {
+		ds.Append([]any{name})
+	}
+
+	pairs, err := ds.FindSimilarRows("Name", 0.85)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, p := range pairs {
+		got = append(got, fmt.Sprintf("%d-%d:%.2f", p.First, p.Second, p.Similarity))
+	}
+	if strings.Join(got, " ") != "0-1:0.90 0-3:1.00 1-3:0.90" {
+		t.Errorf("unexpected pairs %v", got)
+	}
+
+	if levenshtein([]rune("kitten"), []rune("sitting")) != 3 {
+		t.Errorf("unexpected Levenshtein distance")
+	}
+	if _, err := ds.FindSimilarRows("Name", 1.5); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+	if _, err := ds.FindSimilarRows("Missing", 0.5); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestSplitBy(t *testing.T) {
+	ds := NewDataset([]string{"Region", "Sales"})
+	ds.Append([]any{"North", 10})
+	ds.Append([]any{"South", 20}, "big")
+	ds.Append([]any{"North", 30})
+
+	db, err := ds.SplitBy("Region")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.Size() != 2 {
+		t.Fatalf("expected 2 sheets, got %d", db.Size())
+	}
+	north, err := db.SheetByTitle("North")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if col, _ := north.Column(1); fmt.Sprint(col) != "[10 30]" {
+		t.Errorf("unexpected North rows %v", col)
+	}
+	south, _ := db.Sheet(1)
+	if south.Title() != "South" || south.Filter("big").Height() != 1 {
+		t.Errorf("unexpected South sheet %q", south.Title())
+	}
+
+	if _, err := ds.SplitBy("Missing"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	ds.Append([]any{"Bob", 17}, "minor")
+	ds.Append([]any{"Cid", 45})
+	ds.SetAlignment("Age", AlignRight)
+
+	adults, minors := ds.Partition(func(row []any) bool { return row[1].(int) >= 18 })
+	if col, _ := adults.Column(0); fmt.Sprint(col) != "[Alice Cid]" {
+		t.Errorf("unexpected matches %v", col)
+	}
+	if minors.Height() != 1 || minors.Filter("minor").Height() != 1 {
+		t.Errorf("unexpected rest with %d rows", minors.Height())
+	}
+	if adults.Alignment("Age") != AlignRight || minors.Alignment("Age") != AlignRight {
+		t.Errorf("expected column settings on both partitions")
+	}
+}
+
+func TestDateColumns(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Born", "Seen"})
+	ds.Append([]any{"Alice", "1990-04-01", "2024-05-06T07:08:09Z"})
+	ds.Append([]any{"Bob", "", "2024-05-07 10:00:00"})
+
+	if err := ds.ParseDates("Born"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ds.ParseDates("Seen"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	born, _ := ds.Get(0, 1)
+	if tm, ok := born.(time.Time); !ok || !tm.Equal(time.Date(1990, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected parsed date %v", born)
+	}
+	if v, _ := ds.Get(1, 1); v != nil {
+		t.Errorf("expected nil for an empty date, got %v", v)
+	}
+
+	other := NewDataset([]string{"When"})
+	other.Append([]any{"01/02/2024"})
+	other.Append([]any{"soon"})
+	if err := other.ParseDates("When", "01/02/2006"); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+	if v, _ := other.Get(0, 0); v != "01/02/2024" {
+		t.Errorf("expected column to be unchanged, got %v", v)
+	}
+	if err := ds.ParseDates("Missing"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+
+	csv, _ := ds.ExportString(FormatCSV)
+	if !strings.Contains(csv, "Alice,1990-04-01T00:00:00Z,2024-05-06T07:08:09Z") {
+		t.Errorf("expected ISO dates in CSV:\n%s", csv)
+	}
+	js, _ := ds.ExportString(FormatJSON)
+	if !strings.Contains(js, `"2024-05-06T07:08:09Z"`) {
+		t.Errorf("expected RFC 3339 times in JSON:\n%s", js)
+	}
+
+	sql, _ := ds.ExportString(FormatSQL)
+	if !strings.Contains(sql, "DATE '1990-04-01'") || !strings.Contains(sql, "TIMESTAMP '2024-05-06 07:08:09'") {
+		t.Errorf("unexpected SQL literals:\n%s", sql)
+	}
+	if sqlColumnType(ds, 1) != "DATE" || sqlColumnType(ds, 2) != "TIMESTAMP" {
+		t.Errorf("unexpected SQL types %s, %s", sqlColumnType(ds, 1), sqlColumnType(ds, 2))
+	}
+
+	var buf bytes.Buffer
+	if err := ds.Export(FormatDBF, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	imported, err := ImportDBF(bytes.NewReader(buf.Bytes()), DBFImportOptions{Typed: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := imported.Get(0, 1); v != time.Date(1990, 4, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("expected a DBF date field, got %v", v)
+	}
+
+	if err := ds.SetColumnFormat("Seen", "date:02 Jan 2006"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	md, _ := ds.ExportString(FormatMarkdown)
+	if !strings.Contains(md, "06 May 2024") {
+		t.Errorf("expected formatted date in markdown:\n%s", md)
+	}
+	if spreadsheetDateFormat("02 Jan 2006") != `dd\ mmm\ yyyy` {
+		t.Errorf("unexpected number format %q", spreadsheetDateFormat("02 Jan 2006"))
+	}
+
+	buf.Reset()
+	if err := ds.Export(FormatXLSX, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	styleID, _ := f.GetCellStyle("Sheet1", "C2")
+	style, _ := f.GetStyle(styleID)
+	if style.CustomNumFmt == nil || *style.CustomNumFmt != `dd\ mmm\ yyyy` {
+		t.Errorf("expected date number format, got %v", style.CustomNumFmt)
+	}
+
+	buf.Reset()
+	if err := ds.Export(FormatODS, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sheet, err := ImportODS(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := sheet.Get(0, 2); v != time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC) {
+		t.Errorf("expected ODS date cell, got %v", v)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ds := NewDataset([]string{"Email", "Age", "Plan", "Joined"})
+	ds.Append([]any{"alice@example.com", "34", "pro", "2024-01-02"})
+	ds.Append([]any{"", "abc", "gold", "yesterday"})
+	ds.Append([]any{"bob@example", 210, "free", nil})
+
+	rules := []Rule{
+		RequiredRule("Email"),
+		PatternRule("Email", regexp.MustCompile(`^[^@]+@[^@]+\.[a-z]+$`)),
+		TypeRule("Age", TypeInt),
+		RangeRule("Age", 0, 130),
+		EnumRule("Plan", "free", "pro"),
+		TypeRule("Joined", TypeTime),
+		CustomRule("Plan", "lowercase", func(v any) bool { return v == strings.ToLower(valueString(v)) }),
+	}
+	report := ds.Validate(rules)
+	if report.Valid() {
+		t.Fatal("expected violations")
+	}
+	var got []string
+	for _, v := range report.Violations {
+		got = append(got, fmt.Sprintf("%d:%s:%s", v.Row, v.Column, v.Rule))
+	}
+	want := "1:Email:required 1:Age:type 1:Age:range 1:Plan:enum 1:Joined:type 2:Email:pattern 2:Age:range"
+	if strings.Join(got, " ") != want {
+		t.Errorf("unexpected violations %v", got)
+	}
+	if err := report.Err(); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+	if report.Dataset().Height() != len(report.Violations) {
+		t.Errorf("expected one report row per violation")
+	}
+
+	missing := ds.Validate([]Rule{RequiredRule("Phone")})
+	if len(missing.Violations) != 1 || missing.Violations[0].Row != -1 {
+		t.Errorf("expected a missing column violation, got %v", missing.Violations)
+	}
+	if ok := ds.Validate([]Rule{RequiredRule("Plan")}); !ok.Valid() || ok.Err() != nil {
+		t.Errorf("expected a valid report, got %v", ok.Violations)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	ds := NewDataset([]string{"Email", "Name"})
+	ds.Append([]any{"alice@example.com", "Alice"})
+	if err := ds.SetConstraint("Email", UniqueConstraint, NotNullConstraint, Pattern(regexp.MustCompile(`@`))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ds.SetConstraint("Phone", NotNullConstraint); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+
+	if err := ds.Append([]any{"alice@example.com", "Alias"}); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected a unique violation, got %v", err)
+	}
+	if err := ds.Insert(0, []any{nil, "Nobody"}); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected a not null violation, got %v", err)
+	}
+	if err := ds.Append([]any{"bob@example.com", "Bob"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ds.Set(1, 0, "bob"); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected a pattern violation, got %v", err)
+	}
+	if err := ds.Set(1, 0, "bob@example.com"); err != nil {
+		t.Errorf("expected a row to keep its own value, got %v", err)
+	}
+	if ds.Height() != 2 {
+		t.Errorf("expected rejected rows to be left out, got %d rows", ds.Height())
+	}
+
+	ds.CollectViolations(true)
+	if err := ds.Append([]any{"bob@example.com", "Robert"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	violations := ds.Violations()
+	if ds.Height() != 3 || len(violations) != 1 || violations[0].Row != 2 || violations[0].Rule != "unique" {
+		t.Errorf("expected a collected violation, got %v", violations)
+	}
+	ds.ClearViolations()
+	if len(ds.Violations()) != 0 {
+		t.Errorf("expected violations to be cleared")
+	}
+
+	ds.CollectViolations(false)
+	if err := ds.SetConstraint("Name", NewConstraint("short", func(v any) error {
+		if len(valueString(v)) > 5 {
+			return fmt.Errorf("%v is too long", v)
+		}
+		return nil
+	})); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected existing values to be checked, got %v", err)
+	}
+	if err := ds.Append([]any{"cid@example.com", "Cidney"}); err != nil {
+		t.Errorf("expected a rejected constraint not to be attached, got %v", err)
+	}
+
+	// Unique columns are indexed, and Set keeps the index current
+	if _, ok := ds.indexes["Email"]; !ok {
+		t.Errorf("expected the unique column to be indexed")
+	}
+	if err := ds.Set(0, 0, "ann@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ds.Append([]any{"alice@example.com", "Alice"}); err != nil {
+		t.Errorf("expected a replaced value to be free again, got %v", err)
+	}
+	if err := ds.Append([]any{"ann@example.com", "Ann"}); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected a unique violation for a value set in place, got %v", err)
+	}
+
+	ds.SetAlignment("Email", AlignRight)
+	ds.DeleteColByHeader("Email")
+	ds.AppendCol("Email", []any{"x", "x", "x", "x", "x"})
+	if _, ok := ds.constraints["Email"]; ok || ds.alignments["Email"] != AlignDefault {
+		t.Errorf("expected a deleted column's settings to be removed")
+	}
+	if err := ds.Set(0, 1, "x"); err != nil {
+		t.Errorf("expected a new column not to inherit constraints, got %v", err)
+	}
+}
+
+func TestConstraintsOnBulkChanges(t *testing.T) {
+	ds := NewDataset([]string{"ID", "Name"})
+	ds.Append([]any{1, "Alice"})
+	ds.Append([]any{2, nil})
+	ds.SetConstraint("ID", UniqueConstraint)
+	ds.SetConstraint("Name", NewConstraint("short", func(v any) error {
+		if len(valueString(v)) > 5 {
+			return fmt.Errorf("%v is too long", v)
+		}
+		return nil
+	}))
+	before := ds.Hash()
+
+	if err := ds.MapColumn("ID", func(any) any { return 7 }); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected MapColumn to reject duplicate values, got %v", err)
+	}
+	if err := ds.MapRows(func(row []any) []any { return []any{row[0], "Alexander"} }); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected MapRows to be checked, got %v", err)
+	}
+	if _, err := ds.UpdateWhere(func([]any) bool { return true }, "ID", 3); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected UpdateWhere to be checked, got %v", err)
+	}
+	if err := ds.FillNA("Nobody at all"); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected FillNA to be checked, got %v", err)
+	}
+	if err := ds.FillNAColumn("Name", "Nobody at all"); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected FillNAColumn to be checked, got %v", err)
+	}
+	other := NewDataset([]string{"ID", "Name"})
+	other.Append([]any{3, "Christopher"})
+	if err := ds.Merge(other, "ID", nil); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected Merge to be checked, got %v", err)
+	}
+	if ds.Hash() != before {
+		t.Errorf("expected rejected changes to leave the dataset unchanged")
+	}
+
+	// Values may swap places within a single change
+	if err := ds.MapColumn("ID", func(v any) any { return 3 - v.(int) }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ds.FillNAColumn("Name", "Bob"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCellComments(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Score"})
+	ds.Append([]any{"Bob", 72})
+	ds.Append([]any{"Alice", 91})
+	if err := ds.SetCellComment(1, 1, "Re-graded\nafter appeal"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ds.SetCellComment(5, 0, "x"); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+
+	sorted, _ := ds.SortByHeader("Name", false)
+	if sorted.CellComment(0, 1) != "Re-graded\nafter appeal" || sorted.CellComment(1, 1) != "" {
+		t.Errorf("expected the comment to move with its row when sorting")
+	}
+	ds.Insert(0, []any{"Cid", 60})
+	if ds.CellComment(2, 1) == "" {
+		t.Errorf("expected the comment to move with its row on Insert")
+	}
+	ds.Pop(0)
+
+	page, _ := ds.ExportString(FormatHTML)
+	if !strings.Contains(page, `<td title="Re-graded
+after appeal">91</td>`) {
+		t.Errorf("expected a title attribute:\n%s", page)
+	}
+
+	var buf bytes.Buffer
+	if err := ds.Export(FormatXLSX, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	comments, _ := f.GetComments("Sheet1")
+	if len(comments) != 1 || comments[0].Cell != "B3" || !strings.Contains(comments[0].Text, "Re-graded") {
+		t.Errorf("unexpected XLSX comments %+v", comments)
+	}
+
+	buf.Reset()
+	if err := ds.Export(FormatODS, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rc, err := zr.Open("content.xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := io.ReadAll(rc)
+	rc.Close()
+	if !strings.Contains(string(content), "Re-graded</p>") || !strings.Contains(string(content), "annotation") {
+		t.Errorf("expected an ODS annotation:\n%s", content)
+	}
+	imported, err := ImportODS(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := imported.Get(1, 1); fmt.Sprint(v) != "91" {
+		t.Errorf("expected the comment to stay out of the cell value, got %v", v)
+	}
+}
+
+func TestColumnTags(t *testing.T) {
+	ds := NewDataset([]string{"Name", "SSN", "Email", "City"})
+	ds.Append([]any{"Alice", "123-45-6789", "alice@example.com", "Oslo"})
+	if err := ds.TagColumn("SSN", "pii", "secret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ds.TagColumn("Email", "pii")
+	if err := ds.TagColumn("Phone", "pii"); err != ErrColumnNotFound {
+		t.Errorf("expected ErrColumnNotFound, got %v", err)
+	}
+	ds.UntagColumn("SSN", "secret")
+	if tags := ds.ColumnTags("SSN"); len(tags) != 1 || tags[0] != "pii" {
+		t.Errorf("unexpected column tags %v", tags)
+	}
+
+	pii := ds.SubsetByTag("pii")
+	if fmt.Sprint(pii.Headers()) != "[SSN Email]" || pii.Height() != 1 {
+		t.Errorf("unexpected subset %v", pii.Headers())
+	}
+	if tags := pii.ColumnTags("Email"); len(tags) != 1 {
+		t.Errorf("expected column tags to be kept in the subset, got %v", tags)
+	}
+
+	ds.RenameColumn("Email", "Mail")
+	ds.DropColumnsByTag("pii")
+	if fmt.Sprint(ds.Headers()) != "[Name City]" {
+		t.Errorf("unexpected headers after dropping %v", ds.Headers())
+	}
+	if row, _ := ds.Row(0); fmt.Sprint(row) != "[Alice Oslo]" {
+		t.Errorf("unexpected row %v", row)
+	}
+}
+
+func TestRowTags(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	ds.Append([]any{"Alice"}, "staff")
+	ds.Append([]any{"Bob"})
+
+	if err := ds.AddTag(1, "guest"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ds.AddTag(1, "guest")
+	ds.AddTag(0, "admin")
+	if tags, _ := ds.TagsAt(1); fmt.Sprint(tags) != "[guest]" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+	if ds.Filter("guest").Height() != 1 {
+		t.Errorf("expected an added tag to be filterable")
+	}
+	if err := ds.RemoveTag(0, "staff"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tags, _ := ds.TagsAt(0); fmt.Sprint(tags) != "[admin]" {
+		t.Errorf("unexpected tags after removal %v", tags)
+	}
+	if fmt.Sprint(ds.AllTags()) != "[admin guest]" {
+		t.Errorf("unexpected tag set %v", ds.AllTags())
+	}
+	if _, err := ds.TagsAt(2); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+	if err := ds.AddTag(-1, "x"); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+}
+
+func TestFilterAllAny(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	ds.Append([]any{"Alice"}, "admin", "active")
+	ds.Append([]any{"Bob"}, "user", "active")
+	ds.Append([]any{"Cid"}, "admin")
+	ds.Append([]any{"Dee"})
+
+	names := func(d *Dataset) string {
+		col, _ := d.Column(0)
+		return fmt.Sprint(col)
+	}
+	if got := names(ds.FilterAll("admin", "active")); got != "[Alice]" {
+		t.Errorf("unexpected FilterAll result %s", got)
+	}
+	if got := names(ds.FilterAny("admin", "user")); got != "[Alice Bob Cid]" {
+		t.Errorf("unexpected FilterAny result %s", got)
+	}
+	if ds.FilterAll().Height() != 4 || ds.FilterAny().Height() != 0 {
+		t.Errorf("unexpected results without tags")
+	}
+	if tags, _ := ds.FilterAll("active").TagsAt(1); fmt.Sprint(tags) != "[user active]" {
+		t.Errorf("expected tags to be kept, got %v", tags)
+	}
+}
+
+func TestNamedFormatters(t *testing.T) {
+	ds := NewDataset([]string{"Name"})
+	ds.AddNamedFormatter("upper", func(v any) any { return strings.ToUpper(valueString(v)) })
+	ds.AddFormatter(func(v any) any { return valueString(v) + "!" })
+	ds.AddNamedFormatter("quote", func(v any) any { return "'" + valueString(v) + "'" })
+
+	if fmt.Sprint(ds.Formatters()) != "[upper  quote]" {
+		t.Errorf("unexpected formatters %q", ds.Formatters())
+	}
+	if got := ds.ApplyFormatters("hi"); got != "'HI!'" {
+		t.Errorf("unexpected result %v", got)
+	}
+
+	ds.AddNamedFormatter("upper", func(v any) any { return strings.ToLower(valueString(v)) })
+	if got := ds.ApplyFormatters("Hi"); got != "'hi!'" {
+		t.Errorf("expected the formatter to be replaced in place, got %v", got)
+	}
+
+	copied := ds.Copy()
+	if !ds.RemoveFormatter("upper") || ds.RemoveFormatter("upper") || ds.RemoveFormatter("") {
+		t.Errorf("unexpected RemoveFormatter results")
+	}
+	if got := ds.ApplyFormatters("Hi"); got != "'Hi!'" {
+		t.Errorf("unexpected result after removal %v", got)
+	}
+	if len(copied.Formatters()) != 3 {
+		t.Errorf("expected the copy to keep its formatters, got %q", copied.Formatters())
+	}
+}
+
+func TestSyncDataset(t *testing.T) {
+	sds := NewSyncDataset([]string{"Worker", "Result"})
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				if err := sds.Append([]any{w, i}); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				sds.Height()
+			}
+		}()
+	}
+	wg.Wait()
+	if sds.Height() != 800 {
+		t.Errorf("expected 800 rows, got %d", sds.Height())
+	}
+
+	err := sds.Write(func(ds *Dataset) error {
+		return ds.AppendCol("Done", make([]any, ds.Height()))
+	})
+	if err != nil || sds.Width() != 3 {
+		t.Errorf("unexpected Write result %v, width %d", err, sds.Width())
+	}
+	var total int
+	sds.Read(func(ds *Dataset) {
+		col, _ := ds.Column(1)
+		for _, v := range col {
+			total += v.(int)
+		}
+	})
+	if total != 8*4950 {
+		t.Errorf("unexpected total %d", total)
+	}
+
+	snapshot := sds.Snapshot()
+	sds.Append([]any{0, 0, nil})
+	if snapshot.Height() != 800 {
+		t.Errorf("expected the snapshot to be independent, got %d rows", snapshot.Height())
+	}
+}
+
+func TestCopyOnWrite(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30}, "a")
+	ds.Append([]any{"Bob", 25})
+
+	copied := ds.Copy()
+	if &copied.data[0][0] != &ds.data[0][0] {
+		t.Fatal("expected Copy to share rows until a write")
+	}
+	copied.Set(0, 1, 31)
+	if v, _ := ds.Get(0, 1); v != 30 {
+		t.Errorf("expected the original to be unchanged, got %v", v)
+	}
+	if &copied.data[1][0] != &ds.data[1][0] {
+		t.Errorf("expected unchanged rows to stay shared")
+	}
+
+	filtered := ds.Filter("a")
+	ds.MapColumn("Name", func(v any) any { return strings.ToUpper(v.(string)) })
+	if v, _ := filtered.Get(0, 0); v != "Alice" {
+		t.Errorf("expected the filtered dataset to be unchanged, got %v", v)
+	}
+
+	sorted, _ := copied.SortByHeader("Age", false)
+	sorted.FillNAColumn("Name", "x")
+	sorted.UpdateWhere(func(row []any) bool { return true }, "Age", 0)
+	if v, _ := copied.Get(0, 1); v != 31 {
+		t.Errorf("expected the sorted source to be unchanged, got %v", v)
+	}
+	row, _ := copied.Pop(1)
+	row[0] = "Changed"
+	if v, _ := sorted.Get(0, 0); v != "Bob" {
+		t.Errorf("expected a popped row not to alias a copy, got %v", v)
+	}
+}
+
+func TestDiskDataset(t *testing.T) {
+	dir := t.TempDir()
+	d := NewDiskDataset([]string{"ID", "Name", "When"}, DiskOptions{MemoryRows: 2, Dir: dir})
+	when := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		if err := d.Append([]any{i, fmt.Sprintf("n%d", i), when}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := d.Append([]any{1, 2}); err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+	if err := d.Append([]any{struct{}{}, "x", nil}); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData for an unstorable cell, got %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 || d.Height() != 5 {
+		t.Fatalf("expected rows to spill to one file, got %d files and %d rows", len(files), d.Height())
+	}
+
+	var ids []any
+	d.Each(func(row []any) error {
+		ids = append(ids, row[0])
+		return nil
+	})
+	if fmt.Sprint(ids) != "[0 1 2 3 4]" {
+		t.Errorf("unexpected rows %v", ids)
+	}
+
+	var buf bytes.Buffer
+	if err := d.Export(FormatCSV, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "ID,Name,When\n0,n0,2024-05-06T00:00:00Z\n") || strings.Count(buf.String(), "\n") != 6 {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := d.Export(FormatNDJSON, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"ID":0,"Name":"n0","When":"2024-05-06T00:00:00Z"}`+"\n") {
+		t.Errorf("unexpected NDJSON:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := d.Export(FormatXLSX, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromXLSX, err := Import(FormatXLSX, &buf)
+	if err != nil || fromXLSX.Height() != 5 {
+		t.Errorf("unexpected XLSX import: %v", err)
+	}
+	if err := d.Export(FormatHTML, &buf); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+
+	ds, err := d.ToDataset()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := ds.Get(4, 2); v != when {
+		t.Errorf("expected typed cells to survive the disk, got %v", v)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected Close to remove the file")
+	}
+
+	csvDisk, err := ImportDiskCSV(strings.NewReader("a,b\n1,2\n3,4\n"), ',', true, DiskOptions{MemoryRows: 1, Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer csvDisk.Close()
+	buf.Reset()
+	csvDisk.Export(FormatNDJSON, &buf)
+	out := buf.String()
+	if out != "{\"a\":\"1\",\"b\":\"2\"}\n{\"a\":\"3\",\"b\":\"4\"}\n" {
+		t.Errorf("unexpected NDJSON from CSV:\n%s", out)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	if err := ds.Undo(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected ErrNoHistory without history, got %v", err)
+	}
+
+	ds.EnableHistory(3)
+	ds.Append([]any{"Bob", 25})
+	ds.Set(0, 1, 31)
+	ds.AppendCol("City", []any{"Paris", "Rome"})
+
+	ds.Undo()
+	if ds.Width() != 2 {
+		t.Errorf("expected the column to be removed, got width %d", ds.Width())
+	}
+	ds.Undo()
+	if v, _ := ds.Get(0, 1); v != 30 {
+		t.Errorf("expected 30 after undoing Set, got %v", v)
+	}
+	ds.Redo()
+	if v, _ := ds.Get(0, 1); v != 31 {
+		t.Errorf("expected 31 after redoing Set, got %v", v)
+	}
+
+	// A new change discards the redo history
+	ds.Pop(1)
+	if ds.CanRedo() {
+		t.Error("expected a change to discard the redo history")
+	}
+	if err := ds.Append([]any{"Carol"}); err == nil || ds.CanRedo() {
+		t.Fatalf("expected a failed change to go unrecorded, got %v", err)
+	}
+
+	// Only the last 3 changes are kept: Append, Set and Pop
+	for ds.CanUndo() {
+		ds.Undo()
+	}
+	if ds.Height() != 1 {
+		t.Errorf("expected the first Append to stay, got height %d", ds.Height())
+	}
+	ds.Redo()
+	if ds.Height() != 2 {
+		t.Errorf("expected 2 rows after redo, got %d", ds.Height())
+	}
+
+	ds.EnableHistory(0)
+	if ds.CanUndo() || ds.CanRedo() {
+		t.Error("expected disabling to discard the history")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30}, "a")
+	ds.Freeze()
+	if !ds.IsFrozen() {
+		t.Fatal("expected the dataset to be frozen")
+	}
+
+	_, popErr := ds.Pop(0)
+	errs := []error{
+		popErr,
+		ds.Append([]any{"Bob", 25}),
+		ds.Set(0, 1, 31),
+		ds.AppendCol("City", []any{"Paris"}),
+		ds.DeleteColByHeader("Age"),
+		ds.RenameColumn("Age", "Years"),
+		ds.AddTag(0, "b"),
+		ds.SetColumnFormat("Age", "0.0"),
+		ds.MapColumn("Name", func(v any) any { return "x" }),
+		ds.FillNA(0),
+	}
+	for i, err := range errs {
+		if !errors.Is(err, ErrFrozen) {
+			t.Errorf("change %d: expected ErrFrozen, got %v", i, err)
+		}
+	}
+
+	// Methods without an error result panic with ErrFrozen
+	changes := []func(){
+		func() { ds.SetTitle("Changed") },
+		func() { ds.Wipe() },
+		func() { ds.AddFormatter(func(v any) any { return v }) },
+		func() { ds.AppendSeparator("x") },
+		func() { ds.DropColumnsByTag("a") },
+		func() { ds.DeleteRowsWhere(func([]any) bool { return true }) },
+		func() { ds.EnableHistory(5) },
+		func() { ds.CollectViolations(true) },
+		func() { ds.AddDynamicColumn("Double", func([]any) any { return nil }) },
+	}
+	for i, change := range changes {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrFrozen) {
+					t.Errorf("change %d: expected a panic with ErrFrozen, got %v", i, err)
+				}
+			}()
+			change()
+		}()
+	}
+	if ds.Height() != 1 || ds.Title() != "" {
+		t.Errorf("expected the frozen dataset to be unchanged, got %d rows and title %q", ds.Height(), ds.Title())
+	}
+
+	copied := ds.Copy()
+	if copied.IsFrozen() || copied.Set(0, 1, 31) != nil {
+		t.Error("expected a copy to be modifiable")
+	}
+	if v, _ := ds.Get(0, 1); v != 30 {
+		t.Errorf("expected the frozen dataset to be unchanged, got %v", v)
+	}
+	if ds.Filter("a").IsFrozen() {
+		t.Error("expected a selection not to be frozen")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	when := time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)
+	a := NewDataset([]string{"ID", "Score", "When", "Tags"})
+	a.Append([]any{1, 2.5, when, []string{"x"}}, "a", "b")
+	a.Append([]any{int64(2), math.NaN(), nil, nil})
+
+	b := NewDataset([]string{"ID", "Score", "When", "Tags"})
+	b.Append([]any{1.0, float32(2.5), when.In(time.FixedZone("CET", 3600)), []string{"x"}}, "b", "a")
+	b.Append([]any{uint8(2), math.NaN(), nil, nil})
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Error("expected datasets with equal values to be equal")
+	}
+
+	b.SetTitle("Other")
+	b.AppendSeparator("End")
+	if a.Equal(b) || !a.EqualData(b) {
+		t.Error("expected the title and separators to matter only to Equal")
+	}
+
+	for _, v := range []any{"1", 1.5, uint64(1 << 63), nil} {
+		c := a.Copy()
+		c.Set(0, 0, v)
+		if a.EqualData(c) {
+			t.Errorf("expected 1 to differ from %T %v", v, v)
+		}
+	}
+	c := a.Copy()
+	c.SetHeaders([]string{"id", "Score", "When", "Tags"})
+	if a.EqualData(c) {
+		t.Error("expected different headers to differ")
+	}
+}
+
+func TestCachedColumnWidths(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Score"})
+	ds.Append([]any{"Al", 1.5})
+	ds.Append([]any{"Bo", 22.25})
+
+	rst, _ := ds.ExportString(FormatRST)
+	md, _ := ds.ExportString(FormatMarkdown)
+	if len(ds.widths.widths) != 2 {
+		t.Fatalf("expected widths cached for RST and Markdown, got %d", len(ds.widths.widths))
+	}
+	if again, _ := ds.ExportString(FormatRST); again != rst {
+		t.Errorf("expected the same RST from cached widths, got:\n%s", again)
+	}
+
+	ds.Set(0, 0, "Alexandra")
+	if md2, _ := ds.ExportString(FormatMarkdown); md2 == md || !strings.Contains(md2, "| Bo        |") {
+		t.Errorf("expected Set to widen the column, got:\n%s", md2)
+	}
+	ds.Append([]any{"Christopher", 3})
+	if out, _ := ds.ExportString(FormatCLI); !strings.Contains(out, "│ Bo          │") {
+		t.Errorf("expected Append to widen the column, got:\n%s", out)
+	}
+	ds.SetColumnFormat("Score", "%.4f")
+	if out, _ := ds.ExportString(FormatRST); !strings.Contains(out, "22.2500") {
+		t.Errorf("expected a new format to be measured, got:\n%s", out)
+	}
+
+	// Views of the dataset measure their own rows
+	var buf bytes.Buffer
+	ds.ExportWith(FormatMarkdown, &buf, ExportOptions{Limit: 1})
+	if !strings.Contains(buf.String(), "| Alexandra | 1.5000 |") {
+		t.Errorf("expected a view to measure only its rows, got:\n%s", buf.String())
+	}
+}
+
+func TestRowDict(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	ds.Append([]any{"Bob", 25})
+	ds.AddDynamicColumn("Adult", func(row []any) any { return row[1].(int) >= 18 })
+
+	m, err := ds.RowDict(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 3 || m["Name"] != "Bob" || m["Age"] != 25 || m["Adult"] != true {
+		t.Errorf("unexpected row: %v", m)
+	}
+	if _, err := ds.RowDict(2); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+	noHeaders := NewDataset(nil)
+	noHeaders.Append([]any{1})
+	if _, err := noHeaders.RowDict(0); err != ErrHeadersRequired {
+		t.Errorf("expected ErrHeadersRequired, got %v", err)
+	}
+}
+
+func TestSetRow(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30}, "a")
+	ds.Append([]any{"Bob", 25})
+	ds.InsertSeparator(1, "Others")
+	ds.SetCellComment(1, 0, "note")
+
+	if err := ds.SetRow(1, []any{"Carol", 41}, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if row, _ := ds.Row(1); row[0] != "Carol" || row[1] != 41 {
+		t.Errorf("unexpected row: %v", row)
+	}
+	if tags, _ := ds.TagsAt(1); len(tags) != 1 || tags[0] != "c" {
+		t.Errorf("expected the tags to be replaced, got %v", tags)
+	}
+	if !ds.HasSeparator(1) || ds.CellComment(1, 0) != "note" || ds.Height() != 2 {
+		t.Error("expected the separator and comment to stay in place")
+	}
+
+	if err := ds.SetRow(2, []any{"Dan", 1}); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+	if err := ds.SetRow(0, []any{"Dan"}); err != ErrInvalidDimensions {
+		t.Errorf("expected ErrInvalidDimensions, got %v", err)
+	}
+
+	ds.SetConstraint("Name", UniqueConstraint)
+	if err := ds.SetRow(0, []any{"Carol", 1}); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("expected ErrConstraintViolation, got %v", err)
+	}
+	if err := ds.SetRow(0, []any{"Alice", 31}); err != nil {
+		t.Errorf("expected a row to keep its own unique value, got %v", err)
+	}
+}
+
+func TestDeleteRows(t *testing.T) {
+	ds := NewDataset([]string{"N"})
+	for i := range 6 {
+		ds.Append([]any{i}, fmt.Sprint("t", i))
+	}
+	ds.SetCellComment(1, 0, "one")
+	ds.SetCellComment(4, 0, "four")
+
+	if err := ds.DeleteRows(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	col, _ := ds.Column(0)
+	if fmt.Sprint(col) != "[0 3 4 5]" {
+		t.Errorf("unexpected rows after DeleteRows: %v", col)
+	}
+	if ds.CellComment(2, 0) != "four" || ds.CellComment(1, 0) != "" {
+		t.Error("expected comments to follow their rows")
+	}
+	if err := ds.DeleteRows(2, 5); err != ErrInvalidRowIndex {
+		t.Errorf("expected ErrInvalidRowIndex, got %v", err)
+	}
+
+	n := ds.DeleteRowsWhere(func(row []any) bool { return row[0].(int)%2 == 1 })
+	col, _ = ds.Column(0)
+	if n != 2 || fmt.Sprint(col) != "[0 4]" {
+		t.Errorf("expected 2 odd rows removed, got %d and %v", n, col)
+	}
+	if tags, _ := ds.TagsAt(1); tags[0] != "t4" || ds.CellComment(1, 0) != "four" {
+		t.Errorf("expected tags and comments to follow their rows, got %v", tags)
+	}
+	if ds.DeleteRowsWhere(func(row []any) bool { return false }) != 0 {
+		t.Error("expected no rows removed")
+	}
+}
+
+func TestSeparatorsFollowRows(t *testing.T) {
+	ds := NewDataset([]string{"n"})
+	for i := range 6 {
+		ds.Append([]any{i})
+	}
+	ds.InsertSeparator(0, "top")
+	ds.InsertSeparator(2, "two")
+	ds.InsertSeparator(4, "four")
+	ds.AppendSeparator("end")
+	separators := func() string {
+		var parts []string
+		for i := 0; i <= ds.Height(); i++ {
+			if sep, ok := ds.GetSeparator(i); ok {
+				parts = append(parts, fmt.Sprintf("%d:%s", i, sep.Text))
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+
+	ds.Lpush([]any{-1})
+	ds.Insert(3, []any{10})
+	if got := separators(); got != "1:top 4:two 6:four 8:end" {
+		t.Errorf("unexpected separators after inserting: %s", got)
+	}
+	ds.Pop(4)
+	if got := separators(); got != "1:top 5:four 7:end" {
+		t.Errorf("unexpected separators after popping: %s", got)
+	}
+	ds.DeleteRows(0, 2)
+	if got := separators(); got != "3:four 5:end" {
+		t.Errorf("unexpected separators after deleting rows: %s", got)
+	}
+	ds.DeleteRowsWhere(func(row []any) bool { return row[0].(int) < 4 })
+	if got := separators(); got != "1:four 3:end" || ds.Height() != 3 {
+		t.Errorf("unexpected separators after deleting matching rows: %s", got)
+	}
+	ds.Insert(2, []any{6})
+	if got := separators(); got != "1:four 4:end" {
+		t.Errorf("expected trailing separator to stay last, got %s", got)
+	}
+	ds.Insert(ds.Height(), []any{7})
+	ds.AppendSeparator("again")
+	ds.Append([]any{8})
+	if got := separators(); got != "1:four 4:end 5:again" {
+		t.Errorf("expected rows added at the end to follow a trailing separator, got %s", got)
+	}
+}
+
+func TestSeparatorsThroughTransformations(t *testing.T) {
+	ds := NewDataset([]string{"name", "n"})
+	ds.AppendTagged([]any{"c", 3}, []string{"keep"})
+	ds.AppendTagged([]any{"a", 1}, nil)
+	ds.AppendTagged([]any{"b", 2}, []string{"keep"})
+	ds.InsertSeparator(0, "first")
+	ds.InsertSeparator(2, "third")
+	ds.AppendSeparator("end")
+	separators := func(ds *Dataset) string {
+		var parts []string
+		for i := 0; i <= ds.Height(); i++ {
+			if sep, ok := ds.GetSeparator(i); ok {
+				parts = append(parts, fmt.Sprintf("%d:%s", i, sep.Text))
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+
+	sorted, _ := ds.SortByHeader("n", false)
+	if got := separators(sorted); got != "1:third 2:first 3:end" {
+		t.Errorf("unexpected separators after sorting: %s", got)
+	}
+	if got := separators(ds.Filter("keep")); got != "0:first 1:third 2:end" {
+		t.Errorf("unexpected separators after filtering: %s", got)
+	}
+	if got := separators(ds.Head(2)); got != "0:first" {
+		t.Errorf("expected trailing separator dropped without the last row, got %s", got)
+	}
+	subset, _ := ds.Subset([]string{"n"})
+	if got := separators(subset); got != "0:first 2:third 3:end" {
+		t.Errorf("unexpected separators after subset: %s", got)
+	}
+	stacked, _ := ds.StackRows(ds)
+	if got := separators(stacked); got != "0:first 2:third 3:first 5:third 6:end" {
+		t.Errorf("unexpected separators after stacking: %s", got)
+	}
+
+	ds.SetCellComment(2, 0, "checked")
+	ds.Append([]any{"b", 2}, "keep")
+	ds.Append([]any{nil, 4})
+	for name, tc := range map[string]struct {
+		got  *Dataset
+		seps string
+	}{
+		"RemoveDuplicates": {ds.RemoveDuplicates(), "0:first 2:third"},
+		"DropNA":           {ds.DropNA(), "0:first 2:third 3:end"},
+	} {
+		if tc.got.Height() != 4 || tc.got.CellComment(2, 0) != "checked" || fmt.Sprint(tc.got.tags[2]) != "[keep]" {
+			t.Errorf("expected %s to keep rows, tags and comments as Filter does", name)
+		}
+		if got := separators(tc.got); got != tc.seps {
+			t.Errorf("unexpected separators after %s: %s", name, got)
+		}
+	}
+}
+
+func TestDynamicColumnPositions(t *testing.T) {
+	ds := NewDataset([]string{"First", "Last"})
+	ds.Append([]any{"Alice", "Smith"})
+	ds.AddDynamicColumn("Upper", func(row []any) any { return strings.ToUpper(row[0].(string)) })
+	if err := ds.AddDynamicColumnAt(1, "Initial", func(row []any) any { return row[0].(string)[:1] }); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(ds.Records()[0]); got != "[Alice A Smith ALICE]" {
+		t.Errorf("unexpected record: %s", got)
+	}
+
+	// Dynamic columns keep their place as stored columns change
+	ds.InsertCol(2, "Middle", []any{"B"})
+	ds.AppendCol("Age", []any{30})
+	ds.DeleteColByHeader("Last")
+	if got := fmt.Sprint(ds.Records()[0]); got != "[Alice A B 30 ALICE]" {
+		t.Errorf("unexpected record after adding columns: %s", got)
+	}
+	if err := ds.AddDynamicColumnAt(0, "Age", nil); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData for a stored header, got %v", err)
+	}
+	ds.AddDynamicColumn("Age", nil)
+	if ds.dynamicIndex("Age") != -1 || ds.Width() != 5 {
+		t.Errorf("expected AddDynamicColumn to ignore a stored header, got width %d", ds.Width())
+	}
+	if err := ds.AddDynamicColumnAt(6, "X", nil); err != ErrInvalidColumnIndex {
+		t.Errorf("expected ErrInvalidColumnIndex, got %v", err)
+	}
+
+	out, _ := ds.ExportString(FormatTablib)
+	got, err := ImportString(FormatTablib, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.dynamicIndex("Initial") != 0 || got.dynamicCols[0].before != 1 {
+		t.Errorf("expected dynamic column positions to round-trip, got %+v", got.dynamicCols)
+	}
+
+	if err := ds.MaterializeDynamicColumns(); err != nil {
+		t.Fatal(err)
+	}
+	if h := ds.Headers(); fmt.Sprint(h) != "[First Initial Middle Age Upper]" || len(ds.dynamicCols) != 0 {
+		t.Errorf("unexpected headers after materializing: %v", h)
+	}
+	ds.Set(0, 0, "Bob")
+	if v, _ := ds.Get(0, 1); v != "A" {
+		t.Errorf("expected materialized values to be stored, got %v", v)
+	}
+}
+
+func TestDynamicColumnWidth(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Score"})
+	ds.Append([]any{"Bob", 2})
+	ds.Append([]any{"Alice", 1})
+	ds.AddDynamicColumnAt(1, "Len", func(row []any) any { return len(row[0].(string)) })
+	ds.AddDynamicColumn("Double", func(row []any) any { return len(row[0].(string)) * 2 })
+
+	if ds.Width() != 4 || ds.Width() != len(ds.Records()[0]) {
+		t.Errorf("expected width 4 to match records, got %d", ds.Width())
+	}
+	if h := fmt.Sprint(ds.Headers()); h != "[Name Len Score Double]" {
+		t.Errorf("unexpected headers: %s", h)
+	}
+	if col, _ := ds.Column(1); fmt.Sprint(col) != "[3 5]" {
+		t.Errorf("unexpected dynamic column: %v", col)
+	}
+	if col, _ := ds.ColumnByHeader("Double"); fmt.Sprint(col) != "[6 10]" {
+		t.Errorf("unexpected dynamic column by header: %v", col)
+	}
+	if v, _ := ds.Get(1, 2); v != 1 {
+		t.Errorf("expected stored value after a dynamic column, got %v", v)
+	}
+	if err := ds.Set(0, 1, 9); err != ErrInvalidColumnIndex {
+		t.Errorf("expected ErrInvalidColumnIndex setting a dynamic column, got %v", err)
+	}
+	if err := ds.SetCellComment(0, 3, "doubled"); err != nil || ds.CellComment(0, 3) != "doubled" {
+		t.Errorf("expected comment on a dynamic column, got %v", err)
+	}
+
+	sorted, err := ds.Sort(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row, _ := sorted.Row(0); row[0] != "Bob" {
+		t.Errorf("expected sort by dynamic column, got %v", row)
+	}
+
+	// New columns go where they are inserted among the dynamic ones
+	ds.InsertCol(2, "Rank", []any{"b", "a"})
+	ds.AppendCol("Team", []any{"x", "y"})
+	if h := fmt.Sprint(ds.Headers()); h != "[Name Len Rank Score Team Double]" {
+		t.Errorf("unexpected headers after inserting: %s", h)
+	}
+	if err := ds.DeleteCol(1); err != nil || ds.Width() != 5 || ds.dynamicIndex("Len") != -1 {
+		t.Errorf("expected dynamic column deleted, got %v", err)
+	}
+	if got := fmt.Sprint(ds.Records()[0]); got != "[Bob b 2 x 6]" {
+		t.Errorf("unexpected record: %s", got)
+	}
+}
+
+func TestDynamicColumnsInExports(t *testing.T) {
+	ds := NewDataset([]string{"Name", "Age"})
+	ds.Append([]any{"Alice", 30})
+	ds.AddDynamicColumnAt(1, "Initial", func(row []any) any { return row[0].(string)[:1] })
+	ds.AddDynamicColumn("Older", func(row []any) any { return row[1].(int) + 1 })
+
+	csv, _ := ds.ExportString(FormatCSV)
+	if csv != "Name,Initial,Age,Older\nAlice,A,30,31\n" {
+		t.Errorf("unexpected CSV:\n%s", csv)
+	}
+	for _, format := range []Format{FormatHTML, FormatMarkdown, FormatCLI, FormatSQL, FormatLatex} {
+		out, err := ds.ExportString(format)
+		if err != nil || !strings.Contains(out, "Initial") || !strings.Contains(out, "31") {
+			t.Errorf("expected dynamic columns in %s, got %v:\n%s", format, err, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	ds.ExportMarkdown(&buf, MarkdownOptions{})
+	if !strings.Contains(buf.String(), "| Alice | A       | 30  | 31    |") {
+		t.Errorf("expected ExportMarkdown to include dynamic columns, got:\n%s", buf.String())
+	}
+	buf.Reset()
+	ds.ExportColumns(FormatCSV, &buf, []string{"Older", "Name"})
+	if buf.String() != "Older,Name\n31,Alice\n" {
+		t.Errorf("expected dynamic columns to be selectable, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	ds.Export(FormatXLSX, &buf)
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f.GetCellValue("Sheet1", "D2"); v != "31" {
+		t.Errorf("expected the dynamic column in XLSX, got %q", v)
+	}
+
+	book := NewDatabook()
+	book.AddSheet(ds)
+	if out, _ := book.ExportString(FormatCSV); !strings.Contains(out, "Alice,A,30,31") {
+		t.Errorf("expected dynamic columns in databook exports, got:\n%s", out)
+	}
+
+	// The native format keeps them dynamic
+	native, _ := ds.ExportString(FormatTablib)
+	got, _ := ImportString(FormatTablib, native)
+	if len(got.headers) != 2 || len(got.dynamicCols) != 2 {
+		t.Errorf("expected 2 stored and 2 dynamic columns, got %d and %d", len(got.headers), len(got.dynamicCols))
+	}
+}
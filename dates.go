@@ -0,0 +1,196 @@
+package tablib
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultDateLayouts are the layouts tried by ParseDates when none are
+// given.
+var defaultDateLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// ParseDates converts the string cells of the column with the specified
+// header to time.Time, trying each layout in turn; without layouts, RFC
+// 3339, "2006-01-02 15:04:05" and "2006-01-02" are tried. Times without a
+// zone are in UTC. Empty strings become nil, and other values are left as
+// they are. If a string matches no layout, ParseDates returns an error
+// wrapping ErrInvalidData and leaves the column unchanged.
+//
+// Exports render time.Time cells natively: ISO 8601 text in CSV, JSON and
+// text formats, date cells in XLSX and ODS, DATE or TIMESTAMP literals in
+// SQL, and 'D' fields in DBF for date columns. A date column is one with a
+// date-only column format such as "date", or, without a time format, one
+// whose times all fall at midnight.
+func (ds *Dataset) ParseDates(header string, layouts ...string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return ErrColumnNotFound
+	}
+	if len(layouts) == 0 {
+		layouts = defaultDateLayouts
+	}
+
+	parsed := make([]any, len(ds.data))
+	for i, row := range ds.data {
+		s, ok := row[col].(string)
+		if !ok {
+			parsed[i] = row[col]
+			continue
+		}
+		t, ok := parseTime(s, layouts)
+		if !ok {
+			return fmt.Errorf("%w: row %d: cannot parse %q as a time", ErrInvalidData, i, s)
+		}
+		parsed[i] = t
+	}
+	ds.record()
+	ds.ownRows()
+	for i, row := range ds.data {
+		row[col] = parsed[i]
+	}
+	ds.dropIndexes()
+	return nil
+}
+
+// parseTime parses s with the first matching layout, returning nil for
+// blank strings.
+func parseTime(s string, layouts []string) (any, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, true
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// isDateColumn reports whether column col holds dates without a time of
+// day: every non-nil value is a time.Time, and either the column format is
+// a date-only time format, or the column has no time format and holds at
+// least one time, all at midnight.
+func (ds *Dataset) isDateColumn(col int) bool {
+	midnight, found := true, false
+	for _, row := range ds.data {
+		switch v := row[col].(type) {
+		case nil:
+		case time.Time:
+			found = true
+			if v.Hour() != 0 || v.Minute() != 0 || v.Second() != 0 || v.Nanosecond() != 0 {
+				midnight = false
+			}
+		default:
+			return false
+		}
+	}
+	if nf, ok := ds.columnNumberFormat(col); ok && nf.kind == numberFormatTime {
+		return nf.dateOnly
+	}
+	return found && midnight
+}
+
+// layoutElement is a Go time layout element and its spreadsheet
+// equivalents: an XLSX number format code and an ODS data style element.
+type layoutElement struct {
+	layout  string
+	code    string
+	part    string // ODS element, or "" for elements spreadsheets cannot show
+	long    bool   // ODS number:style="long"
+	textual bool   // ODS number:textual="true"
+	literal bool   // literal text rather than an element
+}
+
+// layoutElements lists the elements of Go time layouts, longer elements
+// before their prefixes.
+var layoutElements = []layoutElement{
+	{layout: "January", code: "mmmm", part: "month", long: true, textual: true},
+	{layout: "Monday", code: "dddd", part: "day-of-week", long: true},
+	{layout: "2006", code: "yyyy", part: "year", long: true},
+	{layout: "Jan", code: "mmm", part: "month", textual: true},
+	{layout: "Mon", code: "ddd", part: "day-of-week"},
+	{layout: "MST"},
+	{layout: "Z07:00"},
+	{layout: "Z0700"},
+	{layout: "Z07"},
+	{layout: "-07:00"},
+	{layout: "-0700"},
+	{layout: "-07"},
+	{layout: "01", code: "mm", part: "month", long: true},
+	{layout: "02", code: "dd", part: "day", long: true},
+	{layout: "_2", code: "d", part: "day"},
+	{layout: "03", code: "hh", part: "hours", long: true},
+	{layout: "04", code: "mm", part: "minutes", long: true},
+	{layout: "05", code: "ss", part: "seconds", long: true},
+	{layout: "06", code: "yy", part: "year"},
+	{layout: "15", code: "hh", part: "hours", long: true},
+	{layout: "PM", code: "AM/PM", part: "am-pm"},
+	{layout: "pm", code: "am/pm", part: "am-pm"},
+	{layout: "1", code: "m", part: "month"},
+	{layout: "2", code: "d", part: "day"},
+	{layout: "3", code: "h", part: "hours"},
+	{layout: "4", code: "m", part: "minutes"},
+	{layout: "5", code: "s", part: "seconds"},
+}
+
+// splitLayout splits a Go time layout into elements and literal text.
+// Fractional seconds and zones, which spreadsheet formats cannot show, are
+// returned as elements without a code or part.
+func splitLayout(layout string) []layoutElement {
+	var elems []layoutElement
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			elems = append(elems, layoutElement{layout: literal.String(), literal: true})
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(layout); {
+		// Fractional seconds such as ".000" or ",999"
+		if (layout[i] == '.' || layout[i] == ',') && i+1 < len(layout) && (layout[i+1] == '0' || layout[i+1] == '9') {
+			j := i + 1
+			for j < len(layout) && layout[j] == layout[i+1] {
+				j++
+			}
+			flush()
+			elems = append(elems, layoutElement{layout: layout[i:j]})
+			i = j
+			continue
+		}
+		matched := false
+		for _, e := range layoutElements {
+			if strings.HasPrefix(layout[i:], e.layout) {
+				flush()
+				elems = append(elems, e)
+				i += len(e.layout)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			literal.WriteByte(layout[i])
+			i++
+		}
+	}
+	flush()
+	return elems
+}
+
+// spreadsheetDateFormat converts a Go time layout to a spreadsheet number
+// format code, dropping zones and fractional seconds.
+func spreadsheetDateFormat(layout string) string {
+	var sb strings.Builder
+	for _, e := range splitLayout(layout) {
+		if e.literal {
+			sb.WriteString(spreadsheetLiteral(e.layout))
+		} else {
+			sb.WriteString(e.code)
+		}
+	}
+	return sb.String()
+}
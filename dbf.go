@@ -5,8 +5,17 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
 )
 
 func init() {
@@ -16,7 +25,7 @@ func init() {
 
 // DBF file structure constants
 const (
-	dbfVersion        = 0x03 // dBASE III
+	dbfVersion          = 0x03 // dBASE III
 	dbfHeaderTerminator = 0x0D
 	dbfRecordDeleted    = 0x2A // '*'
 	dbfRecordActive     = 0x20 // ' '
@@ -30,119 +39,270 @@ const (
 	dbfFieldTypeLogical = 'L' // Logical
 	dbfFieldTypeDate    = 'D' // Date
 	dbfFieldTypeFloat   = 'F' // Float
+	dbfFieldTypeMemo    = 'M' // Memo
+
+	dbfFieldTypeNullFlags = '0' // Visual FoxPro _NullFlags system field
+)
+
+// Visual FoxPro field flags
+const (
+	dbfVFPFieldSystem   = 0x01 // hidden system field such as _NullFlags
+	dbfVFPFieldNullable = 0x02 // field may be null
+	dbfVFPFieldBinary   = 0x04 // binary data, not translated between code pages
+
+	dbfVFPNullFlagsName = "_NullFlags"
 )
 
 // dbfHeader represents the DBF file header
 type dbfHeader struct {
-	Version       byte
-	Year          byte
-	Month         byte
-	Day           byte
-	RecordCount   uint32
-	HeaderSize    uint16
-	RecordSize    uint16
-	Reserved      [20]byte
+	Version     byte
+	Year        byte
+	Month       byte
+	Day         byte
+	RecordCount uint32
+	HeaderSize  uint16
+	RecordSize  uint16
+	Reserved    [20]byte
 }
 
 // dbfFieldDescriptor represents a field descriptor in DBF
 type dbfFieldDescriptor struct {
-	Name          [11]byte
-	Type          byte
-	Reserved1     [4]byte
-	Length        byte
-	DecimalCount  byte
-	Reserved2     [14]byte
+	Name         [11]byte
+	Type         byte
+	Reserved1    [4]byte
+	Length       byte
+	DecimalCount byte
+	Reserved2    [14]byte
+}
+
+// DBFOptions configures DBF export behavior.
+type DBFOptions struct {
+	// Memo receives the dBASE III memo (.dbt) stream. When set, text columns
+	// with values longer than 254 characters are written as memo ('M') fields;
+	// otherwise such values are truncated.
+	Memo io.Writer
+
+	// CodePage selects the character encoding of text values and is recorded
+	// in the header's language driver byte. Zero writes UTF-8 bytes unmarked.
+	CodePage DBFCodePage
+
+	// Version selects the DBF flavor written. It controls the version byte,
+	// the header layout and the memo file format (.dbt or .fpt).
+	Version DBFVersion
 }
 
+// DBFVersion selects the DBF file flavor written by the exporter.
+type DBFVersion int
+
+const (
+	// DBFVersionDBaseIII writes dBASE III tables (version byte 0x03, or
+	// 0x83 with a .dbt memo file). This is the default.
+	DBFVersionDBaseIII DBFVersion = iota
+	// DBFVersionDBaseIV writes dBASE IV tables (version byte 0x03, or 0x8B
+	// with a dBASE IV .dbt memo file).
+	DBFVersionDBaseIV
+	// DBFVersionVisualFoxPro writes Visual FoxPro tables (version byte 0x30)
+	// with field displacements, field flags, the 263-byte database container
+	// backlink and an .fpt memo file. Columns holding nil are flagged
+	// nullable, with their nulls recorded in a _NullFlags system field, and
+	// memo fields are flagged binary.
+	DBFVersionVisualFoxPro
+)
+
+// versionByte returns the header version byte for the flavor.
+func (v DBFVersion) versionByte(hasMemo bool) byte {
+	switch {
+	case v == DBFVersionVisualFoxPro:
+		return dbfVersionVFP
+	case hasMemo && v == DBFVersionDBaseIV:
+		return dbfVersionDBaseIVMemo
+	case hasMemo:
+		return dbfVersionMemo
+	}
+	return dbfVersion
+}
+
+// DBFImportOptions configures DBF import behavior.
+type DBFImportOptions struct {
+	// Memo supplies the memo (.dbt or .fpt) stream that accompanies the table.
+	// Without it, memo fields are returned as their raw block references.
+	Memo io.Reader
+
+	// CodePage overrides the code page recorded in the file's language
+	// driver byte. Zero uses the file's own setting.
+	CodePage DBFCodePage
+
+	// Typed converts numeric ('N', 'F') fields to int64 or float64, logical
+	// ('L') fields to bool and date ('D') fields to time.Time. Blank values
+	// become nil. When false, every field is returned as a trimmed string.
+	// Either way, values marked null in a Visual FoxPro _NullFlags field are
+	// nil, and system fields are not imported as columns.
+	Typed bool
+}
+
+const (
+	dbfMaxCharLength = 254
+	dbfMemoLength    = 10  // memo block references are stored as 10 ASCII digits
+	dbfMemoBlockSize = 512 // dBASE memo block size
+	dbfVersionMemo   = 0x83
+
+	dbfVersionDBaseIVMemo = 0x8B
+	dbfVersionVFP         = 0x30
+	dbfVFPMemoLength      = 4   // Visual FoxPro memo references are 4-byte integers
+	dbfVFPMemoBlockSize   = 64  // Visual FoxPro default .fpt block size
+	dbfVFPBacklinkSize    = 263 // database container path after the field descriptors
+	dbfVFPTableFlagMemo   = 0x02
+
+	// dbfTableFlagsOffset is the index of the table flags byte (header
+	// offset 28) within dbfHeader.Reserved.
+	dbfTableFlagsOffset = 28 - 12
+
+	// dbfLanguageDriverOffset is the index of the language driver byte
+	// (header offset 29) within dbfHeader.Reserved.
+	dbfLanguageDriverOffset = 29 - 12
+
+	// dbfFieldFlagsOffset is the index of the field flags byte (descriptor
+	// offset 18) within dbfFieldDescriptor.Reserved2.
+	dbfFieldFlagsOffset = 18 - 18
+)
+
 func exportDBF(ds *Dataset, w io.Writer) error {
+	return exportDBFWithOptions(ds, w, DBFOptions{})
+}
+
+// ExportDBF exports the Dataset to DBF format with custom options.
+func (ds *Dataset) ExportDBF(w io.Writer, opts DBFOptions) error {
+	return exportDBFWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+func exportDBFWithOptions(ds *Dataset, w io.Writer, opts DBFOptions) error {
 	if len(ds.headers) == 0 {
 		return ErrHeadersRequired
 	}
 
+	// Encode all values up front so field lengths are measured in bytes of
+	// the target code page
+	encode := opts.CodePage.encoder()
+	dateCols := make([]bool, len(ds.headers))
+	for i := range dateCols {
+		dateCols[i] = ds.isDateColumn(i)
+	}
+	cells := make([][]string, len(ds.data))
+	for r, row := range ds.data {
+		cells[r] = make([]string, len(row))
+		for i, v := range row {
+			fieldType := byte(dbfFieldTypeChar)
+			if dateCols[i] {
+				fieldType = dbfFieldTypeDate
+			}
+			cells[r][i] = dbfFieldValue(v, fieldType, 0, encode)
+		}
+	}
+
 	// Calculate field descriptors
 	fields := make([]dbfFieldDescriptor, len(ds.headers))
 	fieldLengths := make([]int, len(ds.headers))
+	hasMemo := false
 
 	// Determine field lengths by scanning all data
 	for i, header := range ds.headers {
 		// Start with header length
 		maxLen := len(header)
-		if maxLen > 254 {
-			maxLen = 254
+		if maxLen > dbfMaxCharLength {
+			maxLen = dbfMaxCharLength
 		}
 		fieldLengths[i] = maxLen
 
 		// Check all values
-		for _, row := range ds.data {
+		longest := 0
+		for _, row := range cells {
 			if i < len(row) {
-				valLen := len(fmt.Sprintf("%v", row[i]))
-				if valLen > fieldLengths[i] {
-					fieldLengths[i] = valLen
+				valLen := len(row[i])
+				if valLen > longest {
+					longest = valLen
 				}
 			}
 		}
+		if longest > fieldLengths[i] {
+			fieldLengths[i] = longest
+		}
 
 		// Ensure minimum length and maximum
 		if fieldLengths[i] < 1 {
 			fieldLengths[i] = 1
 		}
-		if fieldLengths[i] > 254 {
-			fieldLengths[i] = 254
+		fieldType := byte(dbfFieldTypeChar) // Other fields as character for simplicity
+		if dateCols[i] {
+			fieldType = dbfFieldTypeDate
+			fieldLengths[i] = 8
+		} else if fieldLengths[i] > dbfMaxCharLength {
+			fieldLengths[i] = dbfMaxCharLength
+			if opts.Memo != nil {
+				fieldType = dbfFieldTypeMemo
+				fieldLengths[i] = dbfMemoLength
+				if opts.Version == DBFVersionVisualFoxPro {
+					fieldLengths[i] = dbfVFPMemoLength
+				}
+				hasMemo = true
+			}
 		}
 
 		// Create field descriptor
 		var fd dbfFieldDescriptor
-		name := header
-		if len(name) > 10 {
-			name = name[:10]
-		}
-		copy(fd.Name[:], strings.ToUpper(name))
-		fd.Type = dbfFieldTypeChar // All fields as character for simplicity
+		copy(fd.Name[:], dbfFieldName(header, encode))
+		fd.Type = fieldType
 		fd.Length = byte(fieldLengths[i])
 		fd.DecimalCount = 0
+		if opts.Version == DBFVersionVisualFoxPro {
+			if fieldType == dbfFieldTypeMemo {
+				fd.Reserved2[dbfFieldFlagsOffset] |= dbfVFPFieldBinary
+			}
+			if slices.ContainsFunc(ds.data, func(row []any) bool { return row[i] == nil }) {
+				fd.Reserved2[dbfFieldFlagsOffset] |= dbfVFPFieldNullable
+			}
+		}
 		fields[i] = fd
 	}
 
+	// Visual FoxPro marks null values of nullable fields in a trailing
+	// _NullFlags system field
+	if nullable := dbfNullableCount(fields); nullable > 0 {
+		var fd dbfFieldDescriptor
+		copy(fd.Name[:], dbfVFPNullFlagsName)
+		fd.Type = dbfFieldTypeNullFlags
+		fd.Length = byte((nullable + 7) / 8)
+		fd.Reserved2[dbfFieldFlagsOffset] = dbfVFPFieldSystem | dbfVFPFieldBinary
+		fields = append(fields, fd)
+		fieldLengths = append(fieldLengths, int(fd.Length))
+	}
+
 	// Calculate record size (1 byte for deletion flag + sum of field lengths)
 	recordSize := 1
 	for _, l := range fieldLengths {
 		recordSize += l
 	}
 
-	// Calculate header size (32 bytes header + 32 bytes per field + 1 byte terminator)
-	headerSize := 32 + (32 * len(fields)) + 1
-
 	// Create header
-	now := time.Now()
 	header := dbfHeader{
-		Version:     dbfVersion,
-		Year:        byte(now.Year() - 1900),
-		Month:       byte(now.Month()),
-		Day:         byte(now.Day()),
+		Version:     opts.Version.versionByte(hasMemo),
 		RecordCount: uint32(len(ds.data)),
-		HeaderSize:  uint16(headerSize),
 		RecordSize:  uint16(recordSize),
 	}
+	dbfStamp(&header, time.Now())
+	header.Reserved[dbfLanguageDriverOffset] = byte(opts.CodePage)
 
 	var buf bytes.Buffer
-
-	// Write header
-	if err := binary.Write(&buf, binary.LittleEndian, &header); err != nil {
-		return err
+	var memo *dbfMemoWriter
+	if hasMemo {
+		memo = newDBFMemoWriter(opts.Version)
 	}
 
-	// Write field descriptors
-	for _, fd := range fields {
-		if err := binary.Write(&buf, binary.LittleEndian, &fd); err != nil {
-			return err
-		}
+	if err := writeDBFHeader(&buf, &header, fields, opts.Version); err != nil {
+		return err
 	}
 
-	// Write header terminator
-	buf.WriteByte(dbfHeaderTerminator)
-
 	// Write records
-	for _, row := range ds.data {
+	for r, row := range cells {
 		// Write deletion flag (space = active)
 		buf.WriteByte(dbfRecordActive)
 
@@ -150,25 +310,205 @@ func exportDBF(ds *Dataset, w io.Writer) error {
 		for i, l := range fieldLengths {
 			var val string
 			if i < len(row) {
-				val = fmt.Sprintf("%v", row[i])
+				val = row[i]
 			}
-			// Pad or truncate to field length
-			if len(val) > l {
-				val = val[:l]
+			if fields[i].Type == dbfFieldTypeNullFlags {
+				buf.Write(dbfNullFlags(fields, ds.data[r], l))
+				continue
 			}
-			val = fmt.Sprintf("%-*s", l, val)
-			buf.WriteString(val)
+			if fields[i].Type == dbfFieldTypeMemo {
+				buf.Write(memo.ref(memo.add(val), l))
+				continue
+			}
+			// Pad or truncate to field length
+			buf.WriteString(dbfPad(val, l, fields[i].Type))
 		}
 	}
 
 	// Write EOF marker
 	buf.WriteByte(dbfEOF)
 
-	_, err := w.Write(buf.Bytes())
-	return err
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if memo != nil {
+		_, err := opts.Memo.Write(memo.bytes())
+		return err
+	}
+	return nil
+}
+
+// writeDBFHeader writes the table header, field descriptors and header
+// terminator, filling in the header size. Visual FoxPro tables additionally
+// get field displacements, the memo table flag and an empty database
+// container backlink; their field flags are set by the caller.
+func writeDBFHeader(buf *bytes.Buffer, header *dbfHeader, fields []dbfFieldDescriptor, version DBFVersion) error {
+	headerSize := 32 + (32 * len(fields)) + 1
+	if version == DBFVersionVisualFoxPro {
+		headerSize += dbfVFPBacklinkSize
+		displacement := uint32(1)
+		for i := range fields {
+			binary.LittleEndian.PutUint32(fields[i].Reserved1[:], displacement)
+			displacement += uint32(fields[i].Length)
+			if fields[i].Type == dbfFieldTypeMemo {
+				header.Reserved[dbfTableFlagsOffset] |= dbfVFPTableFlagMemo
+			}
+		}
+	}
+	header.HeaderSize = uint16(headerSize)
+
+	if err := binary.Write(buf, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	for _, fd := range fields {
+		if err := binary.Write(buf, binary.LittleEndian, &fd); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(dbfHeaderTerminator)
+	if version == DBFVersionVisualFoxPro {
+		buf.Write(make([]byte, dbfVFPBacklinkSize))
+	}
+	return nil
+}
+
+// dbfMemoWriter accumulates a memo file in the layout of a DBF flavor. Each
+// memo starts on a block boundary:
+//   - dBASE III (.dbt): 512-byte blocks, text terminated by two 0x1A bytes.
+//   - dBASE IV (.dbt): 512-byte blocks, each memo prefixed with FF FF 08 00
+//     and its little-endian length including the 8-byte prefix.
+//   - Visual FoxPro (.fpt): 64-byte blocks, each memo prefixed with a
+//     big-endian type (1 = text) and length.
+type dbfMemoWriter struct {
+	version   DBFVersion
+	blockSize int
+	buf       bytes.Buffer
+}
+
+func newDBFMemoWriter(version DBFVersion) *dbfMemoWriter {
+	m := &dbfMemoWriter{version: version, blockSize: dbfMemoBlockSize}
+	if version == DBFVersionVisualFoxPro {
+		m.blockSize = dbfVFPMemoBlockSize
+	}
+	// The memo file header always occupies the first 512 bytes
+	m.buf.Write(make([]byte, dbfMemoBlockSize))
+	return m
+}
+
+// add stores text and returns its block number, or 0 for empty text.
+func (m *dbfMemoWriter) add(text string) int {
+	if text == "" {
+		return 0
+	}
+	block := m.buf.Len() / m.blockSize
+	switch m.version {
+	case DBFVersionVisualFoxPro:
+		var prefix [8]byte
+		binary.BigEndian.PutUint32(prefix[:4], 1)
+		binary.BigEndian.PutUint32(prefix[4:], uint32(len(text)))
+		m.buf.Write(prefix[:])
+		m.buf.WriteString(text)
+	case DBFVersionDBaseIV:
+		prefix := [8]byte{0xFF, 0xFF, 0x08, 0x00}
+		binary.LittleEndian.PutUint32(prefix[4:], uint32(len(text)+8))
+		m.buf.Write(prefix[:])
+		m.buf.WriteString(text)
+	default:
+		m.buf.WriteString(text)
+		m.buf.Write([]byte{dbfEOF, dbfEOF})
+	}
+	if pad := m.buf.Len() % m.blockSize; pad != 0 {
+		m.buf.Write(make([]byte, m.blockSize-pad))
+	}
+	return block
+}
+
+// ref encodes a block number as a memo field value of the given length.
+func (m *dbfMemoWriter) ref(block, length int) []byte {
+	if m.version == DBFVersionVisualFoxPro {
+		b := make([]byte, length)
+		binary.LittleEndian.PutUint32(b, uint32(block))
+		return b
+	}
+	if block == 0 {
+		return []byte(strings.Repeat(" ", length))
+	}
+	return []byte(fmt.Sprintf("%*d", length, block))
+}
+
+func (m *dbfMemoWriter) bytes() []byte {
+	data := m.buf.Bytes()
+	next := uint32(len(data) / m.blockSize)
+	switch m.version {
+	case DBFVersionVisualFoxPro:
+		binary.BigEndian.PutUint32(data[:4], next)
+		binary.BigEndian.PutUint16(data[6:8], uint16(m.blockSize))
+	case DBFVersionDBaseIV:
+		binary.LittleEndian.PutUint32(data[:4], next)
+		binary.LittleEndian.PutUint16(data[20:22], uint16(m.blockSize))
+	default:
+		binary.LittleEndian.PutUint32(data[:4], next)
+	}
+	return data
+}
+
+// readDBFMemo returns the memo text stored at block in a .dbt or .fpt file.
+// FoxPro memo files carry their block size in the header and prefix each
+// entry with a big-endian type and length; dBASE III memos are terminated by
+// 0x1A bytes.
+func readDBFMemo(memo []byte, block int, foxpro bool) (string, error) {
+	if foxpro {
+		if len(memo) < 8 {
+			return "", ErrInvalidData
+		}
+		blockSize := int(binary.BigEndian.Uint16(memo[6:8]))
+		offset := block * blockSize
+		if blockSize == 0 || offset+8 > len(memo) {
+			return "", ErrInvalidData
+		}
+		length := int(binary.BigEndian.Uint32(memo[offset+4 : offset+8]))
+		if offset+8+length > len(memo) {
+			return "", ErrInvalidData
+		}
+		return string(memo[offset+8 : offset+8+length]), nil
+	}
+
+	// dBASE IV records its block size at offset 20; dBASE III leaves it zero
+	blockSize := dbfMemoBlockSize
+	if len(memo) >= 22 {
+		if n := int(binary.LittleEndian.Uint16(memo[20:22])); n > 0 {
+			blockSize = n
+		}
+	}
+	offset := block * blockSize
+	if offset > len(memo) {
+		return "", ErrInvalidData
+	}
+	text := memo[offset:]
+	if len(text) >= 8 && bytes.Equal(text[:4], []byte{0xFF, 0xFF, 0x08, 0x00}) {
+		// dBASE IV: length includes the 8-byte prefix
+		length := int(binary.LittleEndian.Uint32(text[4:8]))
+		if length < 8 || length > len(text) {
+			return "", ErrInvalidData
+		}
+		return string(text[8:length]), nil
+	}
+	if idx := bytes.IndexByte(text, dbfEOF); idx >= 0 {
+		text = text[:idx]
+	}
+	return string(text), nil
 }
 
 func importDBF(r io.Reader) (*Dataset, error) {
+	return importDBFWithOptions(r, DBFImportOptions{})
+}
+
+// ImportDBF imports a Dataset from DBF with custom options.
+func ImportDBF(r io.Reader, opts DBFImportOptions) (*Dataset, error) {
+	return importDBFWithOptions(r, opts)
+}
+
+func importDBFWithOptions(r io.Reader, opts DBFImportOptions) (*Dataset, error) {
 	// Read all data
 	data, err := io.ReadAll(r)
 	if err != nil {
@@ -186,36 +526,55 @@ func importDBF(r io.Reader) (*Dataset, error) {
 		return nil, err
 	}
 
-	// Calculate number of fields
-	numFields := (int(header.HeaderSize) - 32 - 1) / 32
-	if numFields < 0 || numFields > 1000 {
-		return nil, ErrInvalidData
+	// Parse field descriptors
+	fields, err := parseDBFFields(data, header)
+	if err != nil {
+		return nil, err
 	}
+	nullBits := dbfNullBits(fields)
+	nullFlags := slices.IndexFunc(fields, func(f dbfFieldDescriptor) bool {
+		return f.Type == dbfFieldTypeNullFlags
+	})
 
-	// Parse field descriptors
-	fields := make([]dbfFieldDescriptor, numFields)
-	for i := 0; i < numFields; i++ {
-		offset := 32 + (i * 32)
-		if offset+32 > len(data) {
-			return nil, ErrInvalidData
-		}
-		fieldReader := bytes.NewReader(data[offset : offset+32])
-		if err := binary.Read(fieldReader, binary.LittleEndian, &fields[i]); err != nil {
-			return nil, err
+	// System fields are not columns
+	var columns []int
+	for i, f := range fields {
+		if !dbfIsSystemField(f) {
+			columns = append(columns, i)
 		}
 	}
+	offsets := make([]int, len(fields))
+	fieldOffset := 1 // Skip deletion flag
+	for i, f := range fields {
+		offsets[i] = fieldOffset
+		fieldOffset += int(f.Length)
+	}
+
+	codePage := opts.CodePage
+	if codePage == 0 {
+		codePage = DBFCodePage(header.Reserved[dbfLanguageDriverOffset])
+	}
+	decode := codePage.decoder()
 
 	// Extract headers
-	headers := make([]string, numFields)
-	for i, f := range fields {
+	headers := make([]string, len(columns))
+	for i, j := range columns {
 		// Find null terminator in name
-		name := string(f.Name[:])
+		name := string(fields[j].Name[:])
 		if idx := strings.IndexByte(name, 0); idx >= 0 {
 			name = name[:idx]
 		}
-		headers[i] = strings.TrimSpace(name)
+		headers[i] = strings.TrimSpace(decode(name))
 	}
 
+	var memo []byte
+	if opts.Memo != nil {
+		if memo, err = io.ReadAll(opts.Memo); err != nil {
+			return nil, err
+		}
+	}
+	foxpro := dbfIsFoxPro(header.Version)
+
 	ds := NewDataset(headers)
 
 	// Parse records
@@ -236,16 +595,35 @@ func importDBF(r io.Reader) (*Dataset, error) {
 		}
 
 		// Parse fields
-		row := make([]any, numFields)
-		fieldOffset := 1 // Skip deletion flag
-		for j, f := range fields {
-			fieldLen := int(f.Length)
-			if fieldOffset+fieldLen > len(recordData) {
+		var nulls []byte
+		if nullFlags != -1 {
+			nulls = dbfFieldBytes(recordData, offsets[nullFlags], fields[nullFlags])
+		}
+		row := make([]any, len(columns))
+		for j, k := range columns {
+			f := fields[k]
+			raw := dbfFieldBytes(recordData, offsets[k], f)
+			if raw == nil {
 				break
 			}
-			value := string(recordData[fieldOffset : fieldOffset+fieldLen])
-			row[j] = strings.TrimSpace(value)
-			fieldOffset += fieldLen
+			if bit := nullBits[k]; bit >= 0 && bit/8 < len(nulls) && nulls[bit/8]&(1<<(bit%8)) != 0 {
+				row[j] = nil
+				continue
+			}
+			if f.Type == dbfFieldTypeMemo && memo != nil {
+				text, err := dbfMemoValue(memo, raw, foxpro)
+				if err != nil {
+					return nil, err
+				}
+				row[j] = decode(text)
+				continue
+			}
+			value := strings.TrimSpace(decode(string(raw)))
+			if opts.Typed {
+				row[j] = dbfTypedValue(f, value)
+				continue
+			}
+			row[j] = value
 		}
 
 		if err := ds.Append(row); err != nil {
@@ -255,3 +633,480 @@ func importDBF(r io.Reader) (*Dataset, error) {
 
 	return ds, nil
 }
+
+// dbfTypedValue converts a trimmed field value to the Go type matching the
+// field's DBF type. Values that fail to parse are returned unchanged.
+func dbfTypedValue(f dbfFieldDescriptor, value string) any {
+	switch f.Type {
+	case dbfFieldTypeNumber, dbfFieldTypeFloat:
+		if value == "" {
+			return nil
+		}
+		if f.Type == dbfFieldTypeNumber && f.DecimalCount == 0 {
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return n
+			}
+		}
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case dbfFieldTypeLogical:
+		switch value {
+		case "T", "t", "Y", "y":
+			return true
+		case "F", "f", "N", "n":
+			return false
+		case "", "?":
+			return nil
+		}
+	case dbfFieldTypeDate:
+		if value == "" {
+			return nil
+		}
+		if t, err := time.Parse("20060102", value); err == nil {
+			return t
+		}
+	}
+	return value
+}
+
+// parseDBFFields reads the field descriptors that follow the 32-byte table
+// header, stopping at the header terminator.
+func parseDBFFields(data []byte, header dbfHeader) ([]dbfFieldDescriptor, error) {
+	end := min(int(header.HeaderSize), len(data))
+	var fields []dbfFieldDescriptor
+	for offset := 32; offset < end && data[offset] != dbfHeaderTerminator; offset += 32 {
+		if offset+32 > len(data) || len(fields) >= 1000 {
+			return nil, ErrInvalidData
+		}
+		var fd dbfFieldDescriptor
+		if err := binary.Read(bytes.NewReader(data[offset:offset+32]), binary.LittleEndian, &fd); err != nil {
+			return nil, err
+		}
+		fields = append(fields, fd)
+	}
+	return fields, nil
+}
+
+// dbfFieldBytes returns the bytes of field f at offset in a record, or nil
+// when the record is too short.
+func dbfFieldBytes(record []byte, offset int, f dbfFieldDescriptor) []byte {
+	if offset+int(f.Length) > len(record) {
+		return nil
+	}
+	return record[offset : offset+int(f.Length)]
+}
+
+// dbfIsSystemField reports whether f is a hidden Visual FoxPro system
+// field, such as _NullFlags, rather than a column.
+func dbfIsSystemField(f dbfFieldDescriptor) bool {
+	return f.Type == dbfFieldTypeNullFlags || f.Reserved2[dbfFieldFlagsOffset]&dbfVFPFieldSystem != 0
+}
+
+// dbfNullBits returns, for each field, the bit of the _NullFlags field
+// that marks its value null, or -1 when the field is not nullable. As in
+// Visual FoxPro, variable-length fields ('V', 'Q') take a bit of their own
+// before it.
+func dbfNullBits(fields []dbfFieldDescriptor) []int {
+	bits := make([]int, len(fields))
+	next := 0
+	for i, f := range fields {
+		bits[i] = -1
+		if f.Type == 'V' || f.Type == 'Q' {
+			next++
+		}
+		if f.Reserved2[dbfFieldFlagsOffset]&dbfVFPFieldNullable != 0 {
+			bits[i] = next
+			next++
+		}
+	}
+	return bits
+}
+
+// dbfNullableCount returns the number of _NullFlags bits fields use.
+func dbfNullableCount(fields []dbfFieldDescriptor) int {
+	n := 0
+	for _, bit := range dbfNullBits(fields) {
+		n = max(n, bit+1)
+	}
+	return n
+}
+
+// dbfNullFlags returns the _NullFlags field value of length bytes for row,
+// whose cells are those of the fields before the system field.
+func dbfNullFlags(fields []dbfFieldDescriptor, row []any, length int) []byte {
+	flags := make([]byte, length)
+	for i, bit := range dbfNullBits(fields) {
+		if bit >= 0 && bit/8 < length && i < len(row) && row[i] == nil {
+			flags[bit/8] |= 1 << (bit % 8)
+		}
+	}
+	return flags
+}
+
+// dbfMemoValue resolves a memo field's block reference against the memo file.
+// Visual FoxPro stores the reference as a 4-byte integer, older formats as
+// space-padded ASCII digits.
+func dbfMemoValue(memo, raw []byte, foxpro bool) (string, error) {
+	var block int
+	if foxpro && len(raw) == 4 {
+		block = int(binary.LittleEndian.Uint32(raw))
+	} else {
+		ref := strings.TrimSpace(string(raw))
+		if ref == "" {
+			return "", nil
+		}
+		n, err := strconv.Atoi(ref)
+		if err != nil {
+			return "", ErrInvalidData
+		}
+		block = n
+	}
+	if block == 0 {
+		return "", nil
+	}
+	return readDBFMemo(memo, block, foxpro)
+}
+
+// dbfIsFoxPro reports whether a version byte denotes a FoxPro table, whose
+// memo files use the .fpt layout.
+func dbfIsFoxPro(version byte) bool {
+	switch version {
+	case 0x30, 0x31, 0x32, 0xF5:
+		return true
+	}
+	return false
+}
+
+// DBFField describes a character field created by NewDBFWriter.
+type DBFField struct {
+	Name   string
+	Length int
+}
+
+// DBFWriter appends records to a DBF file one at a time, keeping the record
+// count in the header current after every write so the file stays readable
+// while it grows.
+type DBFWriter struct {
+	f       io.ReadWriteSeeker
+	header  dbfHeader
+	fields  []dbfFieldDescriptor
+	encode  func(string) string
+	pending int64 // offset where the next record is written
+}
+
+// NewDBFWriter writes an empty DBF table with the given fields to f and
+// returns a writer for appending records to it.
+func NewDBFWriter(f io.ReadWriteSeeker, fields []DBFField, opts DBFOptions) (*DBFWriter, error) {
+	if len(fields) == 0 {
+		return nil, ErrHeadersRequired
+	}
+
+	encode := opts.CodePage.encoder()
+	descriptors := make([]dbfFieldDescriptor, len(fields))
+	recordSize := 1
+	for i, field := range fields {
+		if field.Length < 1 || field.Length > dbfMaxCharLength {
+			return nil, ErrInvalidDimensions
+		}
+		copy(descriptors[i].Name[:], dbfFieldName(field.Name, encode))
+		descriptors[i].Type = dbfFieldTypeChar
+		descriptors[i].Length = byte(field.Length)
+		recordSize += field.Length
+	}
+
+	header := dbfHeader{
+		Version:    opts.Version.versionByte(false),
+		RecordSize: uint16(recordSize),
+	}
+	header.Reserved[dbfLanguageDriverOffset] = byte(opts.CodePage)
+	dbfStamp(&header, time.Now())
+
+	var buf bytes.Buffer
+	if err := writeDBFHeader(&buf, &header, descriptors, opts.Version); err != nil {
+		return nil, err
+	}
+	buf.WriteByte(dbfEOF)
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return &DBFWriter{
+		f:       f,
+		header:  header,
+		fields:  descriptors,
+		encode:  encode,
+		pending: int64(header.HeaderSize),
+	}, nil
+}
+
+// OpenDBFWriter reads the header of an existing DBF table in f and returns a
+// writer that appends records after the last one. The code page recorded in
+// the file is used unless opts.CodePage is set.
+func OpenDBFWriter(f io.ReadWriteSeeker, opts DBFOptions) (*DBFWriter, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var header dbfHeader
+	if err := binary.Read(f, binary.LittleEndian, &header); err != nil {
+		return nil, ErrInvalidData
+	}
+	if header.HeaderSize < 33 || header.RecordSize < 1 {
+		return nil, ErrInvalidData
+	}
+
+	data := make([]byte, header.HeaderSize)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, ErrInvalidData
+	}
+	fields, err := parseDBFFields(data, header)
+	if err != nil {
+		return nil, err
+	}
+	for _, fd := range fields {
+		if fd.Type == dbfFieldTypeMemo {
+			return nil, fmt.Errorf("%w: memo fields cannot be appended", ErrUnsupportedFormat)
+		}
+	}
+
+	codePage := opts.CodePage
+	if codePage == 0 {
+		codePage = DBFCodePage(header.Reserved[dbfLanguageDriverOffset])
+	}
+
+	return &DBFWriter{
+		f:       f,
+		header:  header,
+		fields:  fields,
+		encode:  codePage.encoder(),
+		pending: int64(header.HeaderSize) + int64(header.RecordCount)*int64(header.RecordSize),
+	}, nil
+}
+
+// Append writes a record to the end of the table and updates the header's
+// record count and last-update date.
+func (dw *DBFWriter) Append(row []any) error {
+	// Spread the row over the fields, skipping system fields
+	cells := make([]any, len(dw.fields))
+	n := 0
+	for i, fd := range dw.fields {
+		if dbfIsSystemField(fd) {
+			continue
+		}
+		if n == len(row) {
+			return ErrInvalidDimensions
+		}
+		cells[i] = row[n]
+		n++
+	}
+	if n != len(row) {
+		return ErrInvalidDimensions
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(dbfRecordActive)
+	for i, fd := range dw.fields {
+		switch {
+		case fd.Type == dbfFieldTypeNullFlags:
+			buf.Write(dbfNullFlags(dw.fields, cells, int(fd.Length)))
+		case dbfIsSystemField(fd):
+			buf.Write(make([]byte, fd.Length))
+		default:
+			val := dbfFieldValue(cells[i], fd.Type, int(fd.DecimalCount), dw.encode)
+			buf.WriteString(dbfPad(val, int(fd.Length), fd.Type))
+		}
+	}
+	buf.WriteByte(dbfEOF)
+
+	if _, err := dw.f.Seek(dw.pending, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := dw.f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	dw.pending += int64(dw.header.RecordSize)
+
+	dw.header.RecordCount++
+	dbfStamp(&dw.header, time.Now())
+	if _, err := dw.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return binary.Write(dw.f, binary.LittleEndian, &dw.header)
+}
+
+// Count returns the number of records in the table, including deleted ones.
+func (dw *DBFWriter) Count() int {
+	return int(dw.header.RecordCount)
+}
+
+// dbfStamp sets the header's last-update date.
+func dbfStamp(header *dbfHeader, t time.Time) {
+	header.Year = byte(t.Year() - 1900)
+	header.Month = byte(t.Month())
+	header.Day = byte(t.Day())
+}
+
+// dbfFieldValue returns the encoded text of v for a field of the given
+// type: YYYYMMDD for times in date fields, T or F for booleans in logical
+// fields, and numbers with the field's decimal count in numeric fields.
+// Other values are stored as their display text, and nil as blanks.
+func dbfFieldValue(v any, fieldType byte, decimals int, encode func(string) string) string {
+	switch fieldType {
+	case dbfFieldTypeDate:
+		if t, ok := v.(time.Time); ok {
+			return t.Format("20060102")
+		}
+	case dbfFieldTypeLogical:
+		if b, ok := v.(bool); ok {
+			if b {
+				return "T"
+			}
+			return "F"
+		}
+	case dbfFieldTypeNumber, dbfFieldTypeFloat:
+		switch v.(type) {
+		case float32, float64:
+		case string:
+			return encode(v.(string))
+		default:
+			if decimals == 0 {
+				return valueString(v) // integers keep full precision
+			}
+		}
+		if f, ok := numericValue(v); ok {
+			return strconv.FormatFloat(f, 'f', decimals, 64)
+		}
+	}
+	return encode(valueString(v))
+}
+
+// dbfPad pads or truncates an encoded value to a field's length. Numeric
+// fields are right-aligned, everything else left-aligned.
+func dbfPad(val string, length int, fieldType byte) string {
+	if len(val) > length {
+		val = val[:length]
+	}
+	if fieldType == dbfFieldTypeNumber || fieldType == dbfFieldTypeFloat {
+		return fmt.Sprintf("%*s", length, val)
+	}
+	return fmt.Sprintf("%-*s", length, val)
+}
+
+// DBFCodePage identifies a DBF code page by its language driver ID, the
+// value stored at offset 29 of the table header.
+type DBFCodePage byte
+
+// Supported DBF code pages.
+const (
+	DBFCodePageCP437  DBFCodePage = 0x01 // US MS-DOS
+	DBFCodePageCP850  DBFCodePage = 0x02 // International MS-DOS
+	DBFCodePageCP1252 DBFCodePage = 0x03 // Windows ANSI
+	DBFCodePageCP852  DBFCodePage = 0x64 // Eastern European MS-DOS
+	DBFCodePageCP866  DBFCodePage = 0x65 // Russian MS-DOS
+	DBFCodePageCP865  DBFCodePage = 0x66 // Nordic MS-DOS
+	DBFCodePageCP932  DBFCodePage = 0x7B // Japanese Shift-JIS
+	DBFCodePageCP936  DBFCodePage = 0x7A // Chinese GBK (PRC)
+	DBFCodePageCP949  DBFCodePage = 0x79 // Korean
+	DBFCodePageCP950  DBFCodePage = 0x78 // Chinese Big5 (Taiwan)
+	DBFCodePageCP874  DBFCodePage = 0x7C // Thai
+	DBFCodePageCP1250 DBFCodePage = 0xC8 // Eastern European Windows
+	DBFCodePageCP1251 DBFCodePage = 0xC9 // Russian Windows
+	DBFCodePageCP1253 DBFCodePage = 0xCB // Greek Windows
+	DBFCodePageCP1254 DBFCodePage = 0xCA // Turkish Windows
+
+	// DBFCodePageGBK is an alias for DBFCodePageCP936.
+	DBFCodePageGBK = DBFCodePageCP936
+)
+
+// encoding returns the text encoding for the code page, or nil when the
+// code page is unset or unknown.
+func (cp DBFCodePage) encoding() encoding.Encoding {
+	switch cp {
+	case DBFCodePageCP437:
+		return charmap.CodePage437
+	case DBFCodePageCP850:
+		return charmap.CodePage850
+	case DBFCodePageCP1252, 0x57:
+		return charmap.Windows1252
+	case DBFCodePageCP852:
+		return charmap.CodePage852
+	case DBFCodePageCP866, 0x26:
+		return charmap.CodePage866
+	case DBFCodePageCP865:
+		return charmap.CodePage865
+	case DBFCodePageCP932:
+		return japanese.ShiftJIS
+	case DBFCodePageCP936:
+		return simplifiedchinese.GBK
+	case DBFCodePageCP949:
+		return korean.EUCKR
+	case DBFCodePageCP950:
+		return traditionalchinese.Big5
+	case DBFCodePageCP874:
+		return charmap.Windows874
+	case DBFCodePageCP1250:
+		return charmap.Windows1250
+	case DBFCodePageCP1251:
+		return charmap.Windows1251
+	case DBFCodePageCP1253:
+		return charmap.Windows1253
+	case DBFCodePageCP1254:
+		return charmap.Windows1254
+	}
+	return nil
+}
+
+// dbfFieldName upper-cases name and encodes it with encode, truncated to
+// the 10 bytes of a field name without splitting a character.
+func dbfFieldName(name string, encode func(string) string) string {
+	var out string
+	for _, r := range strings.ToUpper(name) {
+		next := out + encode(string(r))
+		if len(next) > 10 {
+			break
+		}
+		out = next
+	}
+	return out
+}
+
+// encoder returns a function converting UTF-8 text to the code page.
+// Characters the code page cannot represent are replaced.
+func (cp DBFCodePage) encoder() func(string) string {
+	enc := cp.encoding()
+	if enc == nil {
+		return func(s string) string { return s }
+	}
+	e := encoding.ReplaceUnsupported(enc.NewEncoder())
+	return func(s string) string {
+		out, err := e.String(s)
+		if err != nil {
+			return s
+		}
+		return out
+	}
+}
+
+// decoder returns a function converting code page text to UTF-8.
+func (cp DBFCodePage) decoder() func(string) string {
+	enc := cp.encoding()
+	if enc == nil {
+		return func(s string) string { return s }
+	}
+	d := enc.NewDecoder()
+	return func(s string) string {
+		out, err := d.String(s)
+		if err != nil {
+			return s
+		}
+		return out
+	}
+}
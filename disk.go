@@ -0,0 +1,314 @@
+package tablib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DiskOptions configures a DiskDataset.
+type DiskOptions struct {
+	// MemoryRows is the number of rows kept in memory. Appending more
+	// moves every row to a temporary file. Zero means 10000.
+	MemoryRows int
+
+	// Dir is the directory of the temporary file, or "" for the default
+	// directory for temporary files.
+	Dir string
+}
+
+// DiskDataset holds rows too many to keep in memory, such as those of a
+// large CSV file being converted to another format. Rows are appended and
+// read back in order, and spill to a temporary file in the native format
+// once there are more than MemoryRows of them, so cells must be of types
+// the native format supports. Close removes the temporary file.
+type DiskDataset struct {
+	headers []string
+	opts    DiskOptions
+	height  int
+	rows    [][]any // rows in memory, until the dataset spills
+
+	file    *os.File
+	out     *bufio.Writer // buffered writes to file
+	scratch bytes.Buffer  // the row being encoded
+	enc     *nativeWriter // encodes into scratch
+}
+
+// NewDiskDataset creates a new empty DiskDataset.
+func NewDiskDataset(headers []string, opts DiskOptions) *DiskDataset {
+	if opts.MemoryRows <= 0 {
+		opts.MemoryRows = 10000
+	}
+	d := &DiskDataset{headers: slices.Clone(headers), opts: opts}
+	d.enc = &nativeWriter{w: bufio.NewWriter(&d.scratch)}
+	return d
+}
+
+// ImportDiskCSV reads CSV into a new DiskDataset one record at a time, so
+// the input never has to fit in memory. The options match ImportCSV.
+func ImportDiskCSV(r io.Reader, delimiter rune, hasHeaders bool, opts DiskOptions) (*DiskDataset, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var d *DiskDataset
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if d != nil {
+				d.Close()
+			}
+			return nil, err
+		}
+		if d == nil && hasHeaders {
+			d = NewDiskDataset(record, opts)
+			continue
+		}
+		if d == nil {
+			d = NewDiskDataset(nil, opts)
+		}
+		row := make([]any, len(record))
+		for i, v := range record {
+			row[i] = v
+		}
+		if err := d.Append(row); err != nil {
+			d.Close()
+			return nil, err
+		}
+	}
+	if d == nil {
+		d = NewDiskDataset(nil, opts)
+	}
+	return d, nil
+}
+
+// Headers returns the headers of the dataset.
+func (d *DiskDataset) Headers() []string {
+	return slices.Clone(d.headers)
+}
+
+// Height returns the number of rows.
+func (d *DiskDataset) Height() int {
+	return d.height
+}
+
+// Append adds a row to the dataset. The row must be as wide as the headers,
+// if any, and cells of types the native format cannot store give
+// ErrInvalidData.
+func (d *DiskDataset) Append(row []any) error {
+	if len(d.headers) > 0 && len(row) != len(d.headers) {
+		return ErrInvalidDimensions
+	}
+	if err := d.encode(row); err != nil {
+		return err
+	}
+	if d.file == nil && len(d.rows) < d.opts.MemoryRows {
+		d.rows = append(d.rows, slices.Clone(row))
+		d.height++
+		return nil
+	}
+	if d.file == nil {
+		if err := d.spill(); err != nil {
+			return err
+		}
+		// Spilling reused the scratch buffer
+		d.encode(row)
+	}
+	if _, err := d.out.Write(d.scratch.Bytes()); err != nil {
+		return err
+	}
+	d.height++
+	return nil
+}
+
+// spill moves the rows in memory to a new temporary file.
+func (d *DiskDataset) spill() error {
+	file, err := os.CreateTemp(d.opts.Dir, "tablib-*.tblb")
+	if err != nil {
+		return err
+	}
+	d.file = file
+	d.out = bufio.NewWriter(file)
+	for _, row := range d.rows {
+		d.encode(row) // checked by Append
+		if _, err := d.out.Write(d.scratch.Bytes()); err != nil {
+			return err
+		}
+	}
+	d.rows = nil
+	return nil
+}
+
+// encode encodes a row into the scratch buffer, so that a cell that cannot
+// be stored is found before anything is written to the file.
+func (d *DiskDataset) encode(row []any) error {
+	d.scratch.Reset()
+	d.enc.err = nil
+	d.enc.uvarint(uint64(len(row)))
+	for _, v := range row {
+		d.enc.value(v)
+	}
+	if d.enc.err == nil {
+		d.enc.err = d.enc.w.Flush()
+	}
+	if d.enc.err != nil {
+		d.enc.w.Reset(&d.scratch)
+	}
+	return d.enc.err
+}
+
+// Each calls fn with every row in order, stopping at the first error,
+// which it returns. The row passed to fn must not be kept, as its slice
+// may be reused.
+func (d *DiskDataset) Each(fn func(row []any) error) error {
+	if d.file == nil {
+		for _, row := range d.rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := d.out.Flush(); err != nil {
+		return err
+	}
+	info, err := d.file.Stat()
+	if err != nil {
+		return err
+	}
+	nr := newNativeReader(io.NewSectionReader(d.file, 0, info.Size()), info.Size())
+	var row []any
+	for range d.height {
+		n := nr.count()
+		row = slices.Grow(row[:0], n)[:n]
+		for i := range row {
+			row[i] = nr.value()
+		}
+		if nr.err != nil {
+			return nr.err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToDataset loads every row into a new Dataset.
+func (d *DiskDataset) ToDataset() (*Dataset, error) {
+	ds := NewDataset(d.headers)
+	err := d.Each(func(row []any) error {
+		return ds.Append(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// Export streams the rows to w in the specified format, holding one row in
+// memory at a time. CSV, TSV, NDJSON and XLSX are supported; other formats
+// return ErrUnsupportedFormat.
+func (d *DiskDataset) Export(format Format, w io.Writer) error {
+	switch format {
+	case FormatCSV, FormatTSV:
+		return d.exportCSV(w, format == FormatTSV)
+	case FormatNDJSON:
+		return d.exportNDJSON(w)
+	case FormatXLSX:
+		return d.exportXLSX(w)
+	}
+	return ErrUnsupportedFormat
+}
+
+func (d *DiskDataset) exportCSV(w io.Writer, tabs bool) error {
+	writer := csv.NewWriter(w)
+	if tabs {
+		writer.Comma = '\t'
+	}
+	if len(d.headers) > 0 {
+		if err := writer.Write(d.headers); err != nil {
+			return err
+		}
+	}
+	var record []string
+	err := d.Each(func(row []any) error {
+		record = record[:0]
+		for _, v := range row {
+			record = append(record, valueString(v))
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (d *DiskDataset) exportNDJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return d.Each(func(row []any) error {
+		return encodeNDJSONRow(encoder, d.headers, row)
+	})
+}
+
+func (d *DiskDataset) exportXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	sw, err := f.NewStreamWriter("Sheet1")
+	if err != nil {
+		return err
+	}
+
+	rowNum := 1
+	if len(d.headers) > 0 {
+		header := make([]any, len(d.headers))
+		for i, h := range d.headers {
+			header[i] = h
+		}
+		if err := sw.SetRow("A1", header); err != nil {
+			return err
+		}
+		rowNum++
+	}
+	err = d.Each(func(row []any) error {
+		cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+		rowNum++
+		return sw.SetRow(cell, row)
+	})
+	if err != nil {
+		return err
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// Close removes the temporary file, if any. The dataset must not be used
+// afterwards.
+func (d *DiskDataset) Close() error {
+	d.rows = nil
+	if d.file == nil {
+		return nil
+	}
+	name := d.file.Name()
+	err := d.file.Close()
+	d.file = nil
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package tablib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	RegisterExporter(FormatDokuWiki, ExporterFunc(exportDokuWiki))
+}
+
+// exportDokuWiki exports the Dataset to DokuWiki table markup.
+func exportDokuWiki(ds *Dataset, w io.Writer) error {
+	if ds.Width() == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+
+	// Write headers (DokuWiki uses ^ for header cells)
+	if len(ds.headers) > 0 {
+		sb.WriteString("^")
+		for _, h := range ds.headers {
+			sb.WriteString(fmt.Sprintf(" %s ^", escapeDokuWiki(h)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// writeSeparator writes a bold row spanning every column; DokuWiki
+	// merges empty cells into the cell before them
+	writeSeparator := func(text string) {
+		sb.WriteString(fmt.Sprintf("| **%s** ", escapeDokuWiki(text)))
+		sb.WriteString(strings.Repeat("|", ds.Width()))
+		sb.WriteString("\n")
+	}
+
+	// Write data rows
+	for rowIdx, row := range ds.data {
+		// Check for separator before this row
+		if sep, ok := ds.GetSeparator(rowIdx); ok {
+			writeSeparator(sep.Text)
+		}
+
+		sb.WriteString("|")
+		for i, v := range row {
+			sb.WriteString(fmt.Sprintf(" %s |", escapeDokuWiki(ds.cellText(i, v))))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Check for separator after the last row
+	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
+		writeSeparator(sep.Text)
+	}
+
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
+
+// escapeDokuWiki protects cell delimiters with %% nowiki markers and turns
+// newlines into forced line breaks.
+func escapeDokuWiki(s string) string {
+	if strings.ContainsAny(s, "|^") {
+		s = "%%" + s + "%%"
+	}
+	return strings.ReplaceAll(s, "\n", "\\\\ ")
+}
@@ -0,0 +1,261 @@
+package tablib
+
+import "slices"
+
+// dynamicColumn is a computed column placed before the stored column at
+// index before, or after every stored column when before is -1. The
+// columns of a dataset are kept in column order, so their before indices
+// never decrease, with -1 last.
+type dynamicColumn struct {
+	header string
+	fn     DynamicColumn
+	before int
+}
+
+// layoutColumn is a column in display order: the stored column at index,
+// or the dynamic column at index when dynamic is true.
+type layoutColumn struct {
+	index   int
+	dynamic bool
+}
+
+// AddDynamicColumn adds a dynamic (computed) column after every other
+// column; stored columns appended later go before it. Adding a dynamic column
+// under a header already in use by one replaces its function in place.
+// A header already in use by a stored column is rejected, as by
+// AddDynamicColumnAt, and the call does nothing; use AddDynamicColumnAt to
+// have the collision reported as ErrInvalidData.
+func (ds *Dataset) AddDynamicColumn(header string, fn DynamicColumn) {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	if ds.headerIndex(header) != -1 {
+		return
+	}
+	if i := ds.dynamicIndex(header); i != -1 {
+		ds.dynamicCols[i].fn = fn
+		return
+	}
+	ds.dynamicCols = append(ds.dynamicCols, dynamicColumn{header: header, fn: fn, before: -1})
+}
+
+// AddDynamicColumnAt adds a dynamic column at the specified index among
+// all columns, stored and dynamic, moving it there if a dynamic column of
+// that header exists. It keeps its place relative to the stored columns as
+// they are added and removed. A header already in use by a stored column
+// returns ErrInvalidData.
+func (ds *Dataset) AddDynamicColumnAt(index int, header string, fn DynamicColumn) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if ds.headerIndex(header) != -1 {
+		return ErrInvalidData
+	}
+	dynamicCols := ds.dynamicCols
+	if i := ds.dynamicIndex(header); i != -1 {
+		dynamicCols = slices.Delete(slices.Clone(dynamicCols), i, i+1)
+	}
+	view := *ds
+	view.dynamicCols = dynamicCols
+	layout := view.columnLayout()
+	if index < 0 || index > len(layout) {
+		return ErrInvalidColumnIndex
+	}
+
+	// Columns before index decide the place among stored and dynamic ones
+	stored, pos := layoutCounts(layout[:index])
+	before := stored
+	if stored == ds.storedWidth() {
+		before = -1
+	}
+	dc := dynamicColumn{header: header, fn: fn, before: before}
+	ds.dynamicCols = slices.Insert(slices.Clip(dynamicCols), pos, dc)
+	return nil
+}
+
+// MaterializeDynamicColumns computes the dynamic columns for every row and
+// stores them as regular columns in their positions, removing the
+// functions. Later changes to the rows no longer update them.
+func (ds *Dataset) MaterializeDynamicColumns() error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if len(ds.dynamicCols) == 0 {
+		return nil
+	}
+	ds.record()
+	view := ds.withDynamicColumns()
+	ds.headers, ds.data = view.headers, view.data
+	ds.dynamicCols = nil
+	ds.sharing.shared.Store(false)
+	ds.dropIndexes()
+	return nil
+}
+
+// withDynamicColumns returns a view of the dataset with its dynamic
+// columns stored in their positions, for exporters, which read the rows
+// and headers directly. Without dynamic columns it returns the dataset
+// itself. The view shares the settings of the dataset.
+func (ds *Dataset) withDynamicColumns() *Dataset {
+	if len(ds.dynamicCols) == 0 {
+		return ds
+	}
+	view := *ds
+	layout := ds.columnLayout()
+	view.data = make([][]any, len(ds.data))
+	for i, row := range ds.data {
+		view.data[i] = ds.effectiveRow(layout, row)
+	}
+	if len(ds.headers) > 0 {
+		view.headers = ds.effectiveHeaders()
+	}
+	view.dynamicCols = nil
+	view.indexes = nil
+	return &view
+}
+
+// withDynamicColumns returns a view of the databook whose sheets have
+// their dynamic columns stored, see Dataset.withDynamicColumns.
+func (db *Databook) withDynamicColumns() *Databook {
+	view := *db
+	view.sheets = make([]*Dataset, len(db.sheets))
+	for i, ds := range db.sheets {
+		view.sheets[i] = ds.withDynamicColumns()
+	}
+	return &view
+}
+
+// dynamicIndex returns the index of the dynamic column with the specified
+// header, or -1.
+func (ds *Dataset) dynamicIndex(header string) int {
+	return slices.IndexFunc(ds.dynamicCols, func(dc dynamicColumn) bool {
+		return dc.header == header
+	})
+}
+
+// columnLayout returns the stored and dynamic columns in display order.
+func (ds *Dataset) columnLayout() []layoutColumn {
+	n := ds.storedWidth()
+	layout := make([]layoutColumn, 0, n+len(ds.dynamicCols))
+	d := 0
+	for k := 0; k <= n; k++ {
+		for d < len(ds.dynamicCols) && ds.dynamicBefore(d) == k {
+			layout = append(layout, layoutColumn{index: d, dynamic: true})
+			d++
+		}
+		if k < n {
+			layout = append(layout, layoutColumn{index: k})
+		}
+	}
+	return layout
+}
+
+// layoutCounts returns the number of stored and dynamic columns in layout.
+func layoutCounts(layout []layoutColumn) (stored, dynamic int) {
+	for _, c := range layout {
+		if c.dynamic {
+			dynamic++
+		} else {
+			stored++
+		}
+	}
+	return stored, dynamic
+}
+
+// columnAt returns the column at index in display order.
+func (ds *Dataset) columnAt(index int) (layoutColumn, bool) {
+	if len(ds.dynamicCols) == 0 {
+		return layoutColumn{index: index}, index >= 0 && index < ds.storedWidth()
+	}
+	layout := ds.columnLayout()
+	if index < 0 || index >= len(layout) {
+		return layoutColumn{}, false
+	}
+	return layout[index], true
+}
+
+// columnIndex returns the display index of the stored or dynamic column
+// with the specified header, or -1.
+func (ds *Dataset) columnIndex(header string) int {
+	if len(ds.dynamicCols) == 0 {
+		return ds.headerIndex(header)
+	}
+	for i, c := range ds.columnLayout() {
+		if h, ok := ds.columnHeader(c); ok && h == header {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnHeader returns the header of column c, if it has one.
+func (ds *Dataset) columnHeader(c layoutColumn) (string, bool) {
+	if c.dynamic {
+		return ds.dynamicCols[c.index].header, true
+	}
+	if c.index < len(ds.headers) {
+		return ds.headers[c.index], true
+	}
+	return "", false
+}
+
+// cellValue returns the value of column c in row, computing it for a
+// dynamic column.
+func (ds *Dataset) cellValue(c layoutColumn, row []any) any {
+	if c.dynamic {
+		return ds.dynamicCols[c.index].fn(row)
+	}
+	return row[c.index]
+}
+
+// dynamicBefore returns the index of the stored column that dynamic
+// column d precedes, or the width to follow them all.
+func (ds *Dataset) dynamicBefore(d int) int {
+	before, n := ds.dynamicCols[d].before, ds.storedWidth()
+	if before == -1 || before > n {
+		return n
+	}
+	return before
+}
+
+// effectiveHeaders returns the headers of the stored and dynamic columns
+// in display order.
+func (ds *Dataset) effectiveHeaders() []string {
+	if len(ds.dynamicCols) == 0 {
+		return slices.Clone(ds.headers)
+	}
+	headers := make([]string, 0, len(ds.headers)+len(ds.dynamicCols))
+	for _, c := range ds.columnLayout() {
+		if h, ok := ds.columnHeader(c); ok {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// effectiveRow returns a copy of row with the values of the dynamic
+// columns in their positions, given the layout from columnLayout.
+func (ds *Dataset) effectiveRow(layout []layoutColumn, row []any) []any {
+	if len(ds.dynamicCols) == 0 {
+		return slices.Clone(row)
+	}
+	r := make([]any, 0, len(layout))
+	for _, c := range layout {
+		if c.dynamic {
+			r = append(r, ds.dynamicCols[c.index].fn(row))
+		} else {
+			r = append(r, row[c.index])
+		}
+	}
+	return r
+}
+
+// shiftDynamicColumns moves the dynamic columns placed after stored column
+// index by delta, after a stored column is inserted or removed there.
+func (ds *Dataset) shiftDynamicColumns(index, delta int) {
+	for i, dc := range ds.dynamicCols {
+		if dc.before > index {
+			ds.dynamicCols[i].before += delta
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package tablib
+
+import (
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Equal reports whether the datasets have the same headers, cell values,
+// title, row tags and separators. Row tags match in any order. See
+// EqualData for how values are compared. Display settings such as
+// formats, alignments and formatters, and dynamic columns, are not
+// compared.
+func (ds *Dataset) Equal(other *Dataset) bool {
+	if !ds.EqualData(other) || ds.title != other.title {
+		return false
+	}
+	for i := range ds.tags {
+		a, b := slices.Clone(ds.tags[i]), slices.Clone(other.tags[i])
+		slices.Sort(a)
+		slices.Sort(b)
+		if !slices.Equal(a, b) {
+			return false
+		}
+	}
+	return maps.Equal(ds.separators, other.separators)
+}
+
+// EqualData reports whether the datasets have the same headers and cell
+// values, ignoring the title, tags and separators.
+//
+// Numbers are equal when they have the same value, whatever their type,
+// so int 1, int64 1 and float64 1.0 are equal, and NaN equals NaN. A
+// float32 is compared by its exact value, which for 0.1 differs from
+// float64 0.1. Numbers never equal strings, so 1 and "1" differ. Times
+// are equal when they are the same instant, and other values when they
+// are deeply equal.
+func (ds *Dataset) EqualData(other *Dataset) bool {
+	if !slices.Equal(ds.headers, other.headers) || len(ds.data) != len(other.data) {
+		return false
+	}
+	for i, row := range ds.data {
+		if !slices.EqualFunc(row, other.data[i], equalValues) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues reports whether two cell values are equal, see EqualData.
+func equalValues(a, b any) bool {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if isNumberKind(va) && isNumberKind(vb) {
+		return equalNumbers(va, vb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// isNumberKind reports whether v holds an integer or floating-point
+// number.
+func isNumberKind(v reflect.Value) bool {
+	return v.CanInt() || v.CanUint() || v.CanFloat()
+}
+
+// equalNumbers compares two numbers by value without losing precision:
+// integers exactly, and an integer and a float only when the float is a
+// whole number.
+func equalNumbers(a, b reflect.Value) bool {
+	switch {
+	case a.CanFloat() && b.CanFloat():
+		fa, fb := a.Float(), b.Float()
+		return fa == fb || math.IsNaN(fa) && math.IsNaN(fb)
+	case a.CanFloat():
+		return equalIntFloat(b, a.Float())
+	case b.CanFloat():
+		return equalIntFloat(a, b.Float())
+	case a.CanInt() && b.CanInt():
+		return a.Int() == b.Int()
+	case a.CanUint() && b.CanUint():
+		return a.Uint() == b.Uint()
+	case a.CanInt():
+		return a.Int() >= 0 && uint64(a.Int()) == b.Uint()
+	default:
+		return b.Int() >= 0 && uint64(b.Int()) == a.Uint()
+	}
+}
+
+// equalIntFloat reports whether the integer i equals f.
+func equalIntFloat(i reflect.Value, f float64) bool {
+	if f != math.Trunc(f) {
+		return false
+	}
+	if i.CanInt() {
+		return f >= -(1<<63) && f < 1<<63 && int64(f) == i.Int()
+	}
+	return f >= 0 && f < 1<<64 && uint64(f) == i.Uint()
+}
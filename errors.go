@@ -26,4 +26,19 @@ var (
 
 	// ErrInvalidData is returned when the input data is malformed or invalid.
 	ErrInvalidData = errors.New("tablib: invalid data")
+
+	// ErrImportLimit is returned when imported data exceeds an ImportLimits guard.
+	ErrImportLimit = errors.New("tablib: import limit exceeded")
+
+	// ErrInvalidQuery is returned when a Query string cannot be parsed.
+	ErrInvalidQuery = errors.New("tablib: invalid query")
+
+	// ErrConstraintViolation is returned when a change violates a column constraint.
+	ErrConstraintViolation = errors.New("tablib: constraint violation")
+
+	// ErrNoHistory is returned by Undo and Redo when there is no change to revert or reapply.
+	ErrNoHistory = errors.New("tablib: no history")
+
+	// ErrFrozen is returned when attempting to modify a dataset after Freeze.
+	ErrFrozen = errors.New("tablib: dataset is frozen")
 )
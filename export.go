@@ -0,0 +1,254 @@
+package tablib
+
+import (
+	"io"
+	"slices"
+)
+
+// ExportOptions selects and reshapes what is exported without modifying
+// the Dataset or copying it first.
+type ExportOptions struct {
+	// Columns restricts output to these headers, in this order.
+	Columns []string
+
+	// Tags restricts output to rows carrying any of these tags.
+	Tags []string
+	// Offset skips this many rows, counted after the tag filter.
+	Offset int
+	// Limit caps the number of rows written. Zero means no limit.
+	Limit int
+
+	// Totals appends a row aggregating the exported rows of each listed
+	// column. Exporters that support it set the row apart, e.g. as a bold
+	// XLSX row, an HTML <tfoot> or a bordered CLI footer.
+	Totals map[string]Aggregate
+	// TotalsLabel is written in the first column of the totals row when
+	// that column is not aggregated. Defaults to "Total".
+	TotalsLabel string
+
+	// ShowTitle renders the dataset title with the table where the format
+	// allows: a caption in HTML and LaTeX, a heading in Markdown and RST,
+	// and a centered line in CLI output.
+	ShowTitle bool
+
+	// Transpose writes each row as a column, with the headers down the
+	// first column and no header row. It is applied after the options
+	// above; separators and tags are not carried over.
+	Transpose bool
+}
+
+// Aggregate is a function used to summarize a column.
+type Aggregate int
+
+const (
+	// AggregateSum adds up numeric values.
+	AggregateSum Aggregate = iota
+	// AggregateAvg averages numeric values.
+	AggregateAvg
+	// AggregateCount counts non-empty values.
+	AggregateCount
+)
+
+// apply aggregates the values, skipping those that are not numeric for
+// sums and averages. Sums of integers stay integers.
+func (a Aggregate) apply(values []any) any {
+	if a == AggregateCount {
+		count := 0
+		for _, v := range values {
+			if v != nil && v != "" {
+				count++
+			}
+		}
+		return count
+	}
+
+	var sum float64
+	count := 0
+	integral := true
+	for _, v := range values {
+		f, ok := numericValue(v)
+		if !ok {
+			continue
+		}
+		switch v.(type) {
+		case float32, float64, string:
+			integral = false
+		}
+		sum += f
+		count++
+	}
+	switch {
+	case a == AggregateAvg && count == 0:
+		return nil
+	case a == AggregateAvg:
+		return sum / float64(count)
+	case integral:
+		return int64(sum)
+	}
+	return sum
+}
+
+// ExportWith exports the Dataset to the specified format, applying opts
+// while the rows are handed to the exporter.
+func (ds *Dataset) ExportWith(format Format, w io.Writer, opts ExportOptions) error {
+	exporter, ok := exporters[format]
+	if !ok {
+		return ErrUnsupportedFormat
+	}
+	view, settings, err := ds.withDynamicColumns().exportView(opts)
+	if err != nil {
+		return err
+	}
+	if se, ok := exporter.(settingsExporter); ok {
+		return se.exportWith(view, w, settings)
+	}
+	return exporter.Export(view, w)
+}
+
+// exportSettings are the ExportOptions that the built-in exporters render
+// themselves rather than receive in the rows.
+type exportSettings struct {
+	showTitle bool // see ExportOptions.ShowTitle
+	totals    bool // the last row is the ExportOptions.Totals row
+}
+
+// settingsExporter is an Exporter that also honors exportSettings.
+// Exporters without it receive the totals as a plain last row.
+type settingsExporter interface {
+	Exporter
+	exportWith(ds *Dataset, w io.Writer, s exportSettings) error
+}
+
+// settingsExporterFunc adapts a function to a settingsExporter; Export
+// uses the zero settings.
+type settingsExporterFunc func(ds *Dataset, w io.Writer, s exportSettings) error
+
+func (f settingsExporterFunc) Export(ds *Dataset, w io.Writer) error {
+	return f(ds, w, exportSettings{})
+}
+
+func (f settingsExporterFunc) exportWith(ds *Dataset, w io.Writer, s exportSettings) error {
+	return f(ds, w, s)
+}
+
+// ExportColumns exports only the columns with the given headers.
+func (ds *Dataset) ExportColumns(format Format, w io.Writer, headers []string) error {
+	return ds.ExportWith(format, w, ExportOptions{Columns: headers})
+}
+
+// exportView returns a Dataset that shares ds's rows, tags and settings,
+// projected as described by opts, and the settings exporters render. Rows
+// are only rebuilt when columns are selected, and then hold the same
+// values rather than copies.
+func (ds *Dataset) exportView(opts ExportOptions) (*Dataset, exportSettings, error) {
+	settings := exportSettings{showTitle: opts.ShowTitle}
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return nil, settings, ErrInvalidRowIndex
+	}
+	view := *ds
+
+	if opts.Tags != nil || opts.Offset > 0 || opts.Limit > 0 {
+		selectExportRows(&view, ds, opts)
+	}
+
+	if opts.Columns != nil {
+		indices := make([]int, len(opts.Columns))
+		for i, h := range opts.Columns {
+			idx := ds.headerIndex(h)
+			if idx == -1 {
+				return nil, settings, ErrColumnNotFound
+			}
+			indices[i] = idx
+		}
+		view.headers = opts.Columns
+		rows := view.data
+		view.data = make([][]any, len(rows))
+		for i, row := range rows {
+			projected := make([]any, len(indices))
+			for j, idx := range indices {
+				projected[j] = row[idx]
+			}
+			view.data[i] = projected
+		}
+	}
+
+	if len(opts.Totals) > 0 {
+		if err := appendExportTotals(&view, opts); err != nil {
+			return nil, settings, err
+		}
+		settings.totals = true
+	}
+
+	if opts.Transpose {
+		// The totals become the last column, like any other row
+		settings.totals = false
+		return view.TransposeWith(TransposeOptions{Headers: TransposeNoHeaders, KeepHeaders: true}), settings, nil
+	}
+	return &view, settings, nil
+}
+
+// appendExportTotals appends the totals row to view without touching the
+// rows it shares with the source dataset.
+func appendExportTotals(view *Dataset, opts ExportOptions) error {
+	totals := make([]any, view.Width())
+	for h, agg := range opts.Totals {
+		idx := view.headerIndex(h)
+		if idx == -1 {
+			return ErrColumnNotFound
+		}
+		values := make([]any, len(view.data))
+		for i, row := range view.data {
+			values[i] = row[idx]
+		}
+		totals[idx] = agg.apply(values)
+	}
+	if _, ok := opts.Totals[view.headers[0]]; !ok {
+		totals[0] = "Total"
+		if opts.TotalsLabel != "" {
+			totals[0] = opts.TotalsLabel
+		}
+	}
+
+	view.data = append(slices.Clip(view.data), totals)
+	view.tags = append(slices.Clip(view.tags), nil)
+	return nil
+}
+
+// splitTotals separates the totals row of an export from the rows before
+// it. It returns ds unchanged and a nil row when there is none.
+func splitTotals(ds *Dataset, s exportSettings) (*Dataset, []any) {
+	if !s.totals || len(ds.data) == 0 {
+		return ds, nil
+	}
+	view := *ds
+	n := len(ds.data) - 1
+	view.data = ds.data[:n]
+	view.tags = ds.tags[:n]
+	return &view, ds.data[n]
+}
+
+// selectExportRows narrows view to the rows of ds matching opts.Tags and
+// the offset and limit, moving separators along with their rows.
+func selectExportRows(view, ds *Dataset, opts ExportOptions) {
+	var indices []int
+	for i := range ds.data {
+		if opts.Tags == nil || slices.ContainsFunc(opts.Tags, func(tag string) bool {
+			return slices.Contains(ds.tags[i], tag)
+		}) {
+			indices = append(indices, i)
+		}
+	}
+	indices = indices[min(opts.Offset, len(indices)):]
+	if opts.Limit > 0 && opts.Limit < len(indices) {
+		indices = indices[:opts.Limit]
+	}
+
+	view.data = make([][]any, len(indices))
+	view.tags = make([][]string, len(indices))
+	for j, i := range indices {
+		view.data[j] = ds.data[i]
+		view.tags[j] = ds.tags[i]
+	}
+	view.separators = ds.selectSeparators(indices)
+	view.comments = ds.selectComments(indices)
+}
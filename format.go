@@ -24,6 +24,11 @@ const (
 	FormatDBF      Format = "dbf"      // dBase format
 	FormatODS      Format = "ods"      // OpenDocument Spreadsheet
 	FormatXLS      Format = "xls"      // Legacy Excel format
+	FormatOrg      Format = "org"      // Emacs org-mode table
+	FormatDokuWiki Format = "dokuwiki" // DokuWiki markup
+	FormatTracWiki Format = "tracwiki" // TracWiki markup
+	FormatTablib   Format = "tablib"   // native binary format
+	FormatNDJSON   Format = "ndjson"   // newline-delimited JSON, export only
 )
 
 // Exporter is the interface for exporting a Dataset to a specific format.
@@ -62,10 +67,23 @@ func (f DatabookExporterFunc) ExportDatabook(db *Databook, w io.Writer) error {
 	return f(db, w)
 }
 
+// DatabookImporter is the interface for importing a Databook from a specific format.
+type DatabookImporter interface {
+	ImportDatabook(r io.Reader) (*Databook, error)
+}
+
+// DatabookImporterFunc is an adapter for Databook importers.
+type DatabookImporterFunc func(r io.Reader) (*Databook, error)
+
+func (f DatabookImporterFunc) ImportDatabook(r io.Reader) (*Databook, error) {
+	return f(r)
+}
+
 var (
 	exporters         = make(map[Format]Exporter)
 	importers         = make(map[Format]Importer)
 	databookExporters = make(map[Format]DatabookExporter)
+	databookImporters = make(map[Format]DatabookImporter)
 )
 
 // RegisterExporter registers an exporter for a format.
@@ -83,12 +101,21 @@ func RegisterDatabookExporter(format Format, exporter DatabookExporter) {
 	databookExporters[format] = exporter
 }
 
+// RegisterDatabookImporter registers a Databook importer for a format.
+func RegisterDatabookImporter(format Format, importer DatabookImporter) {
+	databookImporters[format] = importer
+}
+
 // Export exports the Dataset to the specified format.
 func (ds *Dataset) Export(format Format, w io.Writer) error {
 	exporter, ok := exporters[format]
 	if !ok {
 		return ErrUnsupportedFormat
 	}
+	if format != FormatTablib {
+		// The native format stores dynamic columns itself
+		ds = ds.withDynamicColumns()
+	}
 	return exporter.Export(ds, w)
 }
 
@@ -115,13 +142,22 @@ func ImportString(format Format, data string) (*Dataset, error) {
 	return Import(format, strings.NewReader(data))
 }
 
+// ImportDatabook imports data from the specified format into a new Databook.
+func ImportDatabook(format Format, r io.Reader) (*Databook, error) {
+	importer, ok := databookImporters[format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	return importer.ImportDatabook(r)
+}
+
 // Export exports the Databook to the specified format.
 func (db *Databook) Export(format Format, w io.Writer) error {
 	exporter, ok := databookExporters[format]
 	if !ok {
 		return ErrUnsupportedFormat
 	}
-	return exporter.ExportDatabook(db, w)
+	return exporter.ExportDatabook(db.withDynamicColumns(), w)
 }
 
 // ExportString exports the Databook to the specified format and returns a string.
@@ -150,3 +186,12 @@ func SupportedImportFormats() []Format {
 	}
 	return formats
 }
+
+// SupportedDatabookImportFormats returns all registered Databook import formats.
+func SupportedDatabookImportFormats() []Format {
+	formats := make([]Format, 0, len(databookImporters))
+	for f := range databookImporters {
+		formats = append(formats, f)
+	}
+	return formats
+}
@@ -0,0 +1,18 @@
+package tablib
+
+// Freeze makes the dataset read-only, for handing a reference dataset to
+// code that must not change it. Afterwards every method that modifies the
+// dataset returns ErrFrozen, or, without an error result, panics with
+// ErrFrozen: rows, cells, columns, headers, tags, comments, formats,
+// formatters, separators, constraints and the history are all frozen.
+// Methods that return a new dataset, such as Copy, Filter and Sort, still
+// work, and their results are not frozen. A dataset cannot be unfrozen; modify a
+// Copy instead. Cell values themselves, such as slices, are not frozen.
+func (ds *Dataset) Freeze() {
+	ds.frozen = true
+}
+
+// IsFrozen reports whether Freeze has been called.
+func (ds *Dataset) IsFrozen() bool {
+	return ds.frozen
+}
@@ -0,0 +1,72 @@
+package tablib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"slices"
+)
+
+// HashOptions configures HashWith.
+type HashOptions struct {
+	// IgnoreRowOrder makes the hash the same for datasets holding the
+	// same rows in any order.
+	IgnoreRowOrder bool
+}
+
+// Hash returns a hex-encoded SHA-256 fingerprint of the headers and cell
+// values, in row order. Values are hashed with their type, so 1 and "1"
+// differ. The title, tags and export settings are not included.
+func (ds *Dataset) Hash() string {
+	return ds.HashWith(HashOptions{})
+}
+
+// HashWith returns a fingerprint like Hash with custom options.
+func (ds *Dataset) HashWith(opts HashOptions) string {
+	h := sha256.New()
+	writeHashField(h, "headers")
+	binary.Write(h, binary.BigEndian, uint64(len(ds.headers)))
+	for _, header := range ds.headers {
+		writeHashField(h, header)
+	}
+
+	if !opts.IgnoreRowOrder {
+		for _, row := range ds.data {
+			writeHashRow(h, row)
+		}
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	// Hash rows separately and combine their sorted digests
+	digests := make([][]byte, len(ds.data))
+	for i, row := range ds.data {
+		rh := sha256.New()
+		writeHashRow(rh, row)
+		digests[i] = rh.Sum(nil)
+	}
+	slices.SortFunc(digests, bytes.Compare)
+	for _, d := range digests {
+		h.Write(d)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashRow writes a row to h as its length followed by each value's
+// type and text.
+func writeHashRow(h hash.Hash, row []any) {
+	binary.Write(h, binary.BigEndian, uint64(len(row)))
+	for _, v := range row {
+		writeHashField(h, fmt.Sprintf("%T", v))
+		writeHashField(h, fmt.Sprintf("%v", v))
+	}
+}
+
+// writeHashField writes s to h prefixed with its length, so that field
+// boundaries are unambiguous.
+func writeHashField(h hash.Hash, s string) {
+	binary.Write(h, binary.BigEndian, uint64(len(s)))
+	h.Write([]byte(s))
+}
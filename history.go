@@ -0,0 +1,114 @@
+package tablib
+
+// history holds the states of a dataset before its recent changes, see
+// EnableHistory.
+type history struct {
+	depth int
+	undo  []*Dataset // most recent last
+	redo  []*Dataset // most recently undone last
+}
+
+// EnableHistory records up to depth changes so that Undo and Redo can
+// revert and reapply them; a depth of zero or less disables the history
+// and discards it. Changes to rows, cells, columns and headers are
+// recorded: Append, Insert, Pop, DeleteRows, DeleteRowsWhere, Set,
+// SetRow, MapColumn, UpdateWhere, MapRows, FillNA, FillNAColumn,
+// ParseDates, Merge, AppendCol, InsertCol, DeleteCol, SetHeaders,
+// RenameColumns and Wipe, and the methods built on them. Failed changes
+// are not recorded.
+//
+// Each change records a copy of the dataset, which shares its rows as
+// Copy does, so the cost is proportional to the number of rows rather
+// than cells. Copies of the dataset do not inherit the history.
+func (ds *Dataset) EnableHistory(depth int) {
+	if ds.frozen {
+		panic(ErrFrozen)
+	}
+	if depth <= 0 {
+		ds.history = nil
+		return
+	}
+	if ds.history == nil {
+		ds.history = &history{}
+	}
+	ds.history.depth = depth
+	if n := len(ds.history.undo) - depth; n > 0 {
+		ds.history.undo = ds.history.undo[n:]
+	}
+	if n := len(ds.history.redo) - depth; n > 0 {
+		ds.history.redo = ds.history.redo[n:]
+	}
+}
+
+// CanUndo reports whether there is a change to undo.
+func (ds *Dataset) CanUndo() bool {
+	return ds.history != nil && len(ds.history.undo) > 0
+}
+
+// CanRedo reports whether there is an undone change to redo.
+func (ds *Dataset) CanRedo() bool {
+	return ds.history != nil && len(ds.history.redo) > 0
+}
+
+// Undo reverts the most recent recorded change. Without one it returns
+// ErrNoHistory.
+func (ds *Dataset) Undo() error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if !ds.CanUndo() {
+		return ErrNoHistory
+	}
+	h := ds.history
+	h.redo = append(h.redo, ds.Copy())
+	ds.restore(h.undo[len(h.undo)-1])
+	h.undo = h.undo[:len(h.undo)-1]
+	return nil
+}
+
+// Redo reapplies the most recently undone change. Any other change made
+// since discards the changes left to redo; without one Redo returns
+// ErrNoHistory.
+func (ds *Dataset) Redo() error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if !ds.CanRedo() {
+		return ErrNoHistory
+	}
+	h := ds.history
+	h.undo = append(h.undo, ds.Copy())
+	ds.restore(h.redo[len(h.redo)-1])
+	h.redo = h.redo[:len(h.redo)-1]
+	return nil
+}
+
+// record saves the state of the dataset before a change, when the history
+// is enabled. It must be called once the change is known to succeed.
+func (ds *Dataset) record() {
+	h := ds.history
+	if h == nil {
+		return
+	}
+	if len(h.undo) == h.depth {
+		h.undo[0] = nil
+		h.undo = h.undo[1:]
+	}
+	h.undo = append(h.undo, ds.Copy())
+	clear(h.redo)
+	h.redo = h.redo[:0]
+}
+
+// restore replaces the state of the dataset with a recorded copy, which
+// the dataset takes over. Settings that changes do not record, such as
+// the collected violations, are kept.
+func (ds *Dataset) restore(state *Dataset) {
+	h := ds.history
+	collect, violations := ds.collectViolations, ds.violations
+	widths := ds.widths
+	*ds = *state
+	ds.history = h
+	ds.collectViolations, ds.violations = collect, violations
+	ds.widths = widths
+	ds.dropWidths()
+}
@@ -1,56 +1,94 @@
 package tablib
 
 import (
+	"bufio"
 	"fmt"
 	"html"
 	"io"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 )
 
 func init() {
-	RegisterExporter(FormatHTML, ExporterFunc(exportHTML))
+	RegisterExporter(FormatHTML, settingsExporterFunc(exportHTML))
 }
 
-func exportHTML(ds *Dataset, w io.Writer) error {
-	var sb strings.Builder
+// htmlDefaultCSS is the built-in stylesheet used by HTMLOptions.DefaultStyle.
+const htmlDefaultCSS = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+caption { font-weight: bold; padding: 0.5em; text-align: left; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; text-align: left; vertical-align: top; }
+thead th { background: #f2f2f2; }
+tbody tr:nth-child(even) { background: #fafafa; }`
 
-	sb.WriteString("<table>\n")
-
-	// Write headers
-	if len(ds.headers) > 0 {
-		sb.WriteString("  <thead>\n    <tr>\n")
-		for _, h := range ds.headers {
-			sb.WriteString(fmt.Sprintf("      <th>%s</th>\n", html.EscapeString(h)))
-		}
-		sb.WriteString("    </tr>\n  </thead>\n")
-	}
-
-	// Write body
-	sb.WriteString("  <tbody>\n")
-	for _, row := range ds.data {
-		sb.WriteString("    <tr>\n")
-		for _, v := range row {
-			sb.WriteString(fmt.Sprintf("      <td>%s</td>\n", html.EscapeString(fmt.Sprintf("%v", v))))
-		}
-		sb.WriteString("    </tr>\n")
+func exportHTML(ds *Dataset, w io.Writer, s exportSettings) error {
+	opts := HTMLOptions{Caption: s.showTitle}
+	if rest, totals := splitTotals(ds, s); totals != nil {
+		ds, opts.Footer = rest, [][]any{totals}
 	}
-	sb.WriteString("  </tbody>\n")
-
-	sb.WriteString("</table>")
-
-	_, err := w.Write([]byte(sb.String()))
-	return err
+	return exportHTMLWithOptions(ds, w, opts)
 }
 
 // HTMLOptions configures HTML export behavior.
 type HTMLOptions struct {
 	TableClass string
 	TableID    string
+
+	// FullDocument wraps the table in a complete HTML document with a head
+	// and body. The dataset title is used as the document title and as the
+	// table caption.
+	FullDocument bool
+	// Stylesheets lists CSS URLs linked from the document head.
+	Stylesheets []string
+	// CSS is embedded in a <style> element in the document head.
+	CSS string
+	// DefaultStyle embeds a small built-in stylesheet before CSS.
+	DefaultStyle bool
+
+	// Caption renders the dataset title as the table <caption>. Full
+	// documents always include the caption.
+	Caption bool
+	// Footer rows are rendered in a <tfoot> section after the body.
+	Footer [][]any
+
+	// ColumnClasses maps a header to a class added to that column's <th>
+	// and <td> cells.
+	ColumnClasses map[string]string
+	// CellClass returns an extra class for the cell at the given row and
+	// column index, or "" for none.
+	CellClass func(row, col int, value any) string
+	// TypeAttributes adds a data-type attribute (number, boolean, date,
+	// null or string) to every <td>, and class="num" to numeric cells.
+	TypeAttributes bool
+	// TagAttributes adds the row's tags to each <tr> as data-tags.
+	TagAttributes bool
+
+	// NestedValues renders slice and array cells as <ul> lists and map
+	// cells as nested key/value tables instead of Go's %v syntax.
+	NestedValues bool
+
+	// RawColumns lists headers whose cells contain trusted HTML that is
+	// written without escaping.
+	RawColumns []string
 }
 
+// HTML marks a cell value as trusted markup. The HTML exporter writes it
+// without escaping; other exporters treat it as a plain string.
+type HTML string
+
 // ExportHTML exports the Dataset to HTML with custom options.
 func (ds *Dataset) ExportHTML(w io.Writer, opts HTMLOptions) error {
-	var sb strings.Builder
+	return exportHTMLWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+func exportHTMLWithOptions(ds *Dataset, w io.Writer, opts HTMLOptions) error {
+	bw := bufio.NewWriter(w)
+
+	if opts.FullDocument {
+		writeHTMLHead(bw, ds, opts)
+	}
 
 	tableAttrs := ""
 	if opts.TableID != "" {
@@ -60,28 +98,202 @@ func (ds *Dataset) ExportHTML(w io.Writer, opts HTMLOptions) error {
 		tableAttrs += fmt.Sprintf(` class="%s"`, html.EscapeString(opts.TableClass))
 	}
 
-	sb.WriteString(fmt.Sprintf("<table%s>\n", tableAttrs))
+	bw.WriteString(fmt.Sprintf("<table%s>\n", tableAttrs))
+
+	if (opts.Caption || opts.FullDocument) && ds.title != "" {
+		bw.WriteString(fmt.Sprintf("  <caption>%s</caption>\n", html.EscapeString(ds.title)))
+	}
 
+	// Write headers
 	if len(ds.headers) > 0 {
-		sb.WriteString("  <thead>\n    <tr>\n")
+		bw.WriteString("  <thead>\n    <tr>\n")
 		for _, h := range ds.headers {
-			sb.WriteString(fmt.Sprintf("      <th>%s</th>\n", html.EscapeString(h)))
+			bw.WriteString(fmt.Sprintf("      <th%s>%s</th>\n", htmlClassAttr(opts.ColumnClasses[h]), html.EscapeString(h)))
+		}
+		bw.WriteString("    </tr>\n  </thead>\n")
+	}
+
+	rawCols := make(map[int]bool, len(opts.RawColumns))
+	for _, h := range opts.RawColumns {
+		if idx := ds.headerIndex(h); idx != -1 {
+			rawCols[idx] = true
+		}
+	}
+
+	// Write body
+	bw.WriteString("  <tbody>\n")
+	for rowIdx, row := range ds.data {
+		rowAttrs := ""
+		if opts.TagAttributes && len(ds.tags[rowIdx]) > 0 {
+			rowAttrs = fmt.Sprintf(` data-tags="%s"`, html.EscapeString(strings.Join(ds.tags[rowIdx], " ")))
+		}
+		bw.WriteString(fmt.Sprintf("    <tr%s>\n", rowAttrs))
+		for colIdx, v := range row {
+			content := htmlCellContent(ds, colIdx, v, opts)
+			if rawCols[colIdx] {
+				content = valueString(v)
+			}
+			bw.WriteString(fmt.Sprintf("      <td%s>%s</td>\n", htmlCellAttrs(ds, opts, rowIdx, colIdx, v), content))
+		}
+		bw.WriteString("    </tr>\n")
+	}
+	bw.WriteString("  </tbody>\n")
+
+	// Write footer
+	if len(opts.Footer) > 0 {
+		bw.WriteString("  <tfoot>\n")
+		for _, row := range opts.Footer {
+			bw.WriteString("    <tr>\n")
+			for colIdx, v := range row {
+				bw.WriteString(fmt.Sprintf("      <td>%s</td>\n", htmlCellContent(ds, colIdx, v, opts)))
+			}
+			bw.WriteString("    </tr>\n")
 		}
-		sb.WriteString("    </tr>\n  </thead>\n")
+		bw.WriteString("  </tfoot>\n")
 	}
 
-	sb.WriteString("  <tbody>\n")
-	for _, row := range ds.data {
-		sb.WriteString("    <tr>\n")
-		for _, v := range row {
-			sb.WriteString(fmt.Sprintf("      <td>%s</td>\n", html.EscapeString(fmt.Sprintf("%v", v))))
+	bw.WriteString("</table>")
+
+	if opts.FullDocument {
+		bw.WriteString("\n</body>\n</html>\n")
+	}
+
+	return bw.Flush()
+}
+
+// writeHTMLHead writes the doctype, document head and opening body tag.
+func writeHTMLHead(bw *bufio.Writer, ds *Dataset, opts HTMLOptions) {
+	bw.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	bw.WriteString("<meta charset=\"utf-8\">\n")
+	if ds.title != "" {
+		bw.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(ds.title)))
+	}
+	for _, href := range opts.Stylesheets {
+		bw.WriteString(fmt.Sprintf("<link rel=\"stylesheet\" href=\"%s\">\n", html.EscapeString(href)))
+	}
+	if opts.DefaultStyle || opts.CSS != "" {
+		bw.WriteString("<style>\n")
+		if opts.DefaultStyle {
+			bw.WriteString(htmlDefaultCSS)
+			bw.WriteString("\n")
 		}
-		sb.WriteString("    </tr>\n")
+		if opts.CSS != "" {
+			// Prevent the embedded CSS from closing the style element early
+			bw.WriteString(strings.ReplaceAll(opts.CSS, "</", "<\\/"))
+			bw.WriteString("\n")
+		}
+		bw.WriteString("</style>\n")
 	}
-	sb.WriteString("  </tbody>\n")
+	bw.WriteString("</head>\n<body>\n")
+}
 
-	sb.WriteString("</table>")
+// htmlCellAttrs builds the class, data-type and title attributes for a
+// body cell. The title holds the cell comment.
+func htmlCellAttrs(ds *Dataset, opts HTMLOptions, row, col int, v any) string {
+	var classes []string
+	if col < len(ds.headers) {
+		if c := opts.ColumnClasses[ds.headers[col]]; c != "" {
+			classes = append(classes, c)
+		}
+	}
+	dataType := ""
+	if opts.TypeAttributes {
+		dataType = htmlDataType(v)
+		if dataType == "number" {
+			classes = append(classes, "num")
+		}
+	}
+	if opts.CellClass != nil {
+		if c := opts.CellClass(row, col, v); c != "" {
+			classes = append(classes, c)
+		}
+	}
 
-	_, err := w.Write([]byte(sb.String()))
-	return err
+	attrs := htmlClassAttr(strings.Join(classes, " "))
+	if dataType != "" {
+		attrs += fmt.Sprintf(` data-type="%s"`, dataType)
+	}
+	if comment := ds.CellComment(row, col); comment != "" {
+		attrs += fmt.Sprintf(` title="%s"`, html.EscapeString(comment))
+	}
+	return attrs
+}
+
+// htmlClassAttr returns a class attribute, or "" when class is empty.
+func htmlClassAttr(class string) string {
+	if class == "" {
+		return ""
+	}
+	return fmt.Sprintf(` class="%s"`, html.EscapeString(class))
+}
+
+// htmlDataType classifies a cell value for the data-type attribute.
+func htmlDataType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "date"
+	}
+	return "string"
+}
+
+// htmlCellContent renders a cell value in column col as escaped HTML,
+// applying the column's display format. HTML values are written as-is.
+func htmlCellContent(ds *Dataset, col int, v any, opts HTMLOptions) string {
+	if raw, ok := v.(HTML); ok {
+		return string(raw)
+	}
+	if text, ok := ds.formattedCell(col, v); ok {
+		return html.EscapeString(text)
+	}
+	if opts.NestedValues {
+		return htmlNestedValue(v)
+	}
+	return html.EscapeString(valueString(v))
+}
+
+// htmlNestedValue renders slices and arrays as <ul> lists and maps as
+// key/value tables, recursing into their elements.
+func htmlNestedValue(v any) string {
+	if raw, ok := v.(HTML); ok {
+		return string(raw)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			break // []byte renders as text
+		}
+		var sb strings.Builder
+		sb.WriteString("<ul>")
+		for i := 0; i < rv.Len(); i++ {
+			sb.WriteString("<li>")
+			sb.WriteString(htmlNestedValue(rv.Index(i).Interface()))
+			sb.WriteString("</li>")
+		}
+		sb.WriteString("</ul>")
+		return sb.String()
+	case reflect.Map:
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return compareAny(a.Interface(), b.Interface())
+		})
+		var sb strings.Builder
+		sb.WriteString("<table>")
+		for _, k := range keys {
+			sb.WriteString("<tr><th>")
+			sb.WriteString(html.EscapeString(fmt.Sprintf("%v", k.Interface())))
+			sb.WriteString("</th><td>")
+			sb.WriteString(htmlNestedValue(rv.MapIndex(k).Interface()))
+			sb.WriteString("</td></tr>")
+		}
+		sb.WriteString("</table>")
+		return sb.String()
+	}
+	return html.EscapeString(valueString(v))
 }
@@ -0,0 +1,98 @@
+package tablib
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// BuildIndex builds a hash index on the column with the specified header,
+// so that LookupRows finds matching rows without scanning. Append, Set and
+// SetRow keep the index up to date; any other change to the rows or
+// headers discards it, and BuildIndex must be called again. Columns with a
+// UniqueConstraint are indexed as needed to check it. Rows modified
+// through slices obtained from the dataset are not tracked.
+func (ds *Dataset) BuildIndex(header string) error {
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return ErrColumnNotFound
+	}
+	index := make(map[any][]int)
+	for i, row := range ds.data {
+		key := indexKey(row[col])
+		index[key] = append(index[key], i)
+	}
+	if ds.indexes == nil {
+		ds.indexes = make(map[string]map[any][]int)
+	}
+	ds.indexes[header] = index
+	return nil
+}
+
+// LookupRows returns the indices of the rows whose value in the column
+// with the specified header equals value, in ascending order. Values match
+// when they have the same type and value. Without an index built by
+// BuildIndex, the column is scanned. Unknown headers return nil.
+func (ds *Dataset) LookupRows(header string, value any) []int {
+	key := indexKey(value)
+	if index, ok := ds.indexes[header]; ok {
+		return slices.Clone(index[key])
+	}
+
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return nil
+	}
+	var rows []int
+	for i, row := range ds.data {
+		if indexKey(row[col]) == key {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// indexRow adds row i to the built indexes.
+func (ds *Dataset) indexRow(i int) {
+	for header, index := range ds.indexes {
+		key := indexKey(ds.data[i][ds.headerIndex(header)])
+		index[key] = append(index[key], i)
+	}
+}
+
+// reindexCell moves row i of the built index on column col, if any, from
+// the entry of old to that of the value now in the cell.
+func (ds *Dataset) reindexCell(i, col int, old any) {
+	header := ds.headers[col]
+	index, ok := ds.indexes[header]
+	if !ok || ds.headerIndex(header) != col {
+		return
+	}
+	oldKey := indexKey(old)
+	index[oldKey] = slices.DeleteFunc(index[oldKey], func(r int) bool { return r == i })
+	if len(index[oldKey]) == 0 {
+		delete(index, oldKey)
+	}
+	key := indexKey(ds.data[i][col])
+	pos, _ := slices.BinarySearch(index[key], i)
+	index[key] = slices.Insert(index[key], pos, i)
+}
+
+// dropIndexes discards the built indexes, and the cached column widths,
+// after a change to the rows.
+func (ds *Dataset) dropIndexes() {
+	ds.indexes = nil
+	ds.dropWidths()
+}
+
+// indexText is the key of a value that cannot be a map key, such as a
+// slice. It is a distinct type so that it never equals a string cell.
+type indexText string
+
+// indexKey returns the map key of a cell value.
+func indexKey(v any) any {
+	if v == nil || reflect.TypeOf(v).Comparable() {
+		return v
+	}
+	return indexText(fmt.Sprintf("%T\x00%v", v, v))
+}
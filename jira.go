@@ -1,6 +1,7 @@
 package tablib
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strings"
@@ -10,22 +11,44 @@ func init() {
 	RegisterExporter(FormatJira, ExporterFunc(exportJira))
 }
 
+// JiraOptions configures Jira export behavior.
+type JiraOptions struct {
+	// EscapeMarkup escapes every Jira text-effect character (*, _, -, +,
+	// ^, ~) as well as the characters that break table structure. By
+	// default only |, [, ], { and } are escaped so dates and phone numbers
+	// are written unchanged.
+	EscapeMarkup bool
+}
+
 // exportJira exports the Dataset to Jira Wiki markup table format.
 func exportJira(ds *Dataset, w io.Writer) error {
+	return exportJiraWithOptions(ds, w, JiraOptions{})
+}
+
+// ExportJira exports the Dataset to Jira Wiki markup with custom options.
+func (ds *Dataset) ExportJira(w io.Writer, opts JiraOptions) error {
+	return exportJiraWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+func exportJiraWithOptions(ds *Dataset, w io.Writer, opts JiraOptions) error {
 	if ds.Width() == 0 {
 		return nil
 	}
 
-	var sb strings.Builder
+	bw := bufio.NewWriter(w)
+	escapeJira := jiraTableReplacer.Replace
+	if opts.EscapeMarkup {
+		escapeJira = jiraMarkupReplacer.Replace
+	}
 
 	// Write headers (Jira uses || for header cells)
 	if len(ds.headers) > 0 {
-		sb.WriteString("||")
+		bw.WriteString("||")
 		for _, h := range ds.headers {
-			sb.WriteString(escapeJira(h))
-			sb.WriteString("||")
+			bw.WriteString(escapeJira(h))
+			bw.WriteString("||")
 		}
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 	}
 
 	// Write data rows (Jira uses | for regular cells)
@@ -33,45 +56,50 @@ func exportJira(ds *Dataset, w io.Writer) error {
 		// Check for separator before this row
 		if sep, ok := ds.GetSeparator(rowIdx); ok {
 			// Jira doesn't have native separators, use a spanning row with emphasis
-			sb.WriteString("|")
-			sb.WriteString(fmt.Sprintf("*%s*", escapeJira(sep.Text)))
-			sb.WriteString("|\n")
+			bw.WriteString("|")
+			bw.WriteString(fmt.Sprintf("*%s*", escapeJira(sep.Text)))
+			bw.WriteString("|\n")
 		}
 
-		sb.WriteString("|")
-		for _, v := range row {
-			sb.WriteString(escapeJira(fmt.Sprintf("%v", v)))
-			sb.WriteString("|")
+		bw.WriteString("|")
+		for i, v := range row {
+			bw.WriteString(escapeJira(ds.cellText(i, v)))
+			bw.WriteString("|")
 		}
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 	}
 
 	// Check for separator after the last row
 	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
-		sb.WriteString("|")
-		sb.WriteString(fmt.Sprintf("*%s*", escapeJira(sep.Text)))
-		sb.WriteString("|\n")
+		bw.WriteString("|")
+		bw.WriteString(fmt.Sprintf("*%s*", escapeJira(sep.Text)))
+		bw.WriteString("|\n")
 	}
 
-	_, err := w.Write([]byte(sb.String()))
-	return err
+	return bw.Flush()
 }
 
-// escapeJira escapes special characters for Jira Wiki markup.
-func escapeJira(s string) string {
-	// Escape special Jira characters
-	replacer := strings.NewReplacer(
-		"|", "\\|",
-		"[", "\\[",
-		"]", "\\]",
-		"{", "\\{",
-		"}", "\\}",
-		"*", "\\*",
-		"_", "\\_",
-		"-", "\\-",
-		"+", "\\+",
-		"^", "\\^",
-		"~", "\\~",
-	)
-	return replacer.Replace(s)
-}
+// jiraTableReplacer escapes the characters that break Jira table cells:
+// cell delimiters, links and macros.
+var jiraTableReplacer = strings.NewReplacer(
+	"|", "\\|",
+	"[", "\\[",
+	"]", "\\]",
+	"{", "\\{",
+	"}", "\\}",
+)
+
+// jiraMarkupReplacer additionally escapes Jira text effects.
+var jiraMarkupReplacer = strings.NewReplacer(
+	"|", "\\|",
+	"[", "\\[",
+	"]", "\\]",
+	"{", "\\{",
+	"}", "\\}",
+	"*", "\\*",
+	"_", "\\_",
+	"-", "\\-",
+	"+", "\\+",
+	"^", "\\^",
+	"~", "\\~",
+)
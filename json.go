@@ -8,7 +8,9 @@ import (
 func init() {
 	RegisterExporter(FormatJSON, ExporterFunc(exportJSON))
 	RegisterImporter(FormatJSON, ImporterFunc(importJSON))
+	RegisterExporter(FormatNDJSON, ExporterFunc(exportNDJSON))
 	RegisterDatabookExporter(FormatJSON, DatabookExporterFunc(exportDatabookJSON))
+	RegisterDatabookImporter(FormatJSON, DatabookImporterFunc(importDatabookJSON))
 }
 
 func exportJSON(ds *Dataset, w io.Writer) error {
@@ -28,6 +30,30 @@ func exportJSON(ds *Dataset, w io.Writer) error {
 	return encoder.Encode(ds.Records())
 }
 
+// exportNDJSON writes one JSON value per row: an object keyed by header,
+// as in JSON exports, or an array when there are no headers.
+func exportNDJSON(ds *Dataset, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, row := range ds.data {
+		if err := encodeNDJSONRow(encoder, ds.headers, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeNDJSONRow writes a row as a line of NDJSON.
+func encodeNDJSONRow(encoder *json.Encoder, headers []string, row []any) error {
+	if len(headers) == 0 {
+		return encoder.Encode(row)
+	}
+	obj := make(map[string]any, len(headers))
+	for i, h := range headers {
+		obj[h] = row[i]
+	}
+	return encoder.Encode(obj)
+}
+
 func importJSON(r io.Reader) (*Dataset, error) {
 	decoder := json.NewDecoder(r)
 
@@ -123,3 +149,37 @@ func exportDatabookJSON(db *Databook, w io.Writer) error {
 
 	return encoder.Encode(result)
 }
+
+// importDatabookJSON reads the sheet list written by the Databook JSON
+// exporter: an array of objects with a title and data records.
+func importDatabookJSON(r io.Reader) (*Databook, error) {
+	var sheets []struct {
+		Title string          `json:"title"`
+		Data  json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&sheets); err != nil {
+		return nil, ErrInvalidData
+	}
+
+	db := NewDatabook()
+	for _, sheet := range sheets {
+		var ds *Dataset
+		var objects []map[string]any
+		var arrays [][]any
+		var err error
+		switch {
+		case json.Unmarshal(sheet.Data, &objects) == nil:
+			ds, err = importJSONObjects(objects)
+		case json.Unmarshal(sheet.Data, &arrays) == nil:
+			ds, err = importJSONArrays(arrays)
+		default:
+			err = ErrInvalidData
+		}
+		if err != nil {
+			return nil, err
+		}
+		ds.SetTitle(sheet.Title)
+		db.AddSheet(ds)
+	}
+	return db, nil
+}
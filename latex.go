@@ -1,53 +1,193 @@
 package tablib
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strings"
 )
 
 func init() {
-	RegisterExporter(FormatLatex, ExporterFunc(exportLatex))
+	RegisterExporter(FormatLatex, settingsExporterFunc(exportLatex))
 }
 
-func exportLatex(ds *Dataset, w io.Writer) error {
+// LatexOptions configures LaTeX export behavior.
+type LatexOptions struct {
+	// Booktabs uses the booktabs package rules (\toprule, \midrule,
+	// \bottomrule) instead of \hline.
+	Booktabs bool
+
+	// Longtable uses the longtable environment, which breaks across pages
+	// and repeats the header on each one.
+	Longtable bool
+	// Caption renders the dataset title as the table caption. Longtables
+	// repeat it on later pages marked "(continued)"; a plain tabular needs
+	// Float for the caption to be written.
+	Caption bool
+	// Label is written as \label after the caption for cross-references.
+	Label string
+
+	// ColumnSpec overrides the column specification, e.g. "lrp{5cm}". By
+	// default it is derived from the column alignment hints.
+	ColumnSpec string
+
+	// Float wraps a tabular in a table float environment with the caption
+	// and label. It has no effect on longtables, which float themselves.
+	Float bool
+	// Placement is the float placement specifier, e.g. "htbp".
+	Placement string
+}
+
+// ExportLatex exports the Dataset to LaTeX with custom options.
+func (ds *Dataset) ExportLatex(w io.Writer, opts LatexOptions) error {
+	return exportLatexWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+func exportLatex(ds *Dataset, w io.Writer, s exportSettings) error {
+	return exportLatexWithOptions(ds, w, LatexOptions{Caption: s.showTitle, Float: s.showTitle})
+}
+
+func exportLatexWithOptions(ds *Dataset, w io.Writer, opts LatexOptions) error {
 	if ds.Width() == 0 {
 		return nil
 	}
 
-	var sb strings.Builder
+	bw := bufio.NewWriter(w)
+
+	topRule, midRule, bottomRule := "\\hline", "\\hline", "\\hline"
+	if opts.Booktabs {
+		topRule, midRule, bottomRule = "\\toprule", "\\midrule", "\\bottomrule"
+	}
+
+	env := "tabular"
+	if opts.Longtable {
+		env = "longtable"
+	}
+
+	caption := ""
+	if opts.Caption && ds.title != "" {
+		caption = escapeLatex(ds.title)
+	}
+	label := ""
+	if opts.Label != "" {
+		label = fmt.Sprintf("\\label{%s}", opts.Label)
+	}
+
+	float := opts.Float && !opts.Longtable
+	if float {
+		placement := ""
+		if opts.Placement != "" {
+			placement = fmt.Sprintf("[%s]", opts.Placement)
+		}
+		bw.WriteString(fmt.Sprintf("\\begin{table}%s\n", placement))
+		bw.WriteString("\\centering\n")
+		if caption != "" {
+			bw.WriteString(fmt.Sprintf("\\caption{%s}\n", caption))
+		}
+		if label != "" {
+			bw.WriteString(label + "\n")
+		}
+	}
 
 	// Begin tabular environment
-	cols := strings.Repeat("l", ds.Width())
-	sb.WriteString(fmt.Sprintf("\\begin{tabular}{%s}\n", cols))
-	sb.WriteString("\\hline\n")
-
-	// Write headers
-	if len(ds.headers) > 0 {
-		escaped := make([]string, len(ds.headers))
-		for i, h := range ds.headers {
-			escaped[i] = escapeLatex(h)
+	bw.WriteString(fmt.Sprintf("\\begin{%s}{%s}\n", env, latexColumnSpec(ds, opts)))
+
+	// writeHead writes the top rule and header row
+	writeHead := func() {
+		bw.WriteString(topRule + "\n")
+		if len(ds.headers) > 0 {
+			bw.WriteString(latexRow(ds.headers))
+			bw.WriteString(midRule + "\n")
+		}
+	}
+
+	if opts.Longtable {
+		// The head is repeated on every page, with a continued caption
+		if caption != "" {
+			bw.WriteString(fmt.Sprintf("\\caption{%s}%s \\\\\n", caption, label))
+		}
+		writeHead()
+		bw.WriteString("\\endfirsthead\n")
+		if caption != "" {
+			bw.WriteString(fmt.Sprintf("\\caption[]{%s (continued)} \\\\\n", caption))
+		}
+		writeHead()
+		bw.WriteString("\\endhead\n")
+		bw.WriteString(midRule + "\n")
+		bw.WriteString(fmt.Sprintf("\\multicolumn{%d}{r}{Continued on next page} \\\\\n", ds.Width()))
+		bw.WriteString("\\endfoot\n")
+		bw.WriteString(bottomRule + "\n")
+		bw.WriteString("\\endlastfoot\n")
+	} else {
+		writeHead()
+	}
+
+	// writeSection writes a separator as a bold row spanning all columns,
+	// set off by rules where another rule doesn't already follow
+	writeSection := func(text string, ruleBefore, ruleAfter bool) {
+		if ruleBefore {
+			bw.WriteString(midRule + "\n")
+		}
+		bw.WriteString(fmt.Sprintf("\\multicolumn{%d}{l}{\\textbf{%s}} \\\\\n", ds.Width(), escapeLatex(text)))
+		if ruleAfter {
+			bw.WriteString(midRule + "\n")
 		}
-		sb.WriteString(strings.Join(escaped, " & "))
-		sb.WriteString(" \\\\\n")
-		sb.WriteString("\\hline\n")
 	}
 
 	// Write data rows
-	for _, row := range ds.data {
-		escaped := make([]string, len(row))
-		for i, v := range row {
-			escaped[i] = escapeLatex(fmt.Sprintf("%v", v))
+	for rowIdx, row := range ds.data {
+		// Check for separator before this row
+		if sep, ok := ds.GetSeparator(rowIdx); ok {
+			writeSection(sep.Text, rowIdx > 0, true)
 		}
-		sb.WriteString(strings.Join(escaped, " & "))
-		sb.WriteString(" \\\\\n")
+
+		bw.WriteString(latexRow(ds.cellTexts(row)))
 	}
 
-	sb.WriteString("\\hline\n")
-	sb.WriteString("\\end{tabular}")
+	// Check for separator after the last row
+	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
+		writeSection(sep.Text, true, false)
+	}
 
-	_, err := w.Write([]byte(sb.String()))
-	return err
+	if !opts.Longtable {
+		bw.WriteString(bottomRule + "\n")
+	}
+	bw.WriteString(fmt.Sprintf("\\end{%s}", env))
+	if float {
+		bw.WriteString("\n\\end{table}")
+	}
+
+	return bw.Flush()
+}
+
+// latexColumnSpec returns the column specification for the tabular
+// environment, mapping alignment hints to l, c and r.
+func latexColumnSpec(ds *Dataset, opts LatexOptions) string {
+	if opts.ColumnSpec != "" {
+		return opts.ColumnSpec
+	}
+	spec := make([]byte, ds.Width())
+	for i := range spec {
+		spec[i] = 'l'
+		if i < len(ds.headers) {
+			switch ds.Alignment(ds.headers[i]) {
+			case AlignCenter:
+				spec[i] = 'c'
+			case AlignRight:
+				spec[i] = 'r'
+			}
+		}
+	}
+	return string(spec)
+}
+
+// latexRow escapes cells and joins them into a table row.
+func latexRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = escapeLatex(c)
+	}
+	return strings.Join(escaped, " & ") + " \\\\\n"
 }
 
 // escapeLatex escapes special LaTeX characters.
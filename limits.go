@@ -0,0 +1,174 @@
+package tablib
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+)
+
+// ImportLimits guards imports of untrusted input. Zero fields are not
+// enforced.
+//
+// MaxBytes and MaxExpansionRatio are checked before parsing, and together
+// bound the memory an import can use. The row, column and cell limits are
+// checked on the imported data.
+type ImportLimits struct {
+	// MaxBytes is the largest input accepted, in bytes.
+	MaxBytes int64
+	// MaxExpansionRatio is the largest ratio of uncompressed to compressed
+	// size accepted for ZIP-based formats such as XLSX, ODS and Databook
+	// CSV archives.
+	MaxExpansionRatio float64
+
+	MaxRows    int
+	MaxColumns int
+	// MaxCellSize is the longest text cell accepted, in bytes.
+	MaxCellSize int
+}
+
+// ImportWithLimits imports data from the specified format into a new
+// Dataset, returning ErrImportLimit if the input exceeds limits.
+func ImportWithLimits(format Format, r io.Reader, limits ImportLimits) (*Dataset, error) {
+	importer, ok := importers[format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	var ds *Dataset
+	err := limits.guard(r, func(r io.Reader) (err error) {
+		ds, err = importer.Import(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.check(ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// ImportDatabookWithLimits imports data from the specified format into a
+// new Databook, returning ErrImportLimit if the input or any sheet exceeds
+// limits.
+func ImportDatabookWithLimits(format Format, r io.Reader, limits ImportLimits) (*Databook, error) {
+	importer, ok := databookImporters[format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	var db *Databook
+	err := limits.guard(r, func(r io.Reader) (err error) {
+		db, err = importer.ImportDatabook(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range db.sheets {
+		if err := limits.check(ds); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// guard calls parse with r bounded by MaxBytes. When MaxExpansionRatio is
+// set the input is buffered and, if it is a ZIP archive, its declared
+// sizes are checked first; the archive reader rejects members that
+// decompress beyond their declared size.
+func (l ImportLimits) guard(r io.Reader, parse func(io.Reader) error) error {
+	lr := &importLimitReader{r: r, remaining: l.MaxBytes}
+	if l.MaxBytes > 0 {
+		r = lr
+	}
+
+	if l.MaxExpansionRatio > 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if err := l.checkZIP(data); err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+
+	if err := parse(r); err != nil {
+		if lr.exceeded {
+			return ErrImportLimit
+		}
+		return err
+	}
+	return nil
+}
+
+// checkZIP checks the expansion ratio of data if it is a ZIP archive.
+func (l ImportLimits) checkZIP(data []byte) error {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil // not for us to reject; the importer reports it
+	}
+	var total uint64
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if f.CompressedSize64 > 0 && float64(f.UncompressedSize64)/float64(f.CompressedSize64) > l.MaxExpansionRatio {
+			return ErrImportLimit
+		}
+	}
+	if float64(total)/float64(len(data)) > l.MaxExpansionRatio {
+		return ErrImportLimit
+	}
+	return nil
+}
+
+// check checks the size of an imported Dataset.
+func (l ImportLimits) check(ds *Dataset) error {
+	if l.MaxRows > 0 && ds.Height() > l.MaxRows {
+		return ErrImportLimit
+	}
+	if l.MaxColumns > 0 && ds.Width() > l.MaxColumns {
+		return ErrImportLimit
+	}
+	if l.MaxCellSize > 0 {
+		for _, h := range ds.headers {
+			if len(h) > l.MaxCellSize {
+				return ErrImportLimit
+			}
+		}
+		for _, row := range ds.data {
+			for _, v := range row {
+				if s, ok := v.(string); ok && len(s) > l.MaxCellSize {
+					return ErrImportLimit
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// importLimitReader reads from r until more than remaining bytes have been
+// read, then fails with ErrImportLimit.
+type importLimitReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (lr *importLimitReader) Read(p []byte) (int, error) {
+	if lr.exceeded {
+		return 0, ErrImportLimit
+	}
+	// Read one byte past the limit to tell a full input from a long one
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[:lr.remaining+1]
+	}
+	n, err := lr.r.Read(p)
+	lr.remaining -= int64(n)
+	if lr.remaining < 0 {
+		lr.exceeded = true
+		return 0, ErrImportLimit
+	}
+	return n, err
+}
@@ -1,70 +1,205 @@
 package tablib
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strings"
 )
 
 func init() {
-	RegisterExporter(FormatMarkdown, ExporterFunc(exportMarkdown))
+	RegisterExporter(FormatMarkdown, settingsExporterFunc(exportMarkdown))
 }
 
-func exportMarkdown(ds *Dataset, w io.Writer) error {
+// MarkdownOptions configures Markdown export behavior.
+type MarkdownOptions struct {
+	// Title writes the dataset title as a "##" heading before the table.
+	Title bool
+
+	// Compact disables padding cells to the widest value in their column,
+	// keeping output small for very wide or long datasets.
+	Compact bool
+	// SampleRows limits the rows measured for padding to the first
+	// SampleRows, skipping a full pass over long datasets. Longer cells in
+	// later rows are written unpadded. Zero measures every row.
+	SampleRows int
+
+	// Separators controls how dataset separators are rendered.
+	Separators MarkdownSeparatorStyle
+
+	// EastAsianWidth counts ambiguous-width characters as two cells when
+	// padding columns.
+	EastAsianWidth bool
+}
+
+// MarkdownSeparatorStyle selects how separators appear in Markdown output.
+type MarkdownSeparatorStyle int
+
+const (
+	// MarkdownSeparatorRow renders a separator as a row with its text in
+	// bold in the first cell. This is the default.
+	MarkdownSeparatorRow MarkdownSeparatorStyle = iota
+	// MarkdownSeparatorHeading splits the table at each separator and writes
+	// the separator text as a "###" heading between the chunks, repeating
+	// the header row in each chunk.
+	MarkdownSeparatorHeading
+	// MarkdownSeparatorNone omits separators.
+	MarkdownSeparatorNone
+)
+
+func exportMarkdown(ds *Dataset, w io.Writer, s exportSettings) error {
+	return exportMarkdownWithOptions(ds, w, MarkdownOptions{Title: s.showTitle})
+}
+
+// ExportMarkdown exports the Dataset to Markdown with custom options.
+func (ds *Dataset) ExportMarkdown(w io.Writer, opts MarkdownOptions) error {
+	return exportMarkdownWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+func exportMarkdownWithOptions(ds *Dataset, w io.Writer, opts MarkdownOptions) error {
 	if ds.Width() == 0 {
 		return nil
 	}
 
-	var sb strings.Builder
+	bw := bufio.NewWriter(w)
+	cond := newWidthCondition(opts.EastAsianWidth)
 
-	// Calculate column widths
-	widths := make([]int, ds.Width())
+	if opts.Title && ds.title != "" {
+		bw.WriteString(fmt.Sprintf("## %s\n\n", ds.title))
+	}
+
+	// Cells are escaped before measuring so widths account for escape
+	// sequences
+	headers := make([]string, len(ds.headers))
 	for i, h := range ds.headers {
-		if len(h) > widths[i] {
-			widths[i] = len(h)
-		}
+		headers[i] = escapeMarkdown(h)
 	}
-	for _, row := range ds.data {
+	rowCells := func(row []any) []string {
+		cells := make([]string, len(row))
 		for i, v := range row {
-			s := fmt.Sprintf("%v", v)
-			if len(s) > widths[i] {
-				widths[i] = len(s)
-			}
+			cells[i] = escapeMarkdown(ds.cellText(i, v))
+		}
+		return cells
+	}
+
+	// Separator rows occupy the first column
+	sepCells := make(map[int]string)
+	if opts.Separators == MarkdownSeparatorRow {
+		for idx, sep := range ds.separators {
+			sepCells[idx] = "**" + escapeMarkdown(sep.Text) + "**"
+		}
+	}
+
+	// Calculate column widths; compact output only pads the separator
+	widths := make([]int, ds.Width())
+	if !opts.Compact {
+		widths = ds.rowWidths(widthKind{eastAsian: opts.EastAsianWidth, markdown: true}, opts.SampleRows)
+		measureCells(widths, headers, cond)
+		for _, s := range sepCells {
+			widths[0] = max(widths[0], cond.StringWidth(s))
 		}
 	}
 
 	// Ensure minimum width of 3 for separator
+	rules := make([]int, len(widths))
 	for i := range widths {
-		if widths[i] < 3 {
-			widths[i] = 3
+		rules[i] = max(widths[i], 3)
+		if !opts.Compact {
+			widths[i] = rules[i]
 		}
 	}
 
-	// Write headers
-	if len(ds.headers) > 0 {
-		sb.WriteString("|")
-		for i, h := range ds.headers {
-			sb.WriteString(fmt.Sprintf(" %-*s |", widths[i], h))
+	aligns := make([]Alignment, ds.Width())
+	for i, h := range ds.headers {
+		aligns[i] = ds.Alignment(h)
+	}
+
+	writeHeader := func() {
+		if len(headers) == 0 {
+			return
+		}
+		bw.WriteString("|")
+		for i, h := range headers {
+			bw.WriteString(fmt.Sprintf(" %s |", alignText(h, widths[i], aligns[i], cond)))
 		}
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 
 		// Write separator
-		sb.WriteString("|")
-		for _, w := range widths {
-			sb.WriteString(fmt.Sprintf(" %s |", strings.Repeat("-", w)))
+		bw.WriteString("|")
+		for i, w := range rules {
+			bw.WriteString(fmt.Sprintf(" %s |", markdownRule(w, aligns[i])))
 		}
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 	}
 
-	// Write data rows
-	for _, row := range ds.data {
-		sb.WriteString("|")
-		for i, v := range row {
-			sb.WriteString(fmt.Sprintf(" %-*v |", widths[i], v))
+	writeRow := func(row []string) {
+		bw.WriteString("|")
+		for i, s := range row {
+			bw.WriteString(fmt.Sprintf(" %s |", alignText(s, widths[i], aligns[i], cond)))
+		}
+		bw.WriteString("\n")
+	}
+
+	// writeSeparator renders the separator before row index idx, if any.
+	writeSeparator := func(idx int) {
+		sep, ok := ds.GetSeparator(idx)
+		if !ok {
+			return
+		}
+		switch opts.Separators {
+		case MarkdownSeparatorRow:
+			row := make([]string, ds.Width())
+			row[0] = sepCells[idx]
+			writeRow(row)
+		case MarkdownSeparatorHeading:
+			if idx > 0 {
+				bw.WriteString("\n")
+			}
+			bw.WriteString(fmt.Sprintf("### %s\n\n", sep.Text))
+			if idx < len(ds.data) {
+				writeHeader()
+			}
 		}
-		sb.WriteString("\n")
 	}
 
-	_, err := w.Write([]byte(sb.String()))
-	return err
+	// Write headers
+	if opts.Separators != MarkdownSeparatorHeading || !ds.HasSeparator(0) {
+		writeHeader()
+	}
+
+	// Write data rows
+	for rowIdx, row := range ds.data {
+		writeSeparator(rowIdx)
+		writeRow(rowCells(row))
+	}
+	writeSeparator(len(ds.data))
+
+	return bw.Flush()
+}
+
+// markdownRule returns the header separator for a column, using colons to
+// mark left (:---), center (:---:) and right (---:) alignment.
+func markdownRule(width int, align Alignment) string {
+	switch align {
+	case AlignLeft:
+		return ":" + strings.Repeat("-", width-1)
+	case AlignCenter:
+		return ":" + strings.Repeat("-", width-2) + ":"
+	case AlignRight:
+		return strings.Repeat("-", width-1) + ":"
+	}
+	return strings.Repeat("-", width)
+}
+
+// escapeMarkdown escapes characters that would break a Markdown table cell:
+// pipes are backslash-escaped and line breaks become <br>.
+func escapeMarkdown(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"|", "\\|",
+		"\r\n", "<br>",
+		"\n", "<br>",
+		"\r", "<br>",
+	)
+	return replacer.Replace(s)
 }
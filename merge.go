@@ -0,0 +1,139 @@
+package tablib
+
+import (
+	"maps"
+	"slices"
+)
+
+// MergeStrategy resolves a key found in both datasets during Merge. It
+// receives copies of the existing row and the incoming row, both in the
+// receiver's column order, and returns the row to keep.
+type MergeStrategy func(left, right []any) []any
+
+// PreferLeft is a MergeStrategy that keeps the existing row.
+func PreferLeft(left, right []any) []any {
+	return left
+}
+
+// PreferRight is a MergeStrategy that replaces the existing row with the
+// incoming one.
+func PreferRight(left, right []any) []any {
+	return right
+}
+
+// Merge upserts the rows of other into the dataset by the column with the
+// specified header. Rows whose key is not yet present are appended with
+// their tags; rows whose key is present are resolved with strategy, which
+// defaults to PreferRight when nil, and keep their tags. Every row sharing
+// the key is resolved, and incoming rows are applied in order, so a key
+// repeated in other resolves against the already merged row.
+//
+// Columns are matched by header, and other must have every header of the
+// dataset (ErrColumnNotFound otherwise); extra columns are ignored. Keys
+// match when they have the same type and value. A strategy returning a
+// row of the wrong width gives ErrInvalidDimensions. On error the dataset
+// is unchanged.
+func (ds *Dataset) Merge(other *Dataset, keyHeader string, strategy MergeStrategy) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	key := ds.headerIndex(keyHeader)
+	if key == -1 {
+		return ErrColumnNotFound
+	}
+	cols := make([]int, len(ds.headers))
+	for i, h := range ds.headers {
+		cols[i] = other.headerIndex(h)
+		if cols[i] == -1 {
+			return ErrColumnNotFound
+		}
+	}
+	if strategy == nil {
+		strategy = PreferRight
+	}
+
+	data := slices.Clone(ds.data)
+	tags := slices.Clone(ds.tags)
+	merged := make([]bool, len(data))
+	positions := make(map[any][]int)
+	for i, row := range data {
+		k := indexKey(row[key])
+		positions[k] = append(positions[k], i)
+	}
+
+	for i, row := range other.data {
+		incoming := make([]any, len(cols))
+		for j, col := range cols {
+			incoming[j] = row[col]
+		}
+		k := indexKey(incoming[key])
+		rows, ok := positions[k]
+		if !ok {
+			positions[k] = []int{len(data)}
+			data = append(data, incoming)
+			tags = append(tags, slices.Clone(other.tags[i]))
+			continue
+		}
+		for _, r := range rows {
+			resolved := strategy(slices.Clone(data[r]), slices.Clone(incoming))
+			if len(resolved) != len(ds.headers) {
+				return ErrInvalidDimensions
+			}
+			data[r] = slices.Clone(resolved)
+			if r < len(merged) {
+				merged[r] = true
+			}
+		}
+	}
+	if len(ds.constraints) > 0 {
+		var changes []cellChange
+		for i, row := range data {
+			if i >= len(merged) || merged[i] {
+				changes = ds.rowChanges(changes, i, row)
+			}
+		}
+		if err := ds.enforceConstraints(ds.changeViolations(changes)); err != nil {
+			return err
+		}
+	}
+
+	ds.record()
+	ds.data = data
+	ds.tags = tags
+	ds.dropIndexes()
+	return nil
+}
+
+// CrossJoin returns the cartesian product of the dataset and other: one
+// row for every pair of rows, holding the row from the dataset followed by
+// the row from other, under the combined headers. Each row carries the
+// tags of both source rows. The result keeps the title and dynamic
+// columns of the dataset, and the alignments and column formats of both.
+func (ds *Dataset) CrossJoin(other *Dataset) *Dataset {
+	result := NewDataset(append(slices.Clone(ds.headers), other.headers...))
+	result.title = ds.title
+	result.dynamicCols = slices.Clone(ds.dynamicCols)
+	maps.Copy(result.alignments, other.alignments)
+	maps.Copy(result.alignments, ds.alignments)
+	maps.Copy(result.formats, other.formats)
+	maps.Copy(result.columnTags, other.columnTags)
+	maps.Copy(result.formats, ds.formats)
+	maps.Copy(result.columnTags, ds.columnTags)
+
+	height := len(ds.data) * len(other.data)
+	result.data = make([][]any, 0, height)
+	result.tags = make([][]string, 0, height)
+	for i, left := range ds.data {
+		for j, right := range other.data {
+			result.data = append(result.data, slices.Concat(left, right))
+			tags := append([]string{}, ds.tags[i]...)
+			for _, t := range other.tags[j] {
+				if !slices.Contains(tags, t) {
+					tags = append(tags, t)
+				}
+			}
+			result.tags = append(result.tags, tags)
+		}
+	}
+	return result
+}
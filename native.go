@@ -0,0 +1,553 @@
+package tablib
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// The native format (FormatTablib) stores a Dataset without loss: typed
+// cells, row tags, separators, the title, column alignments and display
+// formats, column tags, cell comments, and the names and positions of
+// dynamic columns. Dynamic column functions cannot be stored, so imported
+// dynamic columns compute nil until they are registered again with
+// AddDynamicColumn.
+//
+// A file starts with the magic "TBLB" and a version byte, followed by
+// varint-prefixed fields. Readers reject versions newer than their own.
+
+func init() {
+	RegisterExporter(FormatTablib, ExporterFunc(exportNative))
+	RegisterImporter(FormatTablib, ImporterFunc(importNative))
+}
+
+const (
+	nativeMagic   = "TBLB"
+	nativeVersion = 3 // 2 adds dynamic column positions, 3 cell comments and column tags
+)
+
+// Cell type tags
+const (
+	nativeNil byte = iota
+	nativeBool
+	nativeInt
+	nativeInt8
+	nativeInt16
+	nativeInt32
+	nativeInt64
+	nativeUint
+	nativeUint8
+	nativeUint16
+	nativeUint32
+	nativeUint64
+	nativeFloat32
+	nativeFloat64
+	nativeString
+	nativeBytes
+	nativeTime
+	nativeFormula
+	nativeHTML
+)
+
+func exportNative(ds *Dataset, w io.Writer) error {
+	nw := &nativeWriter{w: bufio.NewWriter(w)}
+	nw.raw([]byte(nativeMagic))
+	nw.raw([]byte{nativeVersion})
+	nw.string(ds.title)
+
+	nw.uvarint(uint64(len(ds.headers)))
+	for _, h := range ds.headers {
+		nw.string(h)
+	}
+
+	nw.uvarint(uint64(len(ds.data)))
+	for i, row := range ds.data {
+		nw.uvarint(uint64(len(row)))
+		for _, v := range row {
+			nw.value(v)
+		}
+		nw.uvarint(uint64(len(ds.tags[i])))
+		for _, tag := range ds.tags[i] {
+			nw.string(tag)
+		}
+	}
+
+	nw.uvarint(uint64(len(ds.separators)))
+	for _, idx := range slices.Sorted(maps.Keys(ds.separators)) {
+		nw.uvarint(uint64(idx))
+		nw.string(ds.separators[idx].Text)
+	}
+
+	nw.uvarint(uint64(len(ds.alignments)))
+	for _, h := range slices.Sorted(maps.Keys(ds.alignments)) {
+		nw.string(h)
+		nw.uvarint(uint64(ds.alignments[h]))
+	}
+
+	nw.uvarint(uint64(len(ds.formats)))
+	for _, h := range slices.Sorted(maps.Keys(ds.formats)) {
+		nw.string(h)
+		nw.string(ds.formats[h].spec)
+	}
+
+	nw.uvarint(uint64(len(ds.dynamicCols)))
+	for _, dc := range ds.dynamicCols {
+		nw.string(dc.header)
+		nw.varint(int64(dc.before))
+	}
+
+	keys := slices.SortedFunc(maps.Keys(ds.comments), func(a, b cellKey) int {
+		return cmp.Or(cmp.Compare(a.row, b.row), cmp.Compare(a.header, b.header))
+	})
+	nw.uvarint(uint64(len(keys)))
+	for _, key := range keys {
+		nw.uvarint(uint64(key.row))
+		nw.string(key.header)
+		nw.string(ds.comments[key])
+	}
+
+	nw.uvarint(uint64(len(ds.columnTags)))
+	for _, h := range slices.Sorted(maps.Keys(ds.columnTags)) {
+		nw.string(h)
+		nw.uvarint(uint64(len(ds.columnTags[h])))
+		for _, tag := range ds.columnTags[h] {
+			nw.string(tag)
+		}
+	}
+
+	if nw.err != nil {
+		return nw.err
+	}
+	return nw.w.Flush()
+}
+
+func importNative(r io.Reader) (*Dataset, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	nr := newNativeReader(bytes.NewReader(data), int64(len(data)))
+	magic := nr.raw(len(nativeMagic) + 1)
+	if nr.err != nil || string(magic[:len(nativeMagic)]) != nativeMagic {
+		return nil, ErrInvalidData
+	}
+	version := magic[len(nativeMagic)]
+	if version > nativeVersion {
+		return nil, ErrUnsupportedFormat
+	}
+
+	title := nr.string()
+	headers := make([]string, nr.count())
+	for i := range headers {
+		headers[i] = nr.string()
+	}
+	if nr.err != nil {
+		return nil, nr.err
+	}
+	ds := NewDataset(headers)
+	ds.title = title
+
+	rows := nr.count()
+	for range rows {
+		if nr.err != nil {
+			break
+		}
+		row := make([]any, nr.count())
+		for i := range row {
+			row[i] = nr.value()
+		}
+		tags := make([]string, nr.count())
+		for i := range tags {
+			tags[i] = nr.string()
+		}
+		if nr.err != nil {
+			break
+		}
+		if len(headers) > 0 && len(row) != len(headers) {
+			return nil, ErrInvalidData
+		}
+		ds.data = append(ds.data, row)
+		ds.tags = append(ds.tags, tags)
+	}
+
+	for range nr.count() {
+		idx := nr.uvarint()
+		text := nr.string()
+		if nr.err != nil {
+			break
+		}
+		if idx > uint64(len(ds.data)) {
+			return nil, ErrInvalidData
+		}
+		ds.separators[int(idx)] = Separator{Text: text}
+	}
+	for range nr.count() {
+		h := nr.string()
+		align := Alignment(nr.uvarint())
+		if nr.err != nil {
+			break
+		}
+		ds.alignments[h] = align
+	}
+	for range nr.count() {
+		if nr.err != nil {
+			break
+		}
+		h := nr.string()
+		spec := nr.string()
+		if nr.err != nil {
+			break
+		}
+		nf, err := parseNumberFormat(spec)
+		if err != nil {
+			return nil, err
+		}
+		ds.formats[h] = columnFormat{spec: spec, nf: nf}
+	}
+	last := 0
+	for range nr.count() {
+		dc := dynamicColumn{header: nr.string(), fn: func([]any) any { return nil }, before: -1}
+		if version >= 2 {
+			dc.before = int(nr.varint())
+		}
+		if nr.err != nil {
+			break
+		}
+		// Positions must be in column order, with -1 last
+		if dc.before < -1 || last == -1 && dc.before != -1 || dc.before != -1 && dc.before < last {
+			return nil, ErrInvalidData
+		}
+		last = dc.before
+		ds.dynamicCols = append(ds.dynamicCols, dc)
+	}
+	if version >= 3 {
+		for range nr.count() {
+			row := nr.uvarint()
+			key := cellKey{header: nr.string()}
+			text := nr.string()
+			if nr.err != nil {
+				break
+			}
+			if row >= uint64(len(ds.data)) {
+				return nil, ErrInvalidData
+			}
+			key.row = int(row)
+			if ds.comments == nil {
+				ds.comments = make(map[cellKey]string)
+			}
+			ds.comments[key] = text
+		}
+		for range nr.count() {
+			h := nr.string()
+			tags := make([]string, nr.count())
+			for i := range tags {
+				tags[i] = nr.string()
+			}
+			if nr.err != nil {
+				break
+			}
+			ds.columnTags[h] = tags
+		}
+	}
+
+	if nr.err != nil {
+		return nil, nr.err
+	}
+	return ds, nil
+}
+
+// nativeWriter writes native format fields, keeping the first error.
+type nativeWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (nw *nativeWriter) raw(b []byte) {
+	if nw.err == nil {
+		_, nw.err = nw.w.Write(b)
+	}
+}
+
+func (nw *nativeWriter) uvarint(n uint64) {
+	nw.raw(binary.AppendUvarint(nil, n))
+}
+
+func (nw *nativeWriter) varint(n int64) {
+	nw.raw(binary.AppendVarint(nil, n))
+}
+
+func (nw *nativeWriter) string(s string) {
+	nw.uvarint(uint64(len(s)))
+	nw.raw([]byte(s))
+}
+
+func (nw *nativeWriter) fixed(v any) {
+	if nw.err == nil {
+		nw.err = binary.Write(nw.w, binary.BigEndian, v)
+	}
+}
+
+// value writes a type tag followed by the cell value. Types without a tag
+// fail with ErrInvalidData rather than losing information.
+func (nw *nativeWriter) value(v any) {
+	switch val := v.(type) {
+	case nil:
+		nw.raw([]byte{nativeNil})
+	case bool:
+		b := byte(0)
+		if val {
+			b = 1
+		}
+		nw.raw([]byte{nativeBool, b})
+	case int:
+		nw.raw([]byte{nativeInt})
+		nw.varint(int64(val))
+	case int8:
+		nw.raw([]byte{nativeInt8})
+		nw.varint(int64(val))
+	case int16:
+		nw.raw([]byte{nativeInt16})
+		nw.varint(int64(val))
+	case int32:
+		nw.raw([]byte{nativeInt32})
+		nw.varint(int64(val))
+	case int64:
+		nw.raw([]byte{nativeInt64})
+		nw.varint(val)
+	case uint:
+		nw.raw([]byte{nativeUint})
+		nw.uvarint(uint64(val))
+	case uint8:
+		nw.raw([]byte{nativeUint8})
+		nw.uvarint(uint64(val))
+	case uint16:
+		nw.raw([]byte{nativeUint16})
+		nw.uvarint(uint64(val))
+	case uint32:
+		nw.raw([]byte{nativeUint32})
+		nw.uvarint(uint64(val))
+	case uint64:
+		nw.raw([]byte{nativeUint64})
+		nw.uvarint(val)
+	case float32:
+		nw.raw([]byte{nativeFloat32})
+		nw.fixed(val)
+	case float64:
+		nw.raw([]byte{nativeFloat64})
+		nw.fixed(val)
+	case string:
+		nw.raw([]byte{nativeString})
+		nw.string(val)
+	case []byte:
+		nw.raw([]byte{nativeBytes})
+		nw.string(string(val))
+	case time.Time:
+		b, err := val.MarshalBinary()
+		if err != nil && nw.err == nil {
+			nw.err = err
+		}
+		nw.raw([]byte{nativeTime})
+		nw.string(string(b))
+	case Formula:
+		// A formula's value is its cached result, never another formula
+		if _, ok := val.Value.(Formula); ok {
+			if nw.err == nil {
+				nw.err = ErrInvalidData
+			}
+			return
+		}
+		nw.raw([]byte{nativeFormula})
+		nw.string(val.Expression)
+		nw.value(val.Value)
+	case HTML:
+		nw.raw([]byte{nativeHTML})
+		nw.string(string(val))
+	default:
+		if nw.err == nil {
+			nw.err = ErrInvalidData
+		}
+	}
+}
+
+// nativeReader reads native format fields, keeping the first error and
+// returning zero values after it.
+type nativeReader struct {
+	r    *bufio.Reader
+	left int64 // bytes left in the input
+	err  error
+}
+
+// newNativeReader returns a reader for size bytes of native fields from r.
+func newNativeReader(r io.Reader, size int64) *nativeReader {
+	return &nativeReader{r: bufio.NewReader(r), left: size}
+}
+
+// Read and ReadByte read from the input, counting the bytes left.
+func (nr *nativeReader) Read(p []byte) (int, error) {
+	n, err := nr.r.Read(p)
+	nr.left -= int64(n)
+	return n, err
+}
+
+func (nr *nativeReader) ReadByte() (byte, error) {
+	b, err := nr.r.ReadByte()
+	if err == nil {
+		nr.left--
+	}
+	return b, err
+}
+
+// raw reads n bytes. Lengths beyond the remaining input fail before
+// allocating, so a corrupt length cannot force a huge allocation.
+func (nr *nativeReader) raw(n int) []byte {
+	if nr.err != nil {
+		return nil
+	}
+	if int64(n) > nr.left {
+		nr.fail(ErrInvalidData)
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(nr, b); err != nil {
+		nr.fail(err)
+		return nil
+	}
+	return b
+}
+
+func (nr *nativeReader) fail(err error) {
+	if nr.err != nil {
+		return
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = ErrInvalidData
+	}
+	nr.err = err
+}
+
+func (nr *nativeReader) byte() byte {
+	if nr.err != nil {
+		return 0
+	}
+	b, err := nr.ReadByte()
+	if err != nil {
+		nr.fail(err)
+	}
+	return b
+}
+
+func (nr *nativeReader) uvarint() uint64 {
+	if nr.err != nil {
+		return 0
+	}
+	n, err := binary.ReadUvarint(nr)
+	if err != nil {
+		nr.fail(err)
+	}
+	return n
+}
+
+func (nr *nativeReader) varint() int64 {
+	if nr.err != nil {
+		return 0
+	}
+	n, err := binary.ReadVarint(nr)
+	if err != nil {
+		nr.fail(err)
+	}
+	return n
+}
+
+// count reads a length or index, failing on values larger than the
+// remaining input: every counted item takes at least one byte, so a
+// corrupt count cannot force a huge allocation or loop.
+func (nr *nativeReader) count() int {
+	n := nr.uvarint()
+	if n > uint64(max(nr.left, 0)) {
+		nr.fail(ErrInvalidData)
+		return 0
+	}
+	return int(n)
+}
+
+func (nr *nativeReader) string() string {
+	return string(nr.raw(nr.count()))
+}
+
+func (nr *nativeReader) fixed(v any) {
+	if nr.err == nil {
+		if err := binary.Read(nr, binary.BigEndian, v); err != nil {
+			nr.fail(err)
+		}
+	}
+}
+
+// value reads a cell. The value of a formula is read without recursing,
+// so nested formulas fail with ErrInvalidData instead of exhausting the
+// stack.
+func (nr *nativeReader) value() any {
+	tag := nr.byte()
+	if tag == nativeFormula {
+		expr := nr.string()
+		return Formula{Expression: expr, Value: nr.plain(nr.byte())}
+	}
+	return nr.plain(tag)
+}
+
+// plain reads a cell of any type but a formula, after its type tag.
+func (nr *nativeReader) plain(tag byte) any {
+	switch tag {
+	case nativeNil:
+		return nil
+	case nativeBool:
+		return nr.byte() == 1
+	case nativeInt:
+		return int(nr.varint())
+	case nativeInt8:
+		return int8(nr.varint())
+	case nativeInt16:
+		return int16(nr.varint())
+	case nativeInt32:
+		return int32(nr.varint())
+	case nativeInt64:
+		return nr.varint()
+	case nativeUint:
+		return uint(nr.uvarint())
+	case nativeUint8:
+		return uint8(nr.uvarint())
+	case nativeUint16:
+		return uint16(nr.uvarint())
+	case nativeUint32:
+		return uint32(nr.uvarint())
+	case nativeUint64:
+		return nr.uvarint()
+	case nativeFloat32:
+		var f float32
+		nr.fixed(&f)
+		return f
+	case nativeFloat64:
+		var f float64
+		nr.fixed(&f)
+		return f
+	case nativeString:
+		return nr.string()
+	case nativeBytes:
+		return nr.raw(nr.count())
+	case nativeTime:
+		var t time.Time
+		if err := t.UnmarshalBinary(nr.raw(nr.count())); err != nil {
+			nr.fail(ErrInvalidData)
+		}
+		return t
+	case nativeHTML:
+		return HTML(nr.string())
+	default:
+		nr.fail(ErrInvalidData)
+		return nil
+	}
+}
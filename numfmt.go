@@ -0,0 +1,351 @@
+package tablib
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column display formats are set per column with SetColumnFormat. A format
+// is either a printf layout with a single verb, such as "%.2f%%" or
+// "$%d", or a named format:
+//
+//	percent       the value times 100 with a percent sign, e.g. "12%"
+//	percent:N     the same with N decimal places, e.g. "percent:1"
+//	currency:USD  the value with a currency symbol, thousands separators
+//	              and two decimal places, e.g. "$1,234.50"
+//	date          a time.Time as an ISO date, e.g. "2024-03-01"
+//	date:LAYOUT   a time.Time in a Go time layout, e.g. "date:02 Jan 2006"
+//	datetime      a time.Time as an RFC 3339 timestamp
+//
+// Text and HTML exports render formatted values; XLSX and ODS exports keep
+// numbers and times numeric and attach an equivalent number format
+// instead.
+
+type numberFormatKind int
+
+const (
+	numberFormatPrintf numberFormatKind = iota
+	numberFormatPercent
+	numberFormatCurrency
+	numberFormatTime
+)
+
+// numberFormat is a parsed column display format.
+type numberFormat struct {
+	kind     numberFormatKind
+	layout   string // printf layout
+	verb     rune   // printf verb
+	prefix   string // literal text before the printf verb
+	suffix   string // literal text after the printf verb
+	decimals int    // decimal places, or -1 for the verb's default
+	symbol   string // currency symbol
+	code     string // ISO 4217 currency code
+	dateOnly bool   // time layout without a clock
+}
+
+// currencySymbols maps currency codes to symbols. Other codes are shown
+// as the code followed by a space.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"INR": "₹",
+	"KRW": "₩",
+}
+
+// currencyDecimals lists currencies without two minor-unit digits.
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// parseNumberFormat parses a column display format.
+func parseNumberFormat(spec string) (numberFormat, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch name {
+	case "percent":
+		nf := numberFormat{kind: numberFormatPercent}
+		if hasArg {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return numberFormat{}, ErrInvalidData
+			}
+			nf.decimals = n
+		}
+		return nf, nil
+	case "currency":
+		code := strings.ToUpper(arg)
+		if !hasArg {
+			code = "USD"
+		}
+		if len(code) != 3 {
+			return numberFormat{}, ErrInvalidData
+		}
+		nf := numberFormat{kind: numberFormatCurrency, code: code, decimals: 2, symbol: code + " "}
+		if s, ok := currencySymbols[code]; ok {
+			nf.symbol = s
+		}
+		if d, ok := currencyDecimals[code]; ok {
+			nf.decimals = d
+		}
+		return nf, nil
+	case "date", "datetime":
+		layout := "2006-01-02"
+		switch {
+		case name == "datetime" && hasArg:
+			return numberFormat{}, ErrInvalidData
+		case name == "datetime":
+			layout = time.RFC3339
+		case hasArg && arg == "":
+			return numberFormat{}, ErrInvalidData
+		case hasArg:
+			layout = arg
+		}
+		// A layout without a clock renders a time and its midnight alike
+		probe := time.Date(2001, 2, 3, 4, 5, 6, 7, time.UTC)
+		midnight := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+		return numberFormat{
+			kind:     numberFormatTime,
+			layout:   layout,
+			dateOnly: probe.Format(layout) == midnight.Format(layout),
+		}, nil
+	}
+	return parsePrintfFormat(spec)
+}
+
+// parsePrintfFormat parses a printf layout holding exactly one verb.
+func parsePrintfFormat(layout string) (numberFormat, error) {
+	nf := numberFormat{kind: numberFormatPrintf, layout: layout, decimals: -1}
+	var literal strings.Builder
+	verbs := 0
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			literal.WriteByte(layout[i])
+			continue
+		}
+		i++
+		if i < len(layout) && layout[i] == '%' {
+			literal.WriteByte('%')
+			continue
+		}
+		for i < len(layout) && strings.IndexByte("+-# 0", layout[i]) >= 0 {
+			i++
+		}
+		for i < len(layout) && layout[i] >= '0' && layout[i] <= '9' {
+			i++
+		}
+		if i < len(layout) && layout[i] == '.' {
+			i++
+			start := i
+			for i < len(layout) && layout[i] >= '0' && layout[i] <= '9' {
+				i++
+			}
+			nf.decimals, _ = strconv.Atoi(layout[start:i])
+		}
+		if i >= len(layout) {
+			return numberFormat{}, ErrInvalidData
+		}
+		verbs++
+		nf.verb = rune(layout[i])
+		nf.prefix = literal.String()
+		literal.Reset()
+	}
+	if verbs != 1 {
+		return numberFormat{}, ErrInvalidData
+	}
+	nf.suffix = literal.String()
+	return nf, nil
+}
+
+// format renders v, reporting false for values the format does not apply
+// to, such as text in a numeric column.
+func (nf numberFormat) format(v any) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	if nf.kind == numberFormatTime {
+		t, ok := v.(time.Time)
+		if !ok {
+			return "", false
+		}
+		return t.Format(nf.layout), true
+	}
+	if nf.kind == numberFormatPrintf {
+		switch nf.verb {
+		case 'f', 'F', 'e', 'E', 'g', 'G':
+			x, ok := numericValue(v)
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprintf(nf.layout, x), true
+		case 'd':
+			x, ok := numericValue(v)
+			if !ok || x != math.Trunc(x) {
+				return "", false
+			}
+			return fmt.Sprintf(nf.layout, int64(x)), true
+		}
+		return fmt.Sprintf(nf.layout, v), true
+	}
+
+	x, ok := numericValue(v)
+	if !ok {
+		return "", false
+	}
+	if nf.kind == numberFormatPercent {
+		return strconv.FormatFloat(x*100, 'f', nf.decimals, 64) + "%", true
+	}
+	sign := ""
+	if x < 0 {
+		sign, x = "-", -x
+	}
+	return sign + nf.symbol + groupThousands(strconv.FormatFloat(x, 'f', nf.decimals, 64)), true
+}
+
+// groupThousands inserts commas between groups of three integer digits.
+func groupThousands(s string) string {
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	var sb strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(r)
+	}
+	if hasFrac {
+		sb.WriteString("." + frac)
+	}
+	return sb.String()
+}
+
+// spreadsheetFormat returns the equivalent spreadsheet number format code,
+// as used by XLSX, or "" when there is none.
+func (nf numberFormat) spreadsheetFormat() string {
+	switch nf.kind {
+	case numberFormatPercent:
+		return "0" + decimalPlaces(nf.decimals) + "%"
+	case numberFormatCurrency:
+		return spreadsheetLiteral(nf.symbol) + "#,##0" + decimalPlaces(nf.decimals)
+	case numberFormatTime:
+		return spreadsheetDateFormat(nf.layout)
+	}
+
+	var code string
+	switch nf.verb {
+	case 'd':
+		code = "0"
+	case 'f', 'F':
+		code = "0" + decimalPlaces(nf.precision(6))
+	case 'e', 'E':
+		code = "0" + decimalPlaces(nf.precision(6)) + "E+00"
+	default:
+		return ""
+	}
+	return spreadsheetLiteral(nf.prefix) + code + spreadsheetLiteral(nf.suffix)
+}
+
+// precision returns the decimal places of a printf format, or def when
+// the layout does not set them.
+func (nf numberFormat) precision(def int) int {
+	if nf.decimals < 0 {
+		return def
+	}
+	return nf.decimals
+}
+
+// decimalPlaces returns the fractional part of a number format code with n
+// decimal places.
+func decimalPlaces(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return "." + strings.Repeat("0", n)
+}
+
+// spreadsheetLiteral escapes text for a number format code, so that
+// characters such as "%" are shown rather than interpreted.
+func spreadsheetLiteral(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		sb.WriteByte('\\')
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// SetColumnFormat sets the display format for the column with the
+// specified header, replacing any previous one. An empty format removes
+// it. Invalid formats return ErrInvalidData.
+func (ds *Dataset) SetColumnFormat(header, format string) error {
+	if ds.frozen {
+		return ErrFrozen
+	}
+	if ds.headerIndex(header) == -1 {
+		return ErrColumnNotFound
+	}
+	if format == "" {
+		delete(ds.formats, header)
+		ds.dropWidths()
+		return nil
+	}
+	nf, err := parseNumberFormat(format)
+	if err != nil {
+		return err
+	}
+	ds.formats[header] = columnFormat{spec: format, nf: nf}
+	ds.dropWidths()
+	return nil
+}
+
+// ColumnFormat returns the display format for the column with the
+// specified header, or "" if it has none.
+func (ds *Dataset) ColumnFormat(header string) string {
+	return ds.formats[header].spec
+}
+
+// columnFormat is a display format set with SetColumnFormat.
+type columnFormat struct {
+	spec string
+	nf   numberFormat
+}
+
+// columnNumberFormat returns the parsed display format of column col.
+func (ds *Dataset) columnNumberFormat(col int) (numberFormat, bool) {
+	if col >= len(ds.headers) {
+		return numberFormat{}, false
+	}
+	cf, ok := ds.formats[ds.headers[col]]
+	return cf.nf, ok
+}
+
+// formattedCell renders v with the display format of column col,
+// reporting false when the column has none or it does not apply to v.
+func (ds *Dataset) formattedCell(col int, v any) (string, bool) {
+	nf, ok := ds.columnNumberFormat(col)
+	if !ok {
+		return "", false
+	}
+	return nf.format(v)
+}
+
+// cellText returns the display text of v in column col.
+func (ds *Dataset) cellText(col int, v any) string {
+	if s, ok := ds.formattedCell(col, v); ok {
+		return s
+	}
+	return valueString(v)
+}
+
+// cellTexts returns the display text of each cell of row.
+func (ds *Dataset) cellTexts(row []any) []string {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		cells[i] = ds.cellText(i, v)
+	}
+	return cells
+}
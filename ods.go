@@ -6,35 +6,64 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func init() {
 	RegisterExporter(FormatODS, ExporterFunc(exportODS))
 	RegisterDatabookExporter(FormatODS, DatabookExporterFunc(exportODSDatabook))
+	RegisterDatabookImporter(FormatODS, DatabookImporterFunc(importODSDatabook))
 }
 
 // ODS XML structures
 type odsDocument struct {
-	XMLName       xml.Name      `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 document-content"`
-	Version       string        `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 version,attr"`
-	OfficeNS      string        `xml:"xmlns:office,attr"`
-	TextNS        string        `xml:"xmlns:text,attr"`
-	TableNS       string        `xml:"xmlns:table,attr"`
-	StyleNS       string        `xml:"xmlns:style,attr"`
-	FoNS          string        `xml:"xmlns:fo,attr"`
-	AutoStyles    odsAutoStyles `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 automatic-styles"`
-	Body          odsBody       `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 body"`
+	XMLName    xml.Name      `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 document-content"`
+	Version    string        `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 version,attr"`
+	OfficeNS   string        `xml:"xmlns:office,attr"`
+	TextNS     string        `xml:"xmlns:text,attr"`
+	TableNS    string        `xml:"xmlns:table,attr"`
+	StyleNS    string        `xml:"xmlns:style,attr"`
+	FoNS       string        `xml:"xmlns:fo,attr"`
+	NumberNS   string        `xml:"xmlns:number,attr"`
+	AutoStyles odsAutoStyles `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 automatic-styles"`
+	Body       odsBody       `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 body"`
 }
 
 type odsAutoStyles struct {
-	Styles []odsStyle `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 style"`
+	DataStyles []odsDataStyle
+	Styles     []odsStyle `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 style"`
 }
 
 type odsStyle struct {
-	Name       string              `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 name,attr"`
-	Family     string              `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 family,attr"`
-	Properties *odsTextProperties  `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 text-properties,omitempty"`
+	Name          string             `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 name,attr"`
+	Family        string             `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 family,attr"`
+	DataStyleName string             `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 data-style-name,attr,omitempty"`
+	Properties    *odsTextProperties `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 text-properties,omitempty"`
+}
+
+// odsDataStyle is a number, percentage, currency or date style, named by
+// its XMLName.
+type odsDataStyle struct {
+	XMLName xml.Name
+	Name    string `xml:"urn:oasis:names:tc:opendocument:xmlns:style:1.0 name,attr"`
+	Parts   []odsDataPart
+}
+
+// odsDataPart is an element of a data style, such as number:number or
+// number:text.
+type odsDataPart struct {
+	XMLName           xml.Name
+	DecimalPlaces     string `xml:"urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0 decimal-places,attr,omitempty"`
+	MinIntegerDigits  string `xml:"urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0 min-integer-digits,attr,omitempty"`
+	MinExponentDigits string `xml:"urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0 min-exponent-digits,attr,omitempty"`
+	Grouping          string `xml:"urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0 grouping,attr,omitempty"`
+	Style             string `xml:"urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0 style,attr,omitempty"`
+	Textual           string `xml:"urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0 textual,attr,omitempty"`
+	Text              string `xml:",chardata"`
 }
 
 type odsTextProperties struct {
@@ -50,8 +79,8 @@ type odsSpreadsheet struct {
 }
 
 type odsTable struct {
-	Name string    `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 name,attr"`
-	Rows []odsRow  `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 table-row"`
+	Name string   `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 name,attr"`
+	Rows []odsRow `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 table-row"`
 }
 
 type odsRow struct {
@@ -59,25 +88,114 @@ type odsRow struct {
 }
 
 type odsCell struct {
-	ValueType string  `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 value-type,attr,omitempty"`
-	Value     string  `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 value,attr,omitempty"`
-	StyleName string  `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 style-name,attr,omitempty"`
-	Text      *odsText `xml:"urn:oasis:names:tc:opendocument:xmlns:text:1.0 p,omitempty"`
+	ValueType string      `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 value-type,attr,omitempty"`
+	Value     string      `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 value,attr,omitempty"`
+	Currency  string      `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 currency,attr,omitempty"`
+	DateValue string      `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 date-value,attr,omitempty"`
+	StyleName string      `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 style-name,attr,omitempty"`
+	Formula   string      `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 formula,attr,omitempty"`
+	Comment   *odsComment `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 annotation,omitempty"`
+	Text      *odsText    `xml:"urn:oasis:names:tc:opendocument:xmlns:text:1.0 p,omitempty"`
 }
 
 type odsText struct {
 	Content string `xml:",chardata"`
 }
 
+// odsComment is a cell comment, one paragraph per line.
+type odsComment struct {
+	Paragraphs []odsText `xml:"urn:oasis:names:tc:opendocument:xmlns:text:1.0 p"`
+}
+
+// ODSOptions configures ODS export behavior. The fields populate the
+// document metadata (meta.xml).
+type ODSOptions struct {
+	// Title defaults to the Dataset or Databook title.
+	Title  string
+	Author string
+	// Created defaults to the time of export.
+	Created time.Time
+	// Generator defaults to "tablib-go".
+	Generator string
+	// Properties are written as user-defined metadata fields.
+	Properties map[string]string
+}
+
+// odsMeta is the meta.xml document.
+type odsMeta struct {
+	XMLName  xml.Name    `xml:"office:document-meta"`
+	OfficeNS string      `xml:"xmlns:office,attr"`
+	MetaNS   string      `xml:"xmlns:meta,attr"`
+	DcNS     string      `xml:"xmlns:dc,attr"`
+	Version  string      `xml:"office:version,attr"`
+	Meta     odsMetaBody `xml:"office:meta"`
+}
+
+type odsMetaBody struct {
+	Generator      string `xml:"meta:generator"`
+	Title          string `xml:"dc:title,omitempty"`
+	InitialCreator string `xml:"meta:initial-creator,omitempty"`
+	Creator        string `xml:"dc:creator,omitempty"`
+	CreationDate   string `xml:"meta:creation-date"`
+	Date           string `xml:"dc:date"`
+	UserDefined    []odsUserDefined
+}
+
+type odsUserDefined struct {
+	XMLName xml.Name `xml:"meta:user-defined"`
+	Name    string   `xml:"meta:name,attr"`
+	Value   string   `xml:",chardata"`
+}
+
+// odsImportMeta mirrors the parts of meta.xml read on import.
+type odsImportMeta struct {
+	Meta struct {
+		Title          string `xml:"title"`
+		InitialCreator string `xml:"initial-creator"`
+		Creator        string `xml:"creator"`
+		CreationDate   string `xml:"creation-date"`
+		UserDefined    []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"user-defined"`
+	} `xml:"meta"`
+}
+
 func exportODS(ds *Dataset, w io.Writer) error {
-	return exportODSSheets(w, []*Dataset{ds})
+	return exportODSSheets(w, []*Dataset{ds}, ODSOptions{Title: ds.title})
 }
 
 func exportODSDatabook(db *Databook, w io.Writer) error {
-	return exportODSSheets(w, db.sheets)
+	return db.ExportODS(w, ODSOptions{})
+}
+
+// ExportODS exports the Dataset to ODS format with custom options.
+func (ds *Dataset) ExportODS(w io.Writer, opts ODSOptions) error {
+	if opts.Title == "" {
+		opts.Title = ds.title
+	}
+	return exportODSSheets(w, []*Dataset{ds.withDynamicColumns()}, opts)
 }
 
-func exportODSSheets(w io.Writer, sheets []*Dataset) error {
+// ExportODS exports the Databook to ODS format with custom options. Unset
+// metadata fields default to the Databook's metadata.
+func (db *Databook) ExportODS(w io.Writer, opts ODSOptions) error {
+	if opts.Title == "" {
+		opts.Title = db.title
+	}
+	if opts.Author == "" {
+		opts.Author = db.author
+	}
+	if opts.Created.IsZero() {
+		opts.Created = db.created
+	}
+	if opts.Properties == nil {
+		opts.Properties = db.properties
+	}
+	return exportODSSheets(w, db.withDynamicColumns().sheets, opts)
+}
+
+func exportODSSheets(w io.Writer, sheets []*Dataset, opts ODSOptions) error {
 	var buf bytes.Buffer
 	zipWriter := zip.NewWriter(&buf)
 
@@ -101,6 +219,7 @@ func exportODSSheets(w io.Writer, sheets []*Dataset) error {
   <manifest:file-entry manifest:full-path="/" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/>
   <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
   <manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>
+  <manifest:file-entry manifest:full-path="meta.xml" manifest:media-type="text/xml"/>
 </manifest:manifest>`
 
 	manifestWriter, err := zipWriter.Create("META-INF/manifest.xml")
@@ -126,6 +245,43 @@ func exportODSSheets(w io.Writer, sheets []*Dataset) error {
 		return err
 	}
 
+	// Create meta.xml
+	if opts.Generator == "" {
+		opts.Generator = "tablib-go"
+	}
+	if opts.Created.IsZero() {
+		opts.Created = time.Now()
+	}
+	created := opts.Created.Format("2006-01-02T15:04:05")
+	meta := odsMeta{
+		OfficeNS: "urn:oasis:names:tc:opendocument:xmlns:office:1.0",
+		MetaNS:   "urn:oasis:names:tc:opendocument:xmlns:meta:1.0",
+		DcNS:     "http://purl.org/dc/elements/1.1/",
+		Version:  "1.2",
+		Meta: odsMetaBody{
+			Generator:      opts.Generator,
+			Title:          opts.Title,
+			InitialCreator: opts.Author,
+			Creator:        opts.Author,
+			CreationDate:   created,
+			Date:           created,
+		},
+	}
+	for _, name := range slices.Sorted(maps.Keys(opts.Properties)) {
+		meta.Meta.UserDefined = append(meta.Meta.UserDefined, odsUserDefined{Name: name, Value: opts.Properties[name]})
+	}
+
+	metaWriter, err := zipWriter.Create("meta.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := metaWriter.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	if err := xml.NewEncoder(metaWriter).Encode(meta); err != nil {
+		return err
+	}
+
 	// Create content.xml
 	doc := odsDocument{
 		Version:  "1.2",
@@ -134,6 +290,7 @@ func exportODSSheets(w io.Writer, sheets []*Dataset) error {
 		TableNS:  "urn:oasis:names:tc:opendocument:xmlns:table:1.0",
 		StyleNS:  "urn:oasis:names:tc:opendocument:xmlns:style:1.0",
 		FoNS:     "urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0",
+		NumberNS: odsDataStyleNS,
 		AutoStyles: odsAutoStyles{
 			Styles: []odsStyle{
 				{
@@ -147,6 +304,9 @@ func exportODSSheets(w io.Writer, sheets []*Dataset) error {
 		},
 	}
 
+	// Column display formats become data styles, shared by format
+	cellStyles := make(map[string]string) // format -> cell style name
+
 	tables := make([]odsTable, 0, len(sheets))
 	for _, ds := range sheets {
 		table := odsTable{
@@ -171,35 +331,38 @@ func exportODSSheets(w io.Writer, sheets []*Dataset) error {
 			table.Rows = append(table.Rows, headerRow)
 		}
 
+		styleNames := make([]string, ds.Width())
+		for col := range styleNames {
+			if nf, ok := ds.columnNumberFormat(col); ok {
+				styleNames[col] = odsCellStyle(&doc.AutoStyles, cellStyles, ds.formats[ds.headers[col]].spec, nf)
+			}
+		}
+
 		// Add data rows
 		for _, row := range ds.data {
 			dataRow := odsRow{
 				Cells: make([]odsCell, len(row)),
 			}
 			for i, v := range row {
-				cell := odsCell{}
-				switch val := v.(type) {
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-					cell.ValueType = "float"
-					cell.Value = fmt.Sprintf("%v", val)
-					cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
-				case float32, float64:
-					cell.ValueType = "float"
-					cell.Value = fmt.Sprintf("%v", val)
-					cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
-				case bool:
-					cell.ValueType = "boolean"
-					cell.Value = fmt.Sprintf("%v", val)
-					cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
-				default:
-					cell.ValueType = "string"
-					cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
+				dataRow.Cells[i] = odsValueCell(v)
+				if i < len(styleNames) && styleNames[i] != "" {
+					nf, _ := ds.columnNumberFormat(i)
+					formatODSCell(&dataRow.Cells[i], v, nf, styleNames[i])
 				}
-				dataRow.Cells[i] = cell
 			}
 			table.Rows = append(table.Rows, dataRow)
 		}
 
+		// Attach cell comments as annotations
+		firstRow := len(table.Rows) - len(ds.data)
+		for _, c := range ds.cellComments() {
+			comment := &odsComment{}
+			for _, line := range strings.Split(c.text, "\n") {
+				comment.Paragraphs = append(comment.Paragraphs, odsText{Content: line})
+			}
+			table.Rows[firstRow+c.row].Cells[c.col].Comment = comment
+		}
+
 		tables = append(tables, table)
 	}
 
@@ -227,13 +390,215 @@ func exportODSSheets(w io.Writer, sheets []*Dataset) error {
 	return err
 }
 
-// ImportODS imports data from an ODS file.
-func ImportODS(r io.ReaderAt, size int64, sheetName string) (*Dataset, error) {
-	zipReader, err := zip.NewReader(r, size)
-	if err != nil {
-		return nil, err
+// odsValueCell builds a typed ODS cell for a value. Formula values are
+// written with a table:formula attribute and their cached result.
+func odsValueCell(v any) odsCell {
+	if f, ok := v.(Formula); ok {
+		cell := odsCell{}
+		if f.Value != nil {
+			cell = odsValueCell(f.Value)
+		}
+		cell.Formula = odsFormula(f.Expression)
+		return cell
+	}
+
+	cell := odsCell{}
+	switch val := v.(type) {
+	case nil:
+		// Null cells are left empty
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		cell.ValueType = "float"
+		cell.Value = fmt.Sprintf("%v", val)
+		cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
+	case float32, float64:
+		cell.ValueType = "float"
+		cell.Value = fmt.Sprintf("%v", val)
+		cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
+	case bool:
+		cell.ValueType = "boolean"
+		cell.Value = fmt.Sprintf("%v", val)
+		cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
+	case time.Time:
+		cell.ValueType = "date"
+		cell.DateValue = val.Format(odsDateLayout)
+		cell.Text = &odsText{Content: valueString(val)}
+	default:
+		cell.ValueType = "string"
+		cell.Text = &odsText{Content: fmt.Sprintf("%v", val)}
 	}
+	return cell
+}
 
+const odsDataStyleNS = "urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0"
+
+// odsDataName returns the name of an element in the data style namespace.
+func odsDataName(local string) xml.Name {
+	return xml.Name{Space: odsDataStyleNS, Local: local}
+}
+
+// odsCellStyle returns the name of a cell style showing numbers with nf,
+// adding it and its data style to styles the first time format is seen.
+// It returns "" for formats without a spreadsheet equivalent.
+func odsCellStyle(styles *odsAutoStyles, names map[string]string, format string, nf numberFormat) string {
+	if name, ok := names[format]; ok {
+		return name
+	}
+	n := len(names) + 1
+	dataStyle, ok := odsNumberStyle(fmt.Sprintf("N%d", n), nf)
+	if !ok {
+		names[format] = ""
+		return ""
+	}
+	name := fmt.Sprintf("ce%d", n)
+	styles.DataStyles = append(styles.DataStyles, dataStyle)
+	styles.Styles = append(styles.Styles, odsStyle{Name: name, Family: "table-cell", DataStyleName: dataStyle.Name})
+	names[format] = name
+	return name
+}
+
+// odsNumberStyle builds the data style equivalent to nf.
+func odsNumberStyle(name string, nf numberFormat) (odsDataStyle, bool) {
+	number := func(decimals int) odsDataPart {
+		return odsDataPart{XMLName: odsDataName("number"), DecimalPlaces: strconv.Itoa(decimals), MinIntegerDigits: "1"}
+	}
+	text := func(s string) odsDataPart {
+		return odsDataPart{XMLName: odsDataName("text"), Text: s}
+	}
+
+	style := odsDataStyle{Name: name}
+	switch nf.kind {
+	case numberFormatPercent:
+		style.XMLName = odsDataName("percentage-style")
+		style.Parts = []odsDataPart{number(nf.decimals), text("%")}
+	case numberFormatCurrency:
+		style.XMLName = odsDataName("currency-style")
+		style.Parts = []odsDataPart{{XMLName: odsDataName("currency-symbol"), Text: strings.TrimSpace(nf.symbol)}}
+		if strings.HasSuffix(nf.symbol, " ") {
+			style.Parts = append(style.Parts, text(" "))
+		}
+		amount := number(nf.decimals)
+		amount.Grouping = "true"
+		style.Parts = append(style.Parts, amount)
+	case numberFormatTime:
+		style.XMLName = odsDataName("date-style")
+		for _, e := range splitLayout(nf.layout) {
+			switch {
+			case e.literal:
+				style.Parts = append(style.Parts, text(e.layout))
+			case e.part != "":
+				part := odsDataPart{XMLName: odsDataName(e.part)}
+				if e.long {
+					part.Style = "long"
+				}
+				if e.textual {
+					part.Textual = "true"
+				}
+				style.Parts = append(style.Parts, part)
+			}
+		}
+	default:
+		var amount odsDataPart
+		switch nf.verb {
+		case 'd':
+			amount = number(0)
+		case 'f', 'F':
+			amount = number(nf.precision(6))
+		case 'e', 'E':
+			amount = number(nf.precision(6))
+			amount.XMLName = odsDataName("scientific-number")
+			amount.MinExponentDigits = "2"
+		default:
+			return odsDataStyle{}, false
+		}
+		style.XMLName = odsDataName("number-style")
+		if nf.prefix != "" {
+			style.Parts = append(style.Parts, text(nf.prefix))
+		}
+		style.Parts = append(style.Parts, amount)
+		if nf.suffix != "" {
+			style.Parts = append(style.Parts, text(nf.suffix))
+		}
+	}
+	return style, true
+}
+
+// formatODSCell applies a column display format to a numeric or date
+// cell: the cell keeps its value, shows the formatted text and uses the
+// cell style of the format.
+func formatODSCell(cell *odsCell, v any, nf numberFormat, style string) {
+	want := "float"
+	if nf.kind == numberFormatTime {
+		want = "date"
+	}
+	if cell.ValueType != want {
+		return
+	}
+	if f, ok := v.(Formula); ok {
+		v = f.Value
+	}
+	if text, ok := nf.format(v); ok {
+		cell.Text = &odsText{Content: text}
+	}
+	cell.StyleName = style
+	switch nf.kind {
+	case numberFormatPercent:
+		cell.ValueType = "percentage"
+	case numberFormatCurrency:
+		cell.ValueType = "currency"
+		cell.Currency = nf.code
+	}
+}
+
+// odsFormula converts a spreadsheet expression such as "=SUM(A1:A2)" into
+// the OpenFormula attribute form "of:=SUM(A1:A2)". Expressions that already
+// carry a namespace prefix are returned unchanged.
+func odsFormula(expr string) string {
+	if strings.HasPrefix(expr, "of:") || strings.HasPrefix(expr, "oooc:") {
+		return expr
+	}
+	return "of:=" + strings.TrimPrefix(expr, "=")
+}
+
+// odsFormulaExpression strips the namespace prefix from a table:formula
+// attribute, returning the expression in "=..." form.
+func odsFormulaExpression(attr string) string {
+	for _, prefix := range []string{"of:", "oooc:", "msoxl:"} {
+		attr = strings.TrimPrefix(attr, prefix)
+	}
+	if !strings.HasPrefix(attr, "=") {
+		attr = "=" + attr
+	}
+	return attr
+}
+
+// odsDateLayout is the layout of office:date-value attributes, which hold
+// a date and time without a zone.
+const odsDateLayout = "2006-01-02T15:04:05.999999999"
+
+// odsNumericTypes are the value types whose office:value holds a number.
+var odsNumericTypes = map[string]bool{"float": true, "percentage": true, "currency": true}
+
+// odsImportCell, odsImportRow and odsImportTable mirror the parts of
+// content.xml read on import.
+type odsImportCell struct {
+	ValueType string `xml:"value-type,attr"`
+	Value     string `xml:"value,attr"`
+	DateValue string `xml:"date-value,attr"`
+	Formula   string `xml:"formula,attr"`
+	Text      string `xml:"p"`
+}
+
+type odsImportRow struct {
+	Cells []odsImportCell `xml:"table-cell"`
+}
+
+type odsImportTable struct {
+	Name string         `xml:"name,attr"`
+	Rows []odsImportRow `xml:"table-row"`
+}
+
+// readODSTables parses the tables from the content.xml of an ODS file.
+func readODSTables(zipReader *zip.Reader) ([]odsImportTable, error) {
 	// Find and parse content.xml
 	var contentFile *zip.File
 	for _, f := range zipReader.File {
@@ -253,78 +618,158 @@ func ImportODS(r io.ReaderAt, size int64, sheetName string) (*Dataset, error) {
 	defer rc.Close()
 
 	// Parse the XML
-	type simpleCell struct {
-		ValueType string `xml:"value-type,attr"`
-		Value     string `xml:"value,attr"`
-		Text      string `xml:"p"`
+	var doc struct {
+		Body struct {
+			Spreadsheet struct {
+				Tables []odsImportTable `xml:"table"`
+			} `xml:"spreadsheet"`
+		} `xml:"body"`
 	}
-	type simpleRow struct {
-		Cells []simpleCell `xml:"table-cell"`
+	decoder := xml.NewDecoder(rc)
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.Body.Spreadsheet.Tables, nil
+}
+
+// ImportODS imports data from an ODS file.
+func ImportODS(r io.ReaderAt, size int64, sheetName string) (*Dataset, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
 	}
-	type simpleTable struct {
-		Name string      `xml:"name,attr"`
-		Rows []simpleRow `xml:"table-row"`
+	tables, err := readODSTables(zipReader)
+	if err != nil {
+		return nil, err
 	}
-	type simpleSpreadsheet struct {
-		Tables []simpleTable `xml:"table"`
+
+	// Find the requested sheet
+	for i := range tables {
+		if sheetName == "" || tables[i].Name == sheetName {
+			return odsTableDataset(&tables[i])
+		}
 	}
-	type simpleBody struct {
-		Spreadsheet simpleSpreadsheet `xml:"spreadsheet"`
+	return nil, fmt.Errorf("sheet '%s' not found", sheetName)
+}
+
+// ImportODSDatabook imports all sheets from an ODS file into a Databook,
+// along with the document metadata.
+func ImportODSDatabook(r io.ReaderAt, size int64) (*Databook, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
 	}
-	type simpleDoc struct {
-		Body simpleBody `xml:"body"`
+	tables, err := readODSTables(zipReader)
+	if err != nil {
+		return nil, err
 	}
 
-	var doc simpleDoc
-	decoder := xml.NewDecoder(rc)
-	if err := decoder.Decode(&doc); err != nil {
+	db := NewDatabook()
+	if err := readODSMeta(zipReader, db); err != nil {
 		return nil, err
 	}
+	for i := range tables {
+		ds, err := odsTableDataset(&tables[i])
+		if err != nil {
+			return nil, err
+		}
+		db.AddSheet(ds)
+	}
+	return db, nil
+}
 
-	// Find the requested sheet
-	var targetTable *simpleTable
-	for i := range doc.Body.Spreadsheet.Tables {
-		t := &doc.Body.Spreadsheet.Tables[i]
-		if sheetName == "" || t.Name == sheetName {
-			targetTable = t
-			break
+// readODSMeta copies the title, author, creation date and user-defined
+// properties from meta.xml, if present, into db.
+func readODSMeta(zipReader *zip.Reader, db *Databook) error {
+	for _, f := range zipReader.File {
+		if f.Name != "meta.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		var meta odsImportMeta
+		if err := xml.NewDecoder(rc).Decode(&meta); err != nil {
+			return err
+		}
+		db.title = meta.Meta.Title
+		db.author = meta.Meta.InitialCreator
+		if db.author == "" {
+			db.author = meta.Meta.Creator
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+			if created, err := time.Parse(layout, meta.Meta.CreationDate); err == nil {
+				db.created = created
+				break
+			}
+		}
+		for _, p := range meta.Meta.UserDefined {
+			db.properties[p.Name] = p.Value
 		}
 	}
-	if targetTable == nil {
-		return nil, fmt.Errorf("sheet '%s' not found", sheetName)
+	return nil
+}
+
+// importODSDatabook reads a whole ODS file from r for the Databook
+// importer registry.
+func importODSDatabook(r io.Reader) (*Databook, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
 	}
+	return ImportODSDatabook(bytes.NewReader(data), int64(len(data)))
+}
 
+// odsTableDataset converts a parsed table to a Dataset, using the first
+// row as headers.
+func odsTableDataset(table *odsImportTable) (*Dataset, error) {
 	// Convert to Dataset
-	if len(targetTable.Rows) == 0 {
-		return NewDataset(nil), nil
+	if len(table.Rows) == 0 {
+		ds := NewDataset(nil)
+		ds.SetTitle(table.Name)
+		return ds, nil
 	}
 
 	// First row as headers
 	var headers []string
-	if len(targetTable.Rows) > 0 {
-		for _, cell := range targetTable.Rows[0].Cells {
-			text := strings.TrimSpace(cell.Text)
-			if text == "" {
-				text = cell.Value
-			}
-			headers = append(headers, text)
+	for _, cell := range table.Rows[0].Cells {
+		text := strings.TrimSpace(cell.Text)
+		if text == "" {
+			text = cell.Value
 		}
+		headers = append(headers, text)
 	}
 
 	ds := NewDataset(headers)
-	ds.SetTitle(targetTable.Name)
+	ds.SetTitle(table.Name)
 
 	// Remaining rows as data
-	for i := 1; i < len(targetTable.Rows); i++ {
+	for i := 1; i < len(table.Rows); i++ {
 		row := make([]any, len(headers))
-		for j, cell := range targetTable.Rows[i].Cells {
+		for j, cell := range table.Rows[i].Cells {
 			if j >= len(headers) {
 				break
 			}
+			// Numbers are read from their value, which is not
+			// affected by the cell's number format
 			text := strings.TrimSpace(cell.Text)
-			if text == "" {
+			if text == "" || (cell.Value != "" && odsNumericTypes[cell.ValueType]) {
 				text = cell.Value
 			}
+			if cell.Formula != "" {
+				row[j] = Formula{Expression: odsFormulaExpression(cell.Formula), Value: text}
+				continue
+			}
+			// Dates are read from their value as time.Time
+			if cell.ValueType == "date" {
+				if t, ok := parseTime(cell.DateValue, []string{odsDateLayout, time.RFC3339Nano}); ok && t != nil {
+					row[j] = t
+					continue
+				}
+			}
 			row[j] = text
 		}
 		if err := ds.Append(row); err != nil {
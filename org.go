@@ -0,0 +1,115 @@
+package tablib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	RegisterExporter(FormatOrg, ExporterFunc(exportOrg))
+}
+
+// exportOrg exports the Dataset to an Emacs org-mode table. Separators
+// become horizontal rules, followed by a row with the separator text in
+// bold when it has any.
+func exportOrg(ds *Dataset, w io.Writer) error {
+	if ds.Width() == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	cond := newWidthCondition(false)
+
+	headers := make([]string, len(ds.headers))
+	for i, h := range ds.headers {
+		headers[i] = escapeOrg(h)
+	}
+	rows := make([][]string, len(ds.data))
+	for i, row := range ds.data {
+		rows[i] = make([]string, len(row))
+		for j, v := range row {
+			rows[i][j] = escapeOrg(ds.cellText(j, v))
+		}
+	}
+	sections := make(map[int]string)
+	for idx, sep := range ds.separators {
+		if sep.Text != "" {
+			sections[idx] = fmt.Sprintf("*%s*", escapeOrg(sep.Text))
+		}
+	}
+
+	// Calculate column widths
+	widths := make([]int, ds.Width())
+	for i, h := range headers {
+		widths[i] = max(widths[i], cond.StringWidth(h))
+	}
+	for _, row := range rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], cond.StringWidth(c))
+		}
+	}
+	for _, text := range sections {
+		widths[0] = max(widths[0], cond.StringWidth(text))
+	}
+	for i := range widths {
+		if widths[i] < 1 {
+			widths[i] = 1
+		}
+	}
+
+	writeRule := func() {
+		sb.WriteString("|")
+		for i, w := range widths {
+			sb.WriteString(strings.Repeat("-", w+2))
+			if i < len(widths)-1 {
+				sb.WriteString("+")
+			}
+		}
+		sb.WriteString("|\n")
+	}
+	writeCells := func(cells []string) {
+		sb.WriteString("|")
+		for i := range widths {
+			c := ""
+			if i < len(cells) {
+				c = cells[i]
+			}
+			sb.WriteString(fmt.Sprintf(" %s |", alignText(c, widths[i], AlignLeft, cond)))
+		}
+		sb.WriteString("\n")
+	}
+	writeSeparator := func(idx int) {
+		if _, ok := ds.GetSeparator(idx); !ok {
+			return
+		}
+		writeRule()
+		if text, ok := sections[idx]; ok {
+			writeCells([]string{text})
+		}
+	}
+
+	// Write headers
+	if len(headers) > 0 {
+		writeCells(headers)
+		if _, ok := ds.GetSeparator(0); !ok {
+			writeRule()
+		}
+	}
+
+	// Write data rows
+	for rowIdx, row := range rows {
+		writeSeparator(rowIdx)
+		writeCells(row)
+	}
+	writeSeparator(len(rows))
+
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
+
+// escapeOrg replaces characters that would break an org-mode table cell.
+func escapeOrg(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\vert{}")
+	return strings.ReplaceAll(s, "\n", " ")
+}
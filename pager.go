@@ -0,0 +1,59 @@
+package tablib
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Pager pipes text through an interactive pager such as less, so that a
+// long CLIWriter table can be read one screen at a time while it is still
+// being written:
+//
+//	p, err := tablib.NewPager()
+//	cw, err := tablib.NewCLIWriter(p, headers, tablib.CLIOptions{})
+//	// cw.Write each row, then:
+//	cw.Close()
+//	p.Close()
+//
+// Once the user quits the pager, writes fail, which stops the CLIWriter.
+type Pager struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+}
+
+// NewPager starts the pager named by the PAGER environment variable, or
+// less if it is unset, with its output on standard output. PAGER may hold
+// arguments separated by spaces, such as "less -S".
+func NewPager() (*Pager, error) {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = []string{"less"}
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &Pager{cmd: cmd, in: in}, nil
+}
+
+// Write sends p to the pager.
+func (pg *Pager) Write(p []byte) (int, error) {
+	return pg.in.Write(p)
+}
+
+// Close ends the pager's input and waits for the user to quit it.
+func (pg *Pager) Close() error {
+	err := pg.in.Close()
+	if werr := pg.cmd.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
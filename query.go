@@ -0,0 +1,512 @@
+package tablib
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query runs a small SQL subset against the dataset and returns the result
+// as a new Dataset:
+//
+//	SELECT Name, Age WHERE Age > 30 AND NOT Active ORDER BY Age DESC LIMIT 10
+//
+// SELECT takes a list of headers or *. Dynamic columns are selected,
+// filtered and sorted like stored ones, and the result stores their
+// computed values. Headers containing spaces or matching a keyword are
+// quoted with double quotes or backticks, and text literals with single
+// quotes. WHERE supports =, !=, <>, <, <=, >, >=,
+// IS [NOT] NULL, AND, OR, NOT and parentheses; a bare column is true when
+// its value is true. Values compare numerically when both sides are
+// numbers or numeric strings, and as text otherwise. Comparisons against
+// nil are false. ORDER BY sorts stably by one or more columns, which need
+// not be selected, with nil values first in ascending order.
+//
+// Keywords are case-insensitive. Malformed queries return an error
+// wrapping ErrInvalidQuery, and unknown headers return ErrColumnNotFound.
+func (ds *Dataset) Query(query string) (*Dataset, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	// Columns are resolved and read in the effective rows
+	view := ds.withDynamicColumns()
+	p := &queryParser{ds: view, tokens: tokens}
+	q, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return q.run(view), nil
+}
+
+// parsedQuery is a compiled query.
+type parsedQuery struct {
+	columns []int
+	where   func(row []any) bool
+	order   []queryOrder
+	limit   int // -1 for no limit
+}
+
+// queryOrder is an ORDER BY term.
+type queryOrder struct {
+	col  int
+	desc bool
+}
+
+func (q *parsedQuery) run(ds *Dataset) *Dataset {
+	rows := make([]int, 0, len(ds.data))
+	for i, row := range ds.data {
+		if q.where == nil || q.where(row) {
+			rows = append(rows, i)
+		}
+	}
+	if len(q.order) > 0 {
+		slices.SortStableFunc(rows, func(a, b int) int {
+			for _, o := range q.order {
+				c := queryOrderCompare(ds.data[a][o.col], ds.data[b][o.col])
+				if o.desc {
+					c = -c
+				}
+				if c != 0 {
+					return c
+				}
+			}
+			return 0
+		})
+	}
+	if q.limit >= 0 && q.limit < len(rows) {
+		rows = rows[:q.limit]
+	}
+
+	headers := make([]string, len(q.columns))
+	for i, col := range q.columns {
+		headers[i] = ds.headers[col]
+	}
+	result := NewDataset(headers)
+	result.title = ds.title
+	result.separators = ds.selectSeparators(rows)
+	for _, h := range headers {
+		if a, ok := ds.alignments[h]; ok {
+			result.alignments[h] = a
+		}
+		if f, ok := ds.formats[h]; ok {
+			result.formats[h] = f
+		}
+		if t, ok := ds.columnTags[h]; ok {
+			result.columnTags[h] = t
+		}
+	}
+	for _, i := range rows {
+		newRow := make([]any, len(q.columns))
+		for j, col := range q.columns {
+			newRow[j] = ds.data[i][col]
+		}
+		result.data = append(result.data, newRow)
+		t := make([]string, len(ds.tags[i]))
+		copy(t, ds.tags[i])
+		result.tags = append(result.tags, t)
+	}
+	return result
+}
+
+// queryCompare compares two values for a WHERE condition, reporting false
+// when either is nil.
+func queryCompare(a, b any) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if x, ok := numericValue(a); ok {
+		if y, ok := numericValue(b); ok {
+			return cmp.Compare(x, y), true
+		}
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			return cmp.Compare(boolRank(x), boolRank(y)), true
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+	}
+	return cmp.Compare(valueString(a), valueString(b)), true
+}
+
+// queryOrderCompare compares two values for ORDER BY, with nil first.
+func queryOrderCompare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	c, _ := queryCompare(a, b)
+	return c
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type queryTokenKind int
+
+const (
+	queryEOF queryTokenKind = iota
+	queryWord
+	queryQuoted // quoted identifier
+	queryString
+	queryNumber
+	queryOp
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+// lexQuery splits a query into tokens.
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			var sb strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(query) {
+					return nil, fmt.Errorf("%w: unterminated quote at %d", ErrInvalidQuery, start)
+				}
+				if query[i] == c {
+					// A doubled quote is an escaped quote
+					if i+1 < len(query) && query[i+1] == c {
+						sb.WriteByte(c)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(query[i])
+				i++
+			}
+			kind := queryQuoted
+			if c == '\'' {
+				kind = queryString
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: sb.String(), pos: start})
+		case c >= '0' && c <= '9' || c == '.' || c == '-' && i+1 < len(query) && (query[i+1] >= '0' && query[i+1] <= '9' || query[i+1] == '.'):
+			start := i
+			i++
+			for i < len(query) && (query[i] >= '0' && query[i] <= '9' || query[i] == '.' || query[i] == 'e' || query[i] == 'E' ||
+				(query[i] == '+' || query[i] == '-') && (query[i-1] == 'e' || query[i-1] == 'E')) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: queryNumber, text: query[start:i], pos: start})
+		case c == '_' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			start := i
+			for i < len(query) && (query[i] == '_' || query[i] >= 0x80 ||
+				unicode.IsLetter(rune(query[i])) || unicode.IsDigit(rune(query[i]))) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: queryWord, text: query[start:i], pos: start})
+		case strings.HasPrefix(query[i:], "<=") || strings.HasPrefix(query[i:], ">=") ||
+			strings.HasPrefix(query[i:], "!=") || strings.HasPrefix(query[i:], "<>"):
+			tokens = append(tokens, queryToken{kind: queryOp, text: query[i : i+2], pos: i})
+			i += 2
+		case strings.IndexByte("=<>(),*", c) >= 0:
+			tokens = append(tokens, queryToken{kind: queryOp, text: query[i : i+1], pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at %d", ErrInvalidQuery, c, i)
+		}
+	}
+	return append(tokens, queryToken{kind: queryEOF, pos: len(query)}), nil
+}
+
+// queryKeywords are the reserved words, which must be quoted to be used as
+// headers.
+var queryKeywords = map[string]bool{
+	"SELECT": true, "WHERE": true, "ORDER": true, "BY": true, "ASC": true, "DESC": true,
+	"LIMIT": true, "AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true,
+	"TRUE": true, "FALSE": true,
+}
+
+// queryParser is a recursive descent parser for Query.
+type queryParser struct {
+	ds     *Dataset
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != queryEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it is the keyword kw.
+func (p *queryParser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == queryWord && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// op consumes the next token if it is the operator op.
+func (p *queryParser) op(op string) bool {
+	t := p.peek()
+	if t.kind == queryOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	t := p.peek()
+	found := t.text
+	if t.kind == queryEOF {
+		found = "end of query"
+	}
+	return fmt.Errorf("%w: %s, found %q at %d", ErrInvalidQuery, fmt.Sprintf(format, args...), found, t.pos)
+}
+
+func (p *queryParser) parse() (*parsedQuery, error) {
+	q := &parsedQuery{limit: -1}
+	if !p.keyword("SELECT") {
+		return nil, p.errorf("expected SELECT")
+	}
+	if p.op("*") {
+		q.columns = make([]int, len(p.ds.headers))
+		for i := range q.columns {
+			q.columns[i] = i
+		}
+	} else {
+		for {
+			col, err := p.column()
+			if err != nil {
+				return nil, err
+			}
+			q.columns = append(q.columns, col)
+			if !p.op(",") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("WHERE") {
+		where, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		q.where = where
+	}
+
+	if p.keyword("ORDER") {
+		if !p.keyword("BY") {
+			return nil, p.errorf("expected BY")
+		}
+		for {
+			col, err := p.column()
+			if err != nil {
+				return nil, err
+			}
+			o := queryOrder{col: col}
+			if p.keyword("DESC") {
+				o.desc = true
+			} else {
+				p.keyword("ASC")
+			}
+			q.order = append(q.order, o)
+			if !p.op(",") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("LIMIT") {
+		t := p.peek()
+		n, err := strconv.Atoi(t.text)
+		if t.kind != queryNumber || err != nil || n < 0 {
+			return nil, p.errorf("expected a non-negative LIMIT")
+		}
+		p.next()
+		q.limit = n
+	}
+
+	if p.peek().kind != queryEOF {
+		return nil, p.errorf("unexpected token")
+	}
+	return q, nil
+}
+
+// column parses a header reference and returns its index.
+func (p *queryParser) column() (int, error) {
+	t := p.peek()
+	if t.kind != queryQuoted && (t.kind != queryWord || queryKeywords[strings.ToUpper(t.text)]) {
+		return 0, p.errorf("expected a column")
+	}
+	idx := p.ds.columnIndex(t.text)
+	if idx == -1 {
+		return 0, fmt.Errorf("%w: %q", ErrColumnNotFound, t.text)
+	}
+	p.next()
+	return idx, nil
+}
+
+// or parses conditions joined by OR.
+func (p *queryParser) or() (func([]any) bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []any) bool { return l(row) || right(row) }
+	}
+	return left, nil
+}
+
+// and parses conditions joined by AND.
+func (p *queryParser) and() (func([]any) bool, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []any) bool { return l(row) && right(row) }
+	}
+	return left, nil
+}
+
+// not parses an optionally negated condition.
+func (p *queryParser) not() (func([]any) bool, error) {
+	if p.keyword("NOT") {
+		cond, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return func(row []any) bool { return !cond(row) }, nil
+	}
+	if p.op("(") {
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.op(")") {
+			return nil, p.errorf("expected )")
+		}
+		return cond, nil
+	}
+	return p.comparison()
+}
+
+// comparison parses a comparison, an IS [NOT] NULL test or a bare operand.
+func (p *queryParser) comparison() (func([]any) bool, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.keyword("IS") {
+		negate := p.keyword("NOT")
+		if !p.keyword("NULL") {
+			return nil, p.errorf("expected NULL")
+		}
+		return func(row []any) bool { return (left(row) == nil) != negate }, nil
+	}
+
+	t := p.peek()
+	var test func(int) bool
+	switch t.text {
+	case "=":
+		test = func(c int) bool { return c == 0 }
+	case "!=", "<>":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	}
+	if t.kind != queryOp || test == nil {
+		return func(row []any) bool { return left(row) == true }, nil
+	}
+	p.next()
+
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(row []any) bool {
+		c, ok := queryCompare(left(row), right(row))
+		return ok && test(c)
+	}, nil
+}
+
+// operand parses a column reference or a literal.
+func (p *queryParser) operand() (func([]any) any, error) {
+	t := p.peek()
+	var literal any
+	switch {
+	case t.kind == queryString:
+		literal = t.text
+	case t.kind == queryNumber:
+		if n, err := strconv.Atoi(t.text); err == nil {
+			literal = n
+		} else if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+			literal = f
+		} else {
+			return nil, p.errorf("invalid number")
+		}
+	case t.kind == queryWord && strings.EqualFold(t.text, "TRUE"):
+		literal = true
+	case t.kind == queryWord && strings.EqualFold(t.text, "FALSE"):
+		literal = false
+	case t.kind == queryWord && strings.EqualFold(t.text, "NULL"):
+		literal = nil
+	default:
+		col, err := p.column()
+		if err != nil {
+			return nil, err
+		}
+		return func(row []any) any { return row[col] }, nil
+	}
+	p.next()
+	return func([]any) any { return literal }, nil
+}
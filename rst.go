@@ -1,38 +1,67 @@
 package tablib
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/mattn/go-runewidth"
 )
 
 func init() {
-	RegisterExporter(FormatRST, ExporterFunc(exportRST))
+	RegisterExporter(FormatRST, settingsExporterFunc(exportRST))
+}
+
+// RSTStyle selects the kind of reStructuredText table to write.
+type RSTStyle int
+
+const (
+	// RSTGrid writes a grid table with full cell borders (the default).
+	RSTGrid RSTStyle = iota
+	// RSTSimple writes a simple table with "=" column rules, which reads
+	// better in source for narrow tables.
+	RSTSimple
+	// RSTListTable writes a list-table directive, which suits cells with
+	// long text or inline markup. Cell values are written as-is.
+	RSTListTable
+)
+
+// RSTOptions configures reStructuredText export behavior.
+type RSTOptions struct {
+	// Style selects grid or simple tables.
+	Style RSTStyle
+
+	// Title writes the dataset title as a section heading before the
+	// table. List tables carry the title in the directive instead.
+	Title bool
+
+	// EastAsianWidth counts ambiguous-width characters as two cells when
+	// padding columns.
+	EastAsianWidth bool
 }
 
 // exportRST exports the Dataset to reStructuredText grid table format.
-func exportRST(ds *Dataset, w io.Writer) error {
+func exportRST(ds *Dataset, w io.Writer, s exportSettings) error {
+	return exportRSTWithOptions(ds, w, RSTOptions{Title: s.showTitle})
+}
+
+// ExportRST exports the Dataset to reStructuredText with custom options.
+func (ds *Dataset) ExportRST(w io.Writer, opts RSTOptions) error {
+	return exportRSTWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+func exportRSTWithOptions(ds *Dataset, w io.Writer, opts RSTOptions) error {
 	if ds.Width() == 0 {
 		return nil
 	}
 
-	var sb strings.Builder
+	bw := bufio.NewWriter(w)
+	cond := newWidthCondition(opts.EastAsianWidth)
 
 	// Calculate column widths
-	widths := make([]int, ds.Width())
-	for i, h := range ds.headers {
-		if len(h) > widths[i] {
-			widths[i] = len(h)
-		}
-	}
-	for _, row := range ds.data {
-		for i, v := range row {
-			s := fmt.Sprintf("%v", v)
-			if len(s) > widths[i] {
-				widths[i] = len(s)
-			}
-		}
-	}
+	widths := ds.rowWidths(widthKind{eastAsian: opts.EastAsianWidth}, 0)
+	measureCells(widths, ds.headers, cond)
 
 	// Ensure minimum width of 1
 	for i := range widths {
@@ -41,14 +70,28 @@ func exportRST(ds *Dataset, w io.Writer) error {
 		}
 	}
 
+	if opts.Title && ds.title != "" && opts.Style != RSTListTable {
+		bw.WriteString(ds.title + "\n")
+		bw.WriteString(strings.Repeat("=", max(cond.StringWidth(ds.title), 1)) + "\n\n")
+	}
+
+	switch opts.Style {
+	case RSTSimple:
+		writeRSTSimple(bw, ds, widths, cond)
+		return bw.Flush()
+	case RSTListTable:
+		writeRSTListTable(bw, ds, widths)
+		return bw.Flush()
+	}
+
 	// Helper function to write a separator line
 	writeSeparator := func(char string) {
-		sb.WriteString("+")
+		bw.WriteString("+")
 		for _, w := range widths {
-			sb.WriteString(strings.Repeat(char, w+2))
-			sb.WriteString("+")
+			bw.WriteString(strings.Repeat(char, w+2))
+			bw.WriteString("+")
 		}
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 	}
 
 	// Write top border
@@ -56,11 +99,11 @@ func exportRST(ds *Dataset, w io.Writer) error {
 
 	// Write headers
 	if len(ds.headers) > 0 {
-		sb.WriteString("|")
+		bw.WriteString("|")
 		for i, h := range ds.headers {
-			sb.WriteString(fmt.Sprintf(" %-*s |", widths[i], h))
+			bw.WriteString(fmt.Sprintf(" %s |", alignText(h, widths[i], AlignLeft, cond)))
 		}
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 		writeSeparator("=")
 	}
 
@@ -69,46 +112,157 @@ func exportRST(ds *Dataset, w io.Writer) error {
 		// Check for separator before this row
 		if sep, ok := ds.GetSeparator(rowIdx); ok {
 			// Write separator row
-			sb.WriteString("|")
+			bw.WriteString("|")
 			totalWidth := 0
 			for _, w := range widths {
 				totalWidth += w + 3 // +3 for " | "
 			}
 			totalWidth-- // Remove last extra space
-			text := sep.Text
-			if len(text) > totalWidth-2 {
-				text = text[:totalWidth-2]
-			}
-			sb.WriteString(fmt.Sprintf(" %-*s |", totalWidth-2, text))
-			sb.WriteString("\n")
+			text := truncateText(sep.Text, totalWidth-2, cond)
+			bw.WriteString(fmt.Sprintf(" %s |", alignText(text, totalWidth-2, AlignLeft, cond)))
+			bw.WriteString("\n")
 			writeSeparator("-")
 		}
 
-		sb.WriteString("|")
+		bw.WriteString("|")
 		for i, v := range row {
-			sb.WriteString(fmt.Sprintf(" %-*v |", widths[i], v))
+			bw.WriteString(fmt.Sprintf(" %s |", alignText(ds.cellText(i, v), widths[i], AlignLeft, cond)))
 		}
-		sb.WriteString("\n")
+		bw.WriteString("\n")
 		writeSeparator("-")
 	}
 
 	// Check for separator after the last row
 	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
-		sb.WriteString("|")
+		bw.WriteString("|")
 		totalWidth := 0
 		for _, w := range widths {
 			totalWidth += w + 3
 		}
 		totalWidth--
-		text := sep.Text
-		if len(text) > totalWidth-2 {
-			text = text[:totalWidth-2]
-		}
-		sb.WriteString(fmt.Sprintf(" %-*s |", totalWidth-2, text))
-		sb.WriteString("\n")
+		text := truncateText(sep.Text, totalWidth-2, cond)
+		bw.WriteString(fmt.Sprintf(" %s |", alignText(text, totalWidth-2, AlignLeft, cond)))
+		bw.WriteString("\n")
 		writeSeparator("-")
 	}
 
-	_, err := w.Write([]byte(sb.String()))
-	return err
+	return bw.Flush()
+}
+
+// writeRSTSimple writes ds as a simple table. Separators become rows
+// spanning every column, and empty first cells are escaped since a blank
+// first column marks a continuation line.
+func writeRSTSimple(bw *bufio.Writer, ds *Dataset, widths []int, cond *runewidth.Condition) {
+	rowCells := func(row []any) []string {
+		cells := ds.cellTexts(row)
+		if cells[0] == "" {
+			cells[0] = "\\"
+		}
+		return cells
+	}
+	for _, row := range ds.data {
+		if ds.cellText(0, row[0]) == "" {
+			widths[0] = max(widths[0], 2)
+			break
+		}
+	}
+
+	totalWidth := 2 * (len(widths) - 1)
+	for _, w := range widths {
+		totalWidth += w
+	}
+
+	writeRule := func() {
+		rules := make([]string, len(widths))
+		for i, w := range widths {
+			rules[i] = strings.Repeat("=", w)
+		}
+		bw.WriteString(strings.Join(rules, "  "))
+		bw.WriteString("\n")
+	}
+	writeCells := func(cells []string) {
+		padded := make([]string, len(cells))
+		for i, c := range cells {
+			padded[i] = alignText(c, widths[i], AlignLeft, cond)
+		}
+		bw.WriteString(strings.TrimRight(strings.Join(padded, "  "), " "))
+		bw.WriteString("\n")
+	}
+	writeSection := func(text string) {
+		bw.WriteString(truncateText(text, totalWidth, cond))
+		bw.WriteString("\n")
+		bw.WriteString(strings.Repeat("-", totalWidth))
+		bw.WriteString("\n")
+	}
+
+	writeRule()
+	if len(ds.headers) > 0 {
+		writeCells(ds.headers)
+		writeRule()
+	}
+	for rowIdx, row := range ds.data {
+		if sep, ok := ds.GetSeparator(rowIdx); ok {
+			writeSection(sep.Text)
+		}
+		writeCells(rowCells(row))
+	}
+	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
+		writeSection(sep.Text)
+	}
+	writeRule()
+}
+
+// writeRSTListTable writes ds as a list-table directive titled with the
+// dataset title. Column widths are relative to the widest cell in each
+// column, and separators become rows with the bold text in the first cell.
+func writeRSTListTable(bw *bufio.Writer, ds *Dataset, widths []int) {
+	bw.WriteString(".. list-table::")
+	if ds.title != "" {
+		bw.WriteString(" " + ds.title)
+	}
+	bw.WriteString("\n")
+	if len(ds.headers) > 0 {
+		bw.WriteString("   :header-rows: 1\n")
+	}
+	relative := make([]string, len(widths))
+	for i, w := range widths {
+		relative[i] = fmt.Sprint(w)
+	}
+	bw.WriteString(fmt.Sprintf("   :widths: %s\n", strings.Join(relative, " ")))
+	bw.WriteString("\n")
+
+	writeCells := func(cells []string) {
+		for i, c := range cells {
+			bullet := "   * - "
+			if i > 0 {
+				bullet = "     - "
+			}
+			// Continuation lines are indented to the cell text
+			lines := strings.Split(c, "\n")
+			bw.WriteString(strings.TrimRight(bullet+lines[0], " "))
+			bw.WriteString("\n")
+			for _, line := range lines[1:] {
+				bw.WriteString(strings.TrimRight("       "+line, " "))
+				bw.WriteString("\n")
+			}
+		}
+	}
+	writeSection := func(text string) {
+		cells := make([]string, ds.Width())
+		cells[0] = fmt.Sprintf("**%s**", text)
+		writeCells(cells)
+	}
+
+	if len(ds.headers) > 0 {
+		writeCells(ds.headers)
+	}
+	for rowIdx, row := range ds.data {
+		if sep, ok := ds.GetSeparator(rowIdx); ok {
+			writeSection(sep.Text)
+		}
+		writeCells(ds.cellTexts(row))
+	}
+	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
+		writeSection(sep.Text)
+	}
 }
@@ -0,0 +1,83 @@
+package tablib
+
+import (
+	"math"
+	"strings"
+)
+
+// RowPair is a pair of similar rows found by FindSimilarRows.
+type RowPair struct {
+	First, Second int     // row indices, First < Second
+	Similarity    float64 // from 0 (unrelated) to 1 (equal)
+}
+
+// FindSimilarRows compares the values of the column with the specified
+// header between every pair of rows and returns the pairs whose similarity
+// is at least threshold, ordered by row index. Values are compared as text
+// after lower-casing, trimming and collapsing runs of whitespace, and the
+// similarity is one minus the Levenshtein distance divided by the length
+// of the longer value. Equal values have a similarity of 1; nil and empty
+// values are skipped. The threshold must be between 0 and 1, otherwise
+// ErrInvalidData is returned.
+//
+// Every pair of rows is compared, so the cost grows with the square of the
+// height.
+func (ds *Dataset) FindSimilarRows(header string, threshold float64) ([]RowPair, error) {
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return nil, ErrColumnNotFound
+	}
+	if !(threshold >= 0 && threshold <= 1) {
+		return nil, ErrInvalidData
+	}
+
+	values := make([][]rune, len(ds.data))
+	for i, row := range ds.data {
+		values[i] = []rune(strings.ToLower(strings.Join(strings.Fields(valueString(row[col])), " ")))
+	}
+
+	var pairs []RowPair
+	for i, a := range values {
+		if len(a) == 0 {
+			continue
+		}
+		for j := i + 1; j < len(values); j++ {
+			b := values[j]
+			if len(b) == 0 {
+				continue
+			}
+			longest := float64(max(len(a), len(b)))
+			// The distance is at least the difference in length
+			if 1-math.Abs(float64(len(a)-len(b)))/longest < threshold {
+				continue
+			}
+			similarity := 1 - float64(levenshtein(a, b))/longest
+			if similarity >= threshold {
+				pairs = append(pairs, RowPair{First: i, Second: j, Similarity: similarity})
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// levenshtein returns the number of single-rune insertions, deletions and
+// substitutions that turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		curr[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -4,15 +4,21 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 )
 
 func init() {
 	RegisterExporter(FormatSQL, ExporterFunc(exportSQL))
+	RegisterDatabookExporter(FormatSQL, DatabookExporterFunc(exportDatabookSQL))
 }
 
 // SQLOptions configures SQL export behavior.
 type SQLOptions struct {
 	TableName string
+
+	// CreateTable writes a CREATE TABLE statement before the inserts, with
+	// column types inferred from the data.
+	CreateTable bool
 }
 
 func exportSQL(ds *Dataset, w io.Writer) error {
@@ -28,29 +34,42 @@ func exportSQLWithOptions(ds *Dataset, w io.Writer, opts SQLOptions) error {
 		return ErrHeadersRequired
 	}
 
-	if len(ds.data) == 0 {
-		return nil
-	}
-
 	var sb strings.Builder
 
 	// Quote column names
 	columns := make([]string, len(ds.headers))
 	for i, h := range ds.headers {
-		columns[i] = fmt.Sprintf(`"%s"`, h)
+		columns[i] = quoteSQLIdent(h)
 	}
 	columnList := strings.Join(columns, ", ")
 
+	if opts.CreateTable {
+		defs := make([]string, len(columns))
+		for i, c := range columns {
+			defs[i] = fmt.Sprintf("%s %s", c, sqlColumnType(ds, i))
+		}
+		sb.WriteString(fmt.Sprintf("CREATE TABLE %s (%s);\n", quoteSQLIdent(opts.TableName), strings.Join(defs, ", ")))
+	}
+
+	dateCols := make([]bool, len(ds.headers))
+	for i := range dateCols {
+		dateCols[i] = ds.isDateColumn(i)
+	}
+
 	// Generate INSERT statements
 	for _, row := range ds.data {
 		values := make([]string, len(row))
 		for i, v := range row {
+			if t, ok := v.(time.Time); ok && dateCols[i] {
+				values[i] = t.Format("DATE '2006-01-02'")
+				continue
+			}
 			values[i] = sqlValue(v)
 		}
 		valueList := strings.Join(values, ", ")
 
-		sb.WriteString(fmt.Sprintf("INSERT INTO \"%s\" (%s) VALUES (%s);\n",
-			opts.TableName, columnList, valueList))
+		sb.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);\n",
+			quoteSQLIdent(opts.TableName), columnList, valueList))
 	}
 
 	_, err := w.Write([]byte(sb.String()))
@@ -59,7 +78,70 @@ func exportSQLWithOptions(ds *Dataset, w io.Writer, opts SQLOptions) error {
 
 // ExportSQL exports the Dataset to SQL INSERT statements with custom options.
 func (ds *Dataset) ExportSQL(w io.Writer, opts SQLOptions) error {
-	return exportSQLWithOptions(ds, w, opts)
+	return exportSQLWithOptions(ds.withDynamicColumns(), w, opts)
+}
+
+// exportDatabookSQL writes a CREATE TABLE statement and inserts for every
+// sheet, named after the sheet titles, inside a single transaction.
+func exportDatabookSQL(db *Databook, w io.Writer) error {
+	if _, err := io.WriteString(w, "BEGIN;\n"); err != nil {
+		return err
+	}
+	for i, ds := range db.sheets {
+		tableName := ds.title
+		if tableName == "" {
+			tableName = fmt.Sprintf("sheet%d", i+1)
+		}
+		if err := exportSQLWithOptions(ds, w, SQLOptions{TableName: tableName, CreateTable: true}); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "COMMIT;\n")
+	return err
+}
+
+// quoteSQLIdent quotes an identifier, doubling embedded quotes.
+func quoteSQLIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqlColumnType infers a column type from the non-nil values in column
+// col, falling back to TEXT for mixed or unknown values. Times are DATE in
+// date columns and TIMESTAMP otherwise.
+func sqlColumnType(ds *Dataset, col int) string {
+	colType := ""
+	for _, row := range ds.data {
+		var t string
+		switch row[col].(type) {
+		case nil:
+			continue
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			t = "INTEGER"
+		case float32, float64:
+			t = "REAL"
+		case bool:
+			t = "BOOLEAN"
+		case time.Time:
+			t = "TIMESTAMP"
+			if ds.isDateColumn(col) {
+				return "DATE"
+			}
+		default:
+			return "TEXT"
+		}
+		switch {
+		case colType == "" || colType == t:
+			colType = t
+		case colType == "INTEGER" && t == "REAL", colType == "REAL" && t == "INTEGER":
+			colType = "REAL"
+		default:
+			return "TEXT"
+		}
+	}
+	if colType == "" {
+		return "TEXT"
+	}
+	return colType
 }
 
 // sqlValue converts a value to its SQL literal representation.
@@ -82,6 +164,8 @@ func sqlValue(v any) string {
 			return "TRUE"
 		}
 		return "FALSE"
+	case time.Time:
+		return val.Format("TIMESTAMP '2006-01-02 15:04:05.999999999'")
 	default:
 		escaped := strings.ReplaceAll(fmt.Sprintf("%v", val), "'", "''")
 		return fmt.Sprintf("'%s'", escaped)
@@ -0,0 +1,146 @@
+package tablib
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// describeHeaders are the columns of the Dataset returned by Describe.
+var describeHeaders = []string{"Column", "Count", "Distinct", "Nulls", "Min", "Max", "Mean", "StdDev"}
+
+// Describe returns a summary Dataset with one row per column holding the
+// number of non-null values, distinct values and nulls, and the minimum
+// and maximum. nil and empty strings count as null. For columns whose
+// values are all numeric, numeric strings included, Min and Max are
+// float64 and Mean and StdDev (the sample standard deviation) are filled
+// in; otherwise they are nil. Columns of a dataset without headers are
+// named by position from "1".
+func (ds *Dataset) Describe() *Dataset {
+	result := NewDataset(describeHeaders)
+	result.title = ds.title
+	for col := range ds.storedWidth() {
+		name := strconv.Itoa(col + 1)
+		if col < len(ds.headers) {
+			name = ds.headers[col]
+		}
+		result.data = append(result.data, ds.describeColumn(name, col))
+		result.tags = append(result.tags, []string{})
+	}
+	return result
+}
+
+// describeColumn returns the Describe row for column col.
+func (ds *Dataset) describeColumn(name string, col int) []any {
+	var values []any
+	nulls := 0
+	for _, row := range ds.data {
+		if v := row[col]; v == nil || v == "" {
+			nulls++
+		} else {
+			values = append(values, v)
+		}
+	}
+
+	distinct := make(map[string]bool)
+	numbers := make([]float64, 0, len(values))
+	for _, v := range values {
+		distinct[fmt.Sprintf("%T\x00%v", v, v)] = true
+		if x, ok := numericValue(v); ok {
+			numbers = append(numbers, x)
+		}
+	}
+
+	var minV, maxV, mean, stddev any
+	if len(values) > 0 && len(numbers) == len(values) {
+		lo, hi, sum := numbers[0], numbers[0], 0.0
+		for _, x := range numbers {
+			lo, hi, sum = min(lo, x), max(hi, x), sum+x
+		}
+		m := sum / float64(len(numbers))
+		minV, maxV, mean = lo, hi, m
+		if len(numbers) > 1 {
+			var sq float64
+			for _, x := range numbers {
+				sq += (x - m) * (x - m)
+			}
+			stddev = math.Sqrt(sq / float64(len(numbers)-1))
+		}
+	} else if len(values) > 0 {
+		minV, maxV = values[0], values[0]
+		for _, v := range values[1:] {
+			if compareAny(v, minV) < 0 {
+				minV = v
+			}
+			if compareAny(v, maxV) > 0 {
+				maxV = v
+			}
+		}
+	}
+
+	return []any{name, len(values), len(distinct), nulls, minV, maxV, mean, stddev}
+}
+
+// Unique returns the distinct values of the column with the specified
+// header in the order they first appear. Values are distinct when they
+// differ in type or value, so 1 and "1" are both returned; nil is returned
+// once if present.
+func (ds *Dataset) Unique(header string) ([]any, error) {
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return nil, ErrColumnNotFound
+	}
+	seen := make(map[any]bool)
+	values := []any{}
+	for _, row := range ds.data {
+		key := indexKey(row[col])
+		if !seen[key] {
+			seen[key] = true
+			values = append(values, row[col])
+		}
+	}
+	return values, nil
+}
+
+// ValueCounts returns a two-column Dataset holding each distinct value of
+// the column with the specified header and the number of rows containing
+// it, under the headers header and "Count". Rows are sorted by count,
+// highest first, with ties in first-seen order. Values are distinct as in
+// Unique.
+func (ds *Dataset) ValueCounts(header string) (*Dataset, error) {
+	col := ds.headerIndex(header)
+	if col == -1 {
+		return nil, ErrColumnNotFound
+	}
+	positions := make(map[any]int)
+	var values []any
+	var counts []int
+	for _, row := range ds.data {
+		key := indexKey(row[col])
+		i, ok := positions[key]
+		if !ok {
+			i = len(values)
+			positions[key] = i
+			values = append(values, row[col])
+			counts = append(counts, 0)
+		}
+		counts[i]++
+	}
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(counts[b], counts[a])
+	})
+
+	result := NewDataset([]string{header, "Count"})
+	for _, i := range order {
+		result.data = append(result.data, []any{values[i], counts[i]})
+		result.tags = append(result.tags, []string{})
+	}
+	return result, nil
+}
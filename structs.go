@@ -0,0 +1,293 @@
+package tablib
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// structField is a struct field mapped to a column.
+type structField struct {
+	header string
+	index  []int
+}
+
+// structFields returns the column mapping of struct type t: its exported
+// fields in declaration order, including those promoted from embedded
+// structs. The `tablib:"header"` tag renames a column and `tablib:"-"`
+// skips the field.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		tag, hasTag := f.Tag.Lookup("tablib")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && !hasTag {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				continue // promoted fields are listed separately
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, structField{header: name, index: f.Index})
+	}
+	return fields
+}
+
+// structType returns the struct type of a slice element, which may be a
+// struct or a pointer to one.
+func structType(elem reflect.Type) (reflect.Type, bool) {
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	return elem, elem.Kind() == reflect.Struct
+}
+
+// FromStructs creates a Dataset from a slice or array of structs or
+// struct pointers. Each exported field becomes a column, headed by its
+// name or its `tablib:"header"` tag; fields tagged `tablib:"-"` are
+// skipped, and fields of embedded structs are promoted. Nil pointers give
+// rows of nil cells. Other values return ErrInvalidData.
+func FromStructs(v any) (*Dataset, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, ErrInvalidData
+	}
+	t, ok := structType(rv.Type().Elem())
+	if !ok {
+		return nil, ErrInvalidData
+	}
+
+	fields := structFields(t)
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.header
+	}
+
+	ds := NewDataset(headers)
+	ds.data = make([][]any, 0, rv.Len())
+	ds.tags = make([][]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i)
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem() // the zero Value for nil pointers
+		}
+		row := make([]any, len(fields))
+		if elem.IsValid() {
+			for j, f := range fields {
+				// Fields behind nil embedded pointers stay nil
+				if fv, err := elem.FieldByIndexErr(f.index); err == nil {
+					row[j] = fv.Interface()
+				}
+			}
+		}
+		ds.data = append(ds.data, row)
+		ds.tags = append(ds.tags, []string{})
+	}
+	return ds, nil
+}
+
+// ToStructs scans the rows into dest, a pointer to a slice of structs or
+// struct pointers, replacing its contents. Columns map to fields by header
+// using the same rules as FromStructs; headers without a field and fields
+// without a column are ignored.
+//
+// Cells are converted to the field type where possible: numbers and
+// numeric strings to numeric fields, any value to its text for string
+// fields, "true"/"false" strings to bools, RFC 3339 and "2006-01-02"
+// strings to time.Time, and strings to types implementing
+// encoding.TextUnmarshaler. nil and empty strings leave non-string fields
+// zero, and pointer fields are allocated as needed. A cell that cannot be
+// converted returns an error wrapping ErrInvalidData.
+func (ds *Dataset) ToStructs(dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return ErrInvalidData
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	t, ok := structType(elemType)
+	if !ok {
+		return ErrInvalidData
+	}
+
+	type mapping struct {
+		col   int
+		field structField
+	}
+	var mappings []mapping
+	for _, f := range structFields(t) {
+		if col := ds.headerIndex(f.header); col != -1 {
+			mappings = append(mappings, mapping{col: col, field: f})
+		}
+	}
+
+	out := reflect.MakeSlice(slice.Type(), len(ds.data), len(ds.data))
+	for i, row := range ds.data {
+		elem := out.Index(i)
+		if elemType.Kind() == reflect.Pointer {
+			elem.Set(reflect.New(t))
+			elem = elem.Elem()
+		}
+		for _, m := range mappings {
+			fv, err := elem.FieldByIndexErr(m.field.index)
+			if err != nil {
+				// Allocate nil embedded struct pointers on the way
+				fv = fieldByIndexAlloc(elem, m.field.index)
+			}
+			if err := setField(fv, row[m.col]); err != nil {
+				return fmt.Errorf("%w: row %d, column %q: %v", ErrInvalidData, i, m.field.header, err)
+			}
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+// fieldByIndexAlloc returns the nested field of v at index, allocating
+// nil embedded struct pointers.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// setField converts v to the type of field and stores it.
+func setField(field reflect.Value, v any) error {
+	if v == nil {
+		return nil
+	}
+	src := reflect.ValueOf(v)
+	if src.Type().AssignableTo(field.Type()) {
+		field.Set(src)
+		return nil
+	}
+	if s, ok := v.(string); ok && s == "" && field.Kind() != reflect.String {
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), v); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if field.Type() == reflect.TypeFor[time.Time]() {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to time.Time", v)
+		}
+		if tm, ok := parseTime(s, defaultDateLayouts); ok && tm != nil {
+			field.Set(reflect.ValueOf(tm))
+			return nil
+		}
+		return fmt.Errorf("cannot parse %q as a time", s)
+	}
+
+	if s, ok := v.(string); ok {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(valueString(v))
+		return nil
+	case reflect.Bool:
+		switch b := v.(type) {
+		case bool:
+			field.SetBool(b)
+			return nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+			if err == nil {
+				field.SetBool(parsed)
+				return nil
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := intValue(src); ok && !field.OverflowInt(n) {
+			field.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := uintValue(src); ok && !field.OverflowUint(n) {
+			field.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := numericValue(v); ok && !field.OverflowFloat(f) {
+			field.SetFloat(f)
+			return nil
+		}
+	}
+	if src.Type().ConvertibleTo(field.Type()) && src.Kind() == field.Kind() {
+		field.Set(src.Convert(field.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot convert %T %v to %s", v, v, field.Type())
+}
+
+// intValue converts integers, whole floats and integer strings to int64
+// without going through float64 where possible, so large values keep
+// their precision.
+func intValue(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint()), v.Uint() <= math.MaxInt64
+	case reflect.String:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v.String()), 10, 64); err == nil {
+			return n, true
+		}
+	}
+	f, ok := numericValue(v.Interface())
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// uintValue is the unsigned counterpart of intValue.
+func uintValue(v reflect.Value) (uint64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()), v.Int() >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	case reflect.String:
+		if n, err := strconv.ParseUint(strings.TrimSpace(v.String()), 10, 64); err == nil {
+			return n, true
+		}
+	}
+	f, ok := numericValue(v.Interface())
+	if !ok || f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+		return 0, false
+	}
+	return uint64(f), true
+}
@@ -0,0 +1,120 @@
+package tablib
+
+import (
+	"io"
+	"sync"
+)
+
+// SyncDataset is a Dataset that is safe for concurrent use. Its methods
+// guard the dataset with a read-write lock: readers run in parallel, and
+// writers run alone. Operations without a method of their own run under
+// the lock with Read and Write.
+type SyncDataset struct {
+	mu sync.RWMutex
+	ds *Dataset
+}
+
+// NewSyncDataset creates a new empty Dataset that is safe for concurrent
+// use.
+func NewSyncDataset(headers []string) *SyncDataset {
+	return NewDataset(headers).Synchronized()
+}
+
+// Synchronized returns a SyncDataset guarding the dataset. The dataset
+// must no longer be used directly.
+func (ds *Dataset) Synchronized() *SyncDataset {
+	return &SyncDataset{ds: ds}
+}
+
+// Read calls fn with the dataset under the read lock. fn must not modify
+// the dataset or keep it after returning.
+func (s *SyncDataset) Read(fn func(ds *Dataset)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.ds)
+}
+
+// Write calls fn with the dataset under the write lock and returns its
+// error. fn must not keep the dataset after returning.
+func (s *SyncDataset) Write(fn func(ds *Dataset) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.ds)
+}
+
+// Snapshot returns a copy of the dataset, which the caller owns.
+func (s *SyncDataset) Snapshot() *Dataset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ds.Copy()
+}
+
+// Append adds a row to the dataset, as Dataset.Append.
+func (s *SyncDataset) Append(row []any, rowTags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ds.Append(row, rowTags...)
+}
+
+// Insert inserts a row at the specified index, as Dataset.Insert.
+func (s *SyncDataset) Insert(index int, row []any, rowTags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ds.Insert(index, row, rowTags...)
+}
+
+// Pop removes and returns the row at the specified index, as Dataset.Pop.
+func (s *SyncDataset) Pop(index int) ([]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ds.Pop(index)
+}
+
+// Set sets a cell value, as Dataset.Set.
+func (s *SyncDataset) Set(row, col int, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ds.Set(row, col, value)
+}
+
+// Get returns a cell value, as Dataset.Get.
+func (s *SyncDataset) Get(row, col int) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ds.Get(row, col)
+}
+
+// Row returns a copy of the row at the specified index, as Dataset.Row.
+func (s *SyncDataset) Row(index int) ([]any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ds.Row(index)
+}
+
+// Headers returns a copy of the headers.
+func (s *SyncDataset) Headers() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ds.Headers()
+}
+
+// Height returns the number of rows.
+func (s *SyncDataset) Height() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ds.Height()
+}
+
+// Width returns the number of columns.
+func (s *SyncDataset) Width() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ds.Width()
+}
+
+// Export exports the dataset to the specified format under the read lock.
+func (s *SyncDataset) Export(format Format, w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ds.Export(format, w)
+}
@@ -0,0 +1,43 @@
+package tablib
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Text exporters measure cells by terminal display width rather than byte
+// length so that CJK characters and emoji keep columns aligned.
+
+// newWidthCondition returns the display-width rules for a text export. When
+// eastAsian is true, characters of ambiguous width (e.g. "±", "→", Cyrillic
+// in some fonts) are counted as two cells, matching East Asian terminals.
+func newWidthCondition(eastAsian bool) *runewidth.Condition {
+	cond := runewidth.NewCondition()
+	cond.EastAsianWidth = eastAsian
+	return cond
+}
+
+// alignText pads s to width display cells according to the alignment hint.
+func alignText(s string, width int, align Alignment, cond *runewidth.Condition) string {
+	pad := width - cond.StringWidth(s)
+	if pad <= 0 {
+		return s
+	}
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", pad) + s
+	case AlignCenter:
+		left := pad / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", pad-left)
+	}
+	return s + strings.Repeat(" ", pad)
+}
+
+// truncateText cuts s to at most width display cells.
+func truncateText(s string, width int, cond *runewidth.Condition) string {
+	if width <= 0 {
+		return ""
+	}
+	return cond.Truncate(s, width, "")
+}
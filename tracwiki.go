@@ -0,0 +1,67 @@
+package tablib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	RegisterExporter(FormatTracWiki, ExporterFunc(exportTracWiki))
+}
+
+// exportTracWiki exports the Dataset to TracWiki table markup.
+func exportTracWiki(ds *Dataset, w io.Writer) error {
+	if ds.Width() == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+
+	// Write headers (TracWiki marks header cells with ||= ... =||)
+	if len(ds.headers) > 0 {
+		sb.WriteString("||")
+		for _, h := range ds.headers {
+			sb.WriteString(fmt.Sprintf("= %s =||", escapeTracWiki(h)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// writeSeparator writes a bold row spanning every column; each extra
+	// leading || widens the cell by one column
+	writeSeparator := func(text string) {
+		sb.WriteString(strings.Repeat("||", ds.Width()))
+		sb.WriteString(fmt.Sprintf(" '''%s''' ||\n", escapeTracWiki(text)))
+	}
+
+	// Write data rows
+	for rowIdx, row := range ds.data {
+		// Check for separator before this row
+		if sep, ok := ds.GetSeparator(rowIdx); ok {
+			writeSeparator(sep.Text)
+		}
+
+		sb.WriteString("||")
+		for i, v := range row {
+			sb.WriteString(fmt.Sprintf(" %s ||", escapeTracWiki(ds.cellText(i, v))))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Check for separator after the last row
+	if sep, ok := ds.GetSeparator(len(ds.data)); ok {
+		writeSeparator(sep.Text)
+	}
+
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
+
+// escapeTracWiki wraps cells containing the || delimiter in a {{{ }}}
+// literal block and turns newlines into [[BR]] line breaks.
+func escapeTracWiki(s string) string {
+	if strings.Contains(s, "||") {
+		s = "{{{" + s + "}}}"
+	}
+	return strings.ReplaceAll(s, "\n", "[[BR]]")
+}
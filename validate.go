@@ -0,0 +1,256 @@
+package tablib
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule is a validation rule for the values of the column with the
+// specified header. Check returns an error describing why a value is
+// invalid, or nil; it is called for every value, including nil. The rule
+// constructors below skip nil and empty values except for RequiredRule.
+type Rule struct {
+	Header string
+	Name   string // identifies the rule in violations
+	Check  func(v any) error
+}
+
+// ValueType is a value type checked by TypeRule.
+type ValueType int
+
+const (
+	TypeString ValueType = iota
+	TypeInt
+	TypeFloat // any number
+	TypeBool
+	TypeTime
+)
+
+// String returns the name of the type.
+func (t ValueType) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeTime:
+		return "time"
+	}
+	return fmt.Sprintf("ValueType(%d)", int(t))
+}
+
+// isBlank reports whether v is nil or a string of whitespace.
+func isBlank(v any) bool {
+	s, ok := v.(string)
+	return v == nil || ok && strings.TrimSpace(s) == ""
+}
+
+// skipBlank wraps check so that nil and empty values pass.
+func skipBlank(check func(v any) error) func(v any) error {
+	return func(v any) error {
+		if isBlank(v) {
+			return nil
+		}
+		return check(v)
+	}
+}
+
+// RequiredRule rejects nil and empty values.
+func RequiredRule(header string) Rule {
+	return Rule{Header: header, Name: "required", Check: checkRequired}
+}
+
+func checkRequired(v any) error {
+	if isBlank(v) {
+		return fmt.Errorf("value is required")
+	}
+	return nil
+}
+
+// TypeRule rejects values that are not of type t. Strings such as those
+// read from CSV are accepted when they parse as t: integers, numbers,
+// "true"/"false", or times in the layouts ParseDates tries by default.
+func TypeRule(header string, t ValueType) Rule {
+	return Rule{Header: header, Name: "type", Check: skipBlank(func(v any) error {
+		if !hasType(v, t) {
+			return fmt.Errorf("%v is not of type %s", v, t)
+		}
+		return nil
+	})}
+}
+
+// hasType reports whether v is of type t or a string that parses as t.
+func hasType(v any, t ValueType) bool {
+	s, isString := v.(string)
+	s = strings.TrimSpace(s)
+	switch t {
+	case TypeString:
+		return isString
+	case TypeInt:
+		if isString {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		}
+		switch v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		}
+	case TypeFloat:
+		_, ok := numericValue(v)
+		return ok
+	case TypeBool:
+		if isString {
+			_, err := strconv.ParseBool(s)
+			return err == nil
+		}
+		_, ok := v.(bool)
+		return ok
+	case TypeTime:
+		if isString {
+			_, ok := parseTime(s, defaultDateLayouts)
+			return ok
+		}
+		_, ok := v.(time.Time)
+		return ok
+	}
+	return false
+}
+
+// RangeRule rejects values that are not numbers between min and max,
+// inclusive. Numeric strings are accepted.
+func RangeRule(header string, min, max float64) Rule {
+	return Rule{Header: header, Name: "range", Check: skipBlank(func(v any) error {
+		f, ok := numericValue(v)
+		if !ok {
+			return fmt.Errorf("%v is not a number", v)
+		}
+		if f < min || f > max {
+			return fmt.Errorf("%v is not between %v and %v", v, min, max)
+		}
+		return nil
+	})}
+}
+
+// PatternRule rejects values whose text does not match re. Use anchors
+// to match the whole value.
+func PatternRule(header string, re *regexp.Regexp) Rule {
+	return Rule{Header: header, Name: "pattern", Check: skipBlank(patternCheck(re))}
+}
+
+func patternCheck(re *regexp.Regexp) func(v any) error {
+	return func(v any) error {
+		if !re.MatchString(valueString(v)) {
+			return fmt.Errorf("%q does not match %s", valueString(v), re)
+		}
+		return nil
+	}
+}
+
+// EnumRule rejects values other than the specified ones. Values match when
+// their text is equal, so the string "1" matches the integer 1.
+func EnumRule(header string, values ...any) Rule {
+	allowed := make(map[string]bool, len(values))
+	for _, v := range values {
+		allowed[valueString(v)] = true
+	}
+	return Rule{Header: header, Name: "enum", Check: skipBlank(func(v any) error {
+		if !allowed[valueString(v)] {
+			return fmt.Errorf("%q is not an allowed value", valueString(v))
+		}
+		return nil
+	})}
+}
+
+// CustomRule rejects the values for which valid returns false. Unlike the
+// other rules, valid also receives nil and empty values.
+func CustomRule(header, name string, valid func(v any) bool) Rule {
+	return Rule{Header: header, Name: name, Check: func(v any) error {
+		if !valid(v) {
+			return fmt.Errorf("%v is invalid", v)
+		}
+		return nil
+	}}
+}
+
+// Violation is a value that failed a validation rule.
+type Violation struct {
+	Row     int    // row index, or -1 when the column does not exist
+	Column  string // header of the column
+	Rule    string // name of the rule
+	Value   any
+	Message string
+}
+
+// ValidationReport lists the violations found by Validate, ordered by row
+// and then by rule.
+type ValidationReport struct {
+	Violations []Violation
+}
+
+// Valid reports whether no rule was violated.
+func (r *ValidationReport) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// Err returns nil for a valid report, and otherwise an error wrapping
+// ErrInvalidData that describes the first violation.
+func (r *ValidationReport) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	v := r.Violations[0]
+	return fmt.Errorf("%w: %d violations, first at row %d, column %q: %s", ErrInvalidData, len(r.Violations), v.Row, v.Column, v.Message)
+}
+
+// Dataset returns the violations as a Dataset with the headers Row,
+// Column, Rule, Value and Message, ready for export.
+func (r *ValidationReport) Dataset() *Dataset {
+	ds := NewDataset([]string{"Row", "Column", "Rule", "Value", "Message"})
+	for _, v := range r.Violations {
+		ds.Append([]any{v.Row, v.Column, v.Rule, v.Value, v.Message})
+	}
+	return ds
+}
+
+// Validate checks every value of the dataset against the rules and
+// reports the violations. A rule whose header is not in the dataset gives
+// a single violation with row -1. The dataset is not modified.
+func (ds *Dataset) Validate(rules []Rule) *ValidationReport {
+	report := &ValidationReport{}
+	cols := make([]int, len(rules))
+	for i, rule := range rules {
+		cols[i] = ds.headerIndex(rule.Header)
+		if cols[i] == -1 {
+			report.Violations = append(report.Violations, Violation{
+				Row:     -1,
+				Column:  rule.Header,
+				Rule:    rule.Name,
+				Message: ErrColumnNotFound.Error(),
+			})
+		}
+	}
+	for r, row := range ds.data {
+		for i, rule := range rules {
+			if cols[i] == -1 || rule.Check == nil {
+				continue
+			}
+			if err := rule.Check(row[cols[i]]); err != nil {
+				report.Violations = append(report.Violations, Violation{
+					Row:     r,
+					Column:  rule.Header,
+					Rule:    rule.Name,
+					Value:   row[cols[i]],
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+	return report
+}
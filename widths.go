@@ -0,0 +1,87 @@
+package tablib
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// widthKind identifies how text exports measure cells.
+type widthKind struct {
+	eastAsian bool // see MarkdownOptions.EastAsianWidth
+	markdown  bool // cells are escaped for Markdown first
+}
+
+// widthCache caches the display width of the widest cell of each column
+// for text exports, so that exporting a large dataset several ways scans
+// the rows once per kind of measurement. It belongs to the dataset it was
+// created for: views of the dataset made by copying the struct share the
+// pointer but hold other rows, and do not use it. The mutex guards it
+// because exports run under a SyncDataset read lock.
+type widthCache struct {
+	mu     sync.Mutex
+	owner  *Dataset
+	widths map[widthKind][]int
+}
+
+// rowWidths returns the display width of the widest cell of each column
+// in the first n rows, or in every row when n is zero or at least the
+// height; headers are not measured. Widths of every row are cached until
+// the rows or column formats change.
+func (ds *Dataset) rowWidths(kind widthKind, n int) []int {
+	rows := ds.data
+	if n > 0 && n < len(rows) {
+		return ds.measureRows(kind, rows[:n])
+	}
+	c := ds.widths
+	if c == nil || c.owner != ds {
+		return ds.measureRows(kind, rows)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	widths, ok := c.widths[kind]
+	if !ok {
+		widths = ds.measureRows(kind, rows)
+		if c.widths == nil {
+			c.widths = make(map[widthKind][]int)
+		}
+		c.widths[kind] = widths
+	}
+	return slices.Clone(widths)
+}
+
+// measureRows returns the display width of the widest cell of each column
+// in rows.
+func (ds *Dataset) measureRows(kind widthKind, rows [][]any) []int {
+	cond := newWidthCondition(kind.eastAsian)
+	widths := make([]int, ds.storedWidth())
+	for _, row := range rows {
+		for i, v := range row {
+			s := ds.cellText(i, v)
+			if kind.markdown {
+				s = escapeMarkdown(s)
+			}
+			widths[i] = max(widths[i], cond.StringWidth(s))
+		}
+	}
+	return widths
+}
+
+// measureCells widens widths to fit cells.
+func measureCells(widths []int, cells []string, cond *runewidth.Condition) {
+	for i, c := range cells {
+		widths[i] = max(widths[i], cond.StringWidth(c))
+	}
+}
+
+// dropWidths discards the cached column widths after a change to the rows
+// or to how cells are displayed.
+func (ds *Dataset) dropWidths() {
+	if c := ds.widths; c != nil {
+		c.mu.Lock()
+		c.widths = nil
+		c.mu.Unlock()
+	}
+}
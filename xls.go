@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 func init() {
 	RegisterExporter(FormatXLS, ExporterFunc(exportXLS))
+	RegisterImporter(FormatXLS, ImporterFunc(importXLS))
 	RegisterDatabookExporter(FormatXLS, DatabookExporterFunc(exportXLSDatabook))
+	RegisterDatabookImporter(FormatXLS, DatabookImporterFunc(ImportXLSDatabook))
 }
 
 // XLS export uses Microsoft Spreadsheet XML format which can be opened by Excel.
@@ -31,21 +34,56 @@ type xlsStyles struct {
 }
 
 type xlsStyle struct {
-	ID   string  `xml:"ss:ID,attr"`
-	Font *xlsFont `xml:"Font,omitempty"`
+	ID           string           `xml:"ss:ID,attr"`
+	Name         string           `xml:"ss:Name,attr,omitempty"`
+	Alignment    *xlsAlignment    `xml:"Alignment,omitempty"`
+	Borders      *xlsBorders      `xml:"Borders,omitempty"`
+	Font         *xlsFont         `xml:"Font,omitempty"`
+	Interior     *xlsInterior     `xml:"Interior,omitempty"`
+	NumberFormat *xlsNumberFormat `xml:"NumberFormat,omitempty"`
+}
+
+type xlsAlignment struct {
+	Horizontal string `xml:"ss:Horizontal,attr,omitempty"`
+	Vertical   string `xml:"ss:Vertical,attr,omitempty"`
+	WrapText   int    `xml:"ss:WrapText,attr,omitempty"`
+}
+
+type xlsBorders struct {
+	Borders []xlsBorder `xml:"Border"`
+}
+
+type xlsBorder struct {
+	Position  string `xml:"ss:Position,attr"`
+	LineStyle string `xml:"ss:LineStyle,attr"`
+	Weight    int    `xml:"ss:Weight,attr"`
 }
 
 type xlsFont struct {
 	Bold int `xml:"ss:Bold,attr,omitempty"`
 }
 
+type xlsInterior struct {
+	Color   string `xml:"ss:Color,attr"`
+	Pattern string `xml:"ss:Pattern,attr"`
+}
+
+type xlsNumberFormat struct {
+	Format string `xml:"ss:Format,attr"`
+}
+
 type xlsWorksheet struct {
 	Name  string   `xml:"ss:Name,attr"`
 	Table xlsTable `xml:"Table"`
 }
 
 type xlsTable struct {
-	Rows []xlsRow `xml:"Row"`
+	Columns []xlsColumn `xml:"Column"`
+	Rows    []xlsRow    `xml:"Row"`
+}
+
+type xlsColumn struct {
+	Width float64 `xml:"ss:Width,attr"`
 }
 
 type xlsRow struct {
@@ -72,16 +110,13 @@ func exportXLSDatabook(db *Databook, w io.Writer) error {
 
 func exportXLSSheets(w io.Writer, sheets []*Dataset) error {
 	workbook := xlsWorkbook{
-		XMLNS:     "urn:schemas-microsoft-com:office:spreadsheet",
+		XMLNS:     xlsNamespace,
 		XMLNSO:    "urn:schemas-microsoft-com:office:office",
 		XMLNSX:    "urn:schemas-microsoft-com:office:excel",
-		XMLNSS:    "urn:schemas-microsoft-com:office:spreadsheet",
+		XMLNSS:    xlsNamespace,
 		XMLNSHTML: "http://www.w3.org/TR/REC-html40",
 		Styles: xlsStyles{
-			Styles: []xlsStyle{
-				{ID: "Default"},
-				{ID: "Header", Font: &xlsFont{Bold: 1}},
-			},
+			Styles: xlsDefaultStyles(),
 		},
 	}
 
@@ -92,6 +127,7 @@ func exportXLSSheets(w io.Writer, sheets []*Dataset) error {
 		if worksheet.Name == "" {
 			worksheet.Name = "Sheet"
 		}
+		worksheet.Table.Columns = xlsColumns(ds)
 
 		// Add header row
 		if len(ds.headers) > 0 {
@@ -119,17 +155,24 @@ func exportXLSSheets(w io.Writer, sheets []*Dataset) error {
 				cell := xlsCell{}
 				switch val := v.(type) {
 				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+					cell.StyleID = "Integer"
 					cell.Data = xlsData{Type: "Number", Value: fmt.Sprintf("%v", val)}
 				case float32, float64:
+					cell.StyleID = "Decimal"
 					cell.Data = xlsData{Type: "Number", Value: fmt.Sprintf("%v", val)}
 				case bool:
 					boolVal := "0"
 					if val {
 						boolVal = "1"
 					}
+					cell.StyleID = "Boolean"
 					cell.Data = xlsData{Type: "Boolean", Value: boolVal}
 				default:
-					cell.Data = xlsData{Type: "String", Value: fmt.Sprintf("%v", val)}
+					text := valueString(val)
+					if strings.Contains(text, "\n") || xlsTextWidth(text) > xlsMaxColumnChars {
+						cell.StyleID = "Wrap"
+					}
+					cell.Data = xlsData{Type: "String", Value: text}
 				}
 				dataRow.Cells[i] = cell
 			}
@@ -154,18 +197,116 @@ func exportXLSSheets(w io.Writer, sheets []*Dataset) error {
 	return encoder.Encode(workbook)
 }
 
-// ImportXLS imports data from an XLS file.
-// Note: This only supports the XML Spreadsheet format, not the binary BIFF format.
-func ImportXLS(r io.Reader, sheetName string) (*Dataset, error) {
-	// Parse the XML
-	var workbook xlsWorkbook
+// Column sizing for SpreadsheetML output. ss:Width is expressed in points;
+// xlsCharWidth approximates one character of the default font.
+const (
+	xlsCharWidth      = 7.0
+	xlsColumnPadding  = 10.0
+	xlsMinColumnChars = 4
+	xlsMaxColumnChars = 60
+)
+
+// xlsDefaultStyles returns the named styles shared by every exported workbook.
+func xlsDefaultStyles() []xlsStyle {
+	return []xlsStyle{
+		{ID: "Default", Name: "Normal", Alignment: &xlsAlignment{Vertical: "Top"}},
+		{
+			ID:        "Header",
+			Name:      "Header",
+			Alignment: &xlsAlignment{Horizontal: "Center", Vertical: "Center", WrapText: 1},
+			Borders: &xlsBorders{Borders: []xlsBorder{
+				{Position: "Bottom", LineStyle: "Continuous", Weight: 1},
+			}},
+			Font:     &xlsFont{Bold: 1},
+			Interior: &xlsInterior{Color: "#D9D9D9", Pattern: "Solid"},
+		},
+		{ID: "Integer", Name: "Integer", Alignment: &xlsAlignment{Horizontal: "Right", Vertical: "Top"}, NumberFormat: &xlsNumberFormat{Format: "0"}},
+		{ID: "Decimal", Name: "Decimal", Alignment: &xlsAlignment{Horizontal: "Right", Vertical: "Top"}},
+		{ID: "Boolean", Name: "Boolean", Alignment: &xlsAlignment{Horizontal: "Center", Vertical: "Top"}},
+		{ID: "Wrap", Name: "Wrap", Alignment: &xlsAlignment{Horizontal: "Left", Vertical: "Top", WrapText: 1}},
+	}
+}
+
+// xlsColumns computes ss:Width column definitions from the widest header or
+// cell in each column. Long text is capped and left to wrap.
+func xlsColumns(ds *Dataset) []xlsColumn {
+	width := ds.Width()
+	if width == 0 {
+		return nil
+	}
+
+	chars := make([]int, width)
+	for i, h := range ds.headers {
+		chars[i] = xlsTextWidth(h)
+	}
+	for _, row := range ds.data {
+		for i, v := range row {
+			if i < width {
+				chars[i] = max(chars[i], xlsTextWidth(valueString(v)))
+			}
+		}
+	}
+
+	columns := make([]xlsColumn, width)
+	for i, n := range chars {
+		n = min(max(n, xlsMinColumnChars), xlsMaxColumnChars)
+		columns[i] = xlsColumn{Width: float64(n)*xlsCharWidth + xlsColumnPadding}
+	}
+	return columns
+}
+
+// xlsTextWidth returns the length in characters of the longest line of s.
+func xlsTextWidth(s string) int {
+	widest := 0
+	for _, line := range strings.Split(s, "\n") {
+		widest = max(widest, utf8.RuneCountInString(line))
+	}
+	return widest
+}
+
+// xlsNamespace is the SpreadsheetML namespace used by the ss: prefix.
+const xlsNamespace = "urn:schemas-microsoft-com:office:spreadsheet"
+
+// Import-side structures. The export structures use literal "ss:" prefixes,
+// which the decoder does not resolve, so attributes are matched by namespace.
+type xlsImportWorkbook struct {
+	Worksheets []xlsImportWorksheet `xml:"Worksheet"`
+}
+
+type xlsImportWorksheet struct {
+	Name  string `xml:"urn:schemas-microsoft-com:office:spreadsheet Name,attr"`
+	Table struct {
+		Rows []struct {
+			Cells []struct {
+				Data string `xml:"Data"`
+			} `xml:"Cell"`
+		} `xml:"Row"`
+	} `xml:"Table"`
+}
+
+func importXLS(r io.Reader) (*Dataset, error) {
+	return ImportXLS(r, "")
+}
+
+func parseXLSWorkbook(r io.Reader) (*xlsImportWorkbook, error) {
+	var workbook xlsImportWorkbook
 	decoder := xml.NewDecoder(r)
 	if err := decoder.Decode(&workbook); err != nil {
 		return nil, fmt.Errorf("failed to parse XLS XML: %w", err)
 	}
+	return &workbook, nil
+}
+
+// ImportXLS imports data from an XLS file.
+// Note: This only supports the XML Spreadsheet format, not the binary BIFF format.
+func ImportXLS(r io.Reader, sheetName string) (*Dataset, error) {
+	workbook, err := parseXLSWorkbook(r)
+	if err != nil {
+		return nil, err
+	}
 
 	// Find the requested sheet
-	var targetSheet *xlsWorksheet
+	var targetSheet *xlsImportWorksheet
 	for i := range workbook.Worksheets {
 		ws := &workbook.Worksheets[i]
 		if sheetName == "" || ws.Name == sheetName {
@@ -177,30 +318,54 @@ func ImportXLS(r io.Reader, sheetName string) (*Dataset, error) {
 		return nil, fmt.Errorf("sheet '%s' not found", sheetName)
 	}
 
-	// Convert to Dataset
-	if len(targetSheet.Table.Rows) == 0 {
-		return NewDataset(nil), nil
+	return readXLSWorksheet(targetSheet)
+}
+
+// ImportXLSDatabook imports all worksheets from an XLS file into a Databook.
+// Note: This only supports the XML Spreadsheet format, not the binary BIFF format.
+func ImportXLSDatabook(r io.Reader) (*Databook, error) {
+	workbook, err := parseXLSWorkbook(r)
+	if err != nil {
+		return nil, err
+	}
+
+	db := NewDatabook()
+	for i := range workbook.Worksheets {
+		ds, err := readXLSWorksheet(&workbook.Worksheets[i])
+		if err != nil {
+			return nil, err
+		}
+		db.AddSheet(ds)
+	}
+
+	return db, nil
+}
+
+func readXLSWorksheet(ws *xlsImportWorksheet) (*Dataset, error) {
+	rows := ws.Table.Rows
+	if len(rows) == 0 {
+		ds := NewDataset(nil)
+		ds.SetTitle(ws.Name)
+		return ds, nil
 	}
 
 	// First row as headers
 	var headers []string
-	if len(targetSheet.Table.Rows) > 0 {
-		for _, cell := range targetSheet.Table.Rows[0].Cells {
-			headers = append(headers, strings.TrimSpace(cell.Data.Value))
-		}
+	for _, cell := range rows[0].Cells {
+		headers = append(headers, strings.TrimSpace(cell.Data))
 	}
 
 	ds := NewDataset(headers)
-	ds.SetTitle(targetSheet.Name)
+	ds.SetTitle(ws.Name)
 
 	// Remaining rows as data
-	for i := 1; i < len(targetSheet.Table.Rows); i++ {
+	for i := 1; i < len(rows); i++ {
 		row := make([]any, len(headers))
-		for j, cell := range targetSheet.Table.Rows[i].Cells {
+		for j, cell := range rows[i].Cells {
 			if j >= len(headers) {
 				break
 			}
-			row[j] = strings.TrimSpace(cell.Data.Value)
+			row[j] = strings.TrimSpace(cell.Data)
 		}
 		if err := ds.Append(row); err != nil {
 			return nil, err
@@ -3,17 +3,21 @@ package tablib
 import (
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/xuri/excelize/v2"
 )
 
 func init() {
-	RegisterExporter(FormatXLSX, ExporterFunc(exportXLSX))
+	RegisterExporter(FormatXLSX, settingsExporterFunc(exportXLSX))
 	RegisterImporter(FormatXLSX, ImporterFunc(importXLSX))
 	RegisterDatabookExporter(FormatXLSX, DatabookExporterFunc(exportDatabookXLSX))
+	RegisterDatabookImporter(FormatXLSX, DatabookImporterFunc(ImportXLSXDatabook))
 }
 
-func exportXLSX(ds *Dataset, w io.Writer) error {
+func exportXLSX(ds *Dataset, w io.Writer, s exportSettings) error {
 	f := excelize.NewFile()
 	defer f.Close()
 
@@ -25,14 +29,16 @@ func exportXLSX(ds *Dataset, w io.Writer) error {
 	// Rename default sheet
 	f.SetSheetName("Sheet1", sheetName)
 
-	if err := writeDatasetToSheet(f, sheetName, ds); err != nil {
+	if err := writeDatasetToSheet(f, sheetName, ds, s.totals); err != nil {
 		return err
 	}
 
 	return f.Write(w)
 }
 
-func writeDatasetToSheet(f *excelize.File, sheetName string, ds *Dataset) error {
+// writeDatasetToSheet writes ds to a sheet, setting its last row in bold
+// when it is a totals row.
+func writeDatasetToSheet(f *excelize.File, sheetName string, ds *Dataset, totals bool) error {
 	rowNum := 1
 
 	// Write headers
@@ -57,9 +63,69 @@ func writeDatasetToSheet(f *excelize.File, sheetName string, ds *Dataset) error
 		rowNum++
 	}
 
+	// Attach column display formats as number formats
+	numFmts := make([]string, ds.Width())
+	for col := range numFmts {
+		if nf, ok := ds.columnNumberFormat(col); ok {
+			numFmts[col] = nf.spreadsheetFormat()
+		}
+	}
+	if len(ds.data) > 0 {
+		for col, numFmt := range numFmts {
+			if numFmt == "" {
+				continue
+			}
+			style, err := xlsxStyle(f, numFmt, false)
+			if err != nil {
+				return err
+			}
+			first, _ := excelize.CoordinatesToCellName(col+1, rowNum-len(ds.data))
+			last, _ := excelize.CoordinatesToCellName(col+1, rowNum-1)
+			if err := f.SetCellStyle(sheetName, first, last, style); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Attach cell comments as native comments
+	firstRow := rowNum - len(ds.data)
+	for _, c := range ds.cellComments() {
+		cell, _ := excelize.CoordinatesToCellName(c.col+1, firstRow+c.row)
+		if err := f.AddComment(sheetName, excelize.Comment{Cell: cell, Author: "tablib", Text: c.text}); err != nil {
+			return err
+		}
+	}
+
+	// Set an export-time totals row in bold
+	if totals && len(ds.data) > 0 {
+		for col, numFmt := range numFmts {
+			style, err := xlsxStyle(f, numFmt, true)
+			if err != nil {
+				return err
+			}
+			cell, _ := excelize.CoordinatesToCellName(col+1, rowNum-1)
+			if err := f.SetCellStyle(sheetName, cell, cell, style); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
+// xlsxStyle returns a cell style with the given number format code, if
+// any, and optionally a bold font.
+func xlsxStyle(f *excelize.File, numFmt string, bold bool) (int, error) {
+	style := &excelize.Style{}
+	if numFmt != "" {
+		style.CustomNumFmt = &numFmt
+	}
+	if bold {
+		style.Font = &excelize.Font{Bold: true}
+	}
+	return f.NewStyle(style)
+}
+
 func importXLSX(r io.Reader) (*Dataset, error) {
 	f, err := excelize.OpenReader(r)
 	if err != nil {
@@ -144,6 +210,9 @@ func ImportXLSXDatabook(r io.Reader) (*Databook, error) {
 	defer f.Close()
 
 	db := NewDatabook()
+	if err := readXLSXDocProps(f, db); err != nil {
+		return nil, err
+	}
 	for _, sheetName := range f.GetSheetList() {
 		ds, err := readSheetToDataset(f, sheetName)
 		if err != nil {
@@ -159,11 +228,7 @@ func exportDatabookXLSX(db *Databook, w io.Writer) error {
 	f := excelize.NewFile()
 	defer f.Close()
 
-	// Remove default sheet if we have sheets to add
-	if db.Size() > 0 {
-		f.DeleteSheet("Sheet1")
-	}
-
+	defaultUsed := false
 	for i, ds := range db.sheets {
 		sheetName := ds.Title()
 		if sheetName == "" {
@@ -174,11 +239,69 @@ func exportDatabookXLSX(db *Databook, w io.Writer) error {
 		if _, err := f.NewSheet(sheetName); err != nil {
 			return err
 		}
+		defaultUsed = defaultUsed || sheetName == "Sheet1"
 
-		if err := writeDatasetToSheet(f, sheetName, ds); err != nil {
+		if err := writeDatasetToSheet(f, sheetName, ds, false); err != nil {
 			return err
 		}
 	}
 
+	if err := writeXLSXDocProps(f, db); err != nil {
+		return err
+	}
+
+	// Remove the default sheet once the workbook has others; the last
+	// sheet of a workbook cannot be deleted
+	if db.Size() > 0 && !defaultUsed {
+		if err := f.DeleteSheet("Sheet1"); err != nil {
+			return err
+		}
+		f.SetActiveSheet(0)
+	}
+
 	return f.Write(w)
 }
+
+// writeXLSXDocProps stores the Databook title, author, creation time and
+// custom properties as workbook document properties.
+func writeXLSXDocProps(f *excelize.File, db *Databook) error {
+	created := db.created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	if err := f.SetDocProps(&excelize.DocProperties{
+		Title:   db.title,
+		Creator: db.author,
+		Created: created.UTC().Format(time.RFC3339),
+	}); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(db.properties)) {
+		if err := f.SetCustomProps(excelize.CustomProperty{Name: name, Value: db.properties[name]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readXLSXDocProps copies workbook document properties into db.
+func readXLSXDocProps(f *excelize.File, db *Databook) error {
+	props, err := f.GetDocProps()
+	if err != nil {
+		return err
+	}
+	db.title = props.Title
+	db.author = props.Creator
+	if created, err := time.Parse(time.RFC3339, props.Created); err == nil {
+		db.created = created
+	}
+
+	custom, err := f.GetCustomProps()
+	if err != nil {
+		return err
+	}
+	for _, p := range custom {
+		db.properties[p.Name] = fmt.Sprintf("%v", p.Value)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ func init() {
 	RegisterExporter(FormatYAML, ExporterFunc(exportYAML))
 	RegisterImporter(FormatYAML, ImporterFunc(importYAML))
 	RegisterDatabookExporter(FormatYAML, DatabookExporterFunc(exportDatabookYAML))
+	RegisterDatabookImporter(FormatYAML, DatabookImporterFunc(importDatabookYAML))
 }
 
 func exportYAML(ds *Dataset, w io.Writer) error {
@@ -129,3 +130,37 @@ func exportDatabookYAML(db *Databook, w io.Writer) error {
 
 	return encoder.Encode(result)
 }
+
+// importDatabookYAML reads the sheet list written by the Databook YAML
+// exporter: a sequence of mappings with a title and data records.
+func importDatabookYAML(r io.Reader) (*Databook, error) {
+	var sheets []struct {
+		Title string    `yaml:"title"`
+		Data  yaml.Node `yaml:"data"`
+	}
+	if err := yaml.NewDecoder(r).Decode(&sheets); err != nil {
+		return nil, ErrInvalidData
+	}
+
+	db := NewDatabook()
+	for _, sheet := range sheets {
+		var ds *Dataset
+		var objects []map[string]any
+		var arrays [][]any
+		var err error
+		switch {
+		case sheet.Data.Decode(&objects) == nil:
+			ds, err = importYAMLObjects(objects)
+		case sheet.Data.Decode(&arrays) == nil:
+			ds, err = importYAMLArrays(arrays)
+		default:
+			err = ErrInvalidData
+		}
+		if err != nil {
+			return nil, err
+		}
+		ds.SetTitle(sheet.Title)
+		db.AddSheet(ds)
+	}
+	return db, nil
+}